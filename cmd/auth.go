@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/internal/anilist"
+	"github.com/keircn/karu/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authToken    string
+	authClientID string
+	authLogout   bool
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Connect external tracking services",
+}
+
+var authAniListCmd = &cobra.Command{
+	Use:   "anilist",
+	Short: "Log in to AniList",
+	Long: `Log in to AniList so karu can update your list as you watch.
+
+Register an API client at https://anilist.co/settings/developer with the
+redirect URL https://anilist.co/api/v2/oauth/pin, then run this command with
+its client ID. Open the printed link, approve access and paste the token back
+here. A token can also be passed directly with --token.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			cfg = &config.DefaultConfig
+		}
+
+		auth, err := config.LoadAuth()
+		if err != nil {
+			fail("Error loading credentials", err)
+			return
+		}
+
+		if authLogout {
+			auth.AniList = nil
+			if err := config.SaveAuth(auth); err != nil {
+				fail("Error saving credentials", err)
+				return
+			}
+			fmt.Println("Logged out of AniList.")
+			return
+		}
+
+		if authClientID != "" && authClientID != cfg.AniListClientID {
+			if err := cfg.Set("anilist_client_id", authClientID); err != nil {
+				fail("Error saving client ID", err)
+				return
+			}
+		}
+
+		token := authToken
+		if token == "" {
+			if cfg.AniListClientID == "" {
+				fmt.Println("No AniList client ID configured. Pass one with --client-id or set anilist_client_id.")
+				return
+			}
+
+			fmt.Println("Open this link in your browser and approve access:")
+			fmt.Printf("\n  %s\n\n", anilist.AuthorizationURL(cfg.AniListClientID))
+			fmt.Print("Paste the token here: ")
+
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				fmt.Printf("\nError reading token: %v\n", err)
+				return
+			}
+			token = strings.TrimSpace(line)
+		}
+
+		if token == "" {
+			fmt.Println("No token given.")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		user, err := anilist.NewClient(cfg.AniListEndpoint, token).Viewer(ctx)
+		if err != nil {
+			fail("Error verifying token", err)
+			return
+		}
+
+		auth.AniList = &config.AniListAuth{
+			Token:    token,
+			UserID:   user.ID,
+			UserName: user.Name,
+		}
+		if err := config.SaveAuth(auth); err != nil {
+			fail("Error saving credentials", err)
+			return
+		}
+
+		fmt.Printf("Logged in to AniList as %s.\n", user.Name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authAniListCmd)
+
+	authAniListCmd.Flags().StringVar(&authToken, "token", "", "Access token to store instead of running the browser flow")
+	authAniListCmd.Flags().StringVar(&authClientID, "client-id", "", "AniList API client ID (saved as anilist_client_id)")
+	authAniListCmd.Flags().BoolVar(&authLogout, "logout", false, "Forget the stored AniList token")
+}
@@ -2,30 +2,57 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/keircn/karu/internal/config"
 	"github.com/keircn/karu/internal/player"
 	"github.com/keircn/karu/internal/scraper"
 	"github.com/keircn/karu/internal/ui"
 	"github.com/keircn/karu/internal/workflow"
+	"github.com/keircn/karu/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var browseMode string
+
 var browseCmd = &cobra.Command{
 	Use:   "browse",
 	Short: "Browse anime by category",
-	Long:  `Interactive browsing interface for discovering anime by search, recent releases, or catalog.`,
+	Long: `Interactive browsing interface for discovering anime by search, recent releases, or catalog.
+
+Use --mode to skip the menu. With --json, the recent, catalog and watchlist
+modes print their listing as JSON instead of opening a picker.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		mode, err := ui.SelectBrowseMode()
-		if err != nil {
-			fmt.Printf("Error selecting browse mode: %v\n", err)
-			return
+		var mode *ui.BrowseMode
+		if browseMode != "" {
+			parsed, err := parseBrowseMode(browseMode)
+			if err != nil {
+				fail("Error", err)
+				return
+			}
+			mode = &parsed
+		} else {
+			if !requireInteractive("pass --mode recent, catalog or watchlist") {
+				return
+			}
+
+			var err error
+			mode, err = ui.SelectBrowseMode()
+			if err != nil {
+				fail("Error selecting browse mode", err)
+				return
+			}
 		}
 
 		if mode == nil {
 			return
 		}
 
+		if outputJSON {
+			printBrowseListing(*mode)
+			return
+		}
+
 		switch *mode {
 		case ui.BrowseModeSearch:
 			handleSearchMode()
@@ -33,19 +60,54 @@ var browseCmd = &cobra.Command{
 			handleTrendingMode()
 		case ui.BrowseModePopular:
 			handlePopularMode()
+		case ui.BrowseModeWatchlist:
+			handleWatchlistMode("")
 		}
 	},
 }
 
-func handleSearchMode() {
-	selection, err := workflow.GetAnimeSelection("")
+func parseBrowseMode(value string) (ui.BrowseMode, error) {
+	for _, mode := range []ui.BrowseMode{ui.BrowseModeSearch, ui.BrowseModeTrending, ui.BrowseModePopular, ui.BrowseModeWatchlist} {
+		if strings.EqualFold(value, string(mode)) {
+			return mode, nil
+		}
+	}
+	return "", errors.New(errors.ValidationError, "unknown browse mode '"+value+"' (valid: search, recent, catalog, watchlist)")
+}
+
+func printBrowseListing(mode ui.BrowseMode) {
+	var animes []scraper.Anime
+	var err error
+
+	switch mode {
+	case ui.BrowseModeTrending:
+		animes, err = scraper.GetTrending()
+	case ui.BrowseModePopular:
+		animes, err = scraper.GetPopular()
+	case ui.BrowseModeWatchlist:
+		watchlist, err := config.LoadWatchlist()
+		if err != nil {
+			fail("Error loading watchlist", err)
+			return
+		}
+		printJSON(watchlist.ByStatus(""))
+		return
+	default:
+		fail("Error", errors.New(errors.ValidationError, "search mode is interactive, use 'karu search <query> --json'"))
+		return
+	}
+
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fail("Error getting anime", err)
 		return
 	}
+	printShows(animes)
+}
 
-	fmt.Printf("You chose: %s\n", selection.Anime.Title)
-	handleEpisodeSelection(selection)
+func handleSearchMode() {
+	if err := ui.RunApp(ui.AppOptions{Download: downloadFromApp}); err != nil {
+		fail("Error", err)
+	}
 }
 
 func handleTrendingMode() {
@@ -53,7 +115,7 @@ func handleTrendingMode() {
 
 	animes, err := scraper.GetTrending()
 	if err != nil {
-		fmt.Printf("Error getting recent anime: %v\n", err)
+		fail("Error getting recent anime", err)
 		return
 	}
 
@@ -64,7 +126,7 @@ func handleTrendingMode() {
 
 	choice, err := ui.SelectAnime(animes)
 	if err != nil {
-		fmt.Printf("Error selecting anime: %v\n", err)
+		fail("Error selecting anime", err)
 		return
 	}
 
@@ -80,7 +142,7 @@ func handlePopularMode() {
 
 	animes, err := scraper.GetPopular()
 	if err != nil {
-		fmt.Printf("Error getting anime catalog: %v\n", err)
+		fail("Error getting anime catalog", err)
 		return
 	}
 
@@ -91,7 +153,7 @@ func handlePopularMode() {
 
 	choice, err := ui.SelectAnime(animes)
 	if err != nil {
-		fmt.Printf("Error selecting anime: %v\n", err)
+		fail("Error selecting anime", err)
 		return
 	}
 
@@ -102,6 +164,32 @@ func handlePopularMode() {
 	}
 }
 
+func handleWatchlistMode(status config.WatchStatus) {
+	watchlist, err := config.LoadWatchlist()
+	if err != nil {
+		fail("Error loading watchlist", err)
+		return
+	}
+
+	entries := watchlist.ByStatus(status)
+	if len(entries) == 0 {
+		fmt.Println("No shows found in your watchlist.")
+		return
+	}
+
+	entry, err := ui.SelectWatchlistEntry(entries)
+	if err != nil {
+		fail("Error selecting from watchlist", err)
+		return
+	}
+
+	if entry != nil {
+		selection := createSelectionFromAnime(&scraper.Anime{Title: entry.Title, URL: entry.URL})
+		fmt.Printf("You chose: %s\n", entry.Title)
+		handleEpisodeSelection(selection)
+	}
+}
+
 func createSelectionFromAnime(choice *scraper.Anime) *workflow.AnimeSelection {
 	showID := extractShowID(choice.URL)
 	return &workflow.AnimeSelection{
@@ -125,7 +213,7 @@ func handleEpisodeSelection(selection *workflow.AnimeSelection) {
 		fmt.Printf("Loading episodes for %s...\n", selection.Anime.Title)
 		episodes, err := scraper.GetEpisodes(selection.ShowID)
 		if err != nil {
-			fmt.Printf("Error getting episodes: %v\n", err)
+			fail("Error getting episodes", err)
 			return
 		}
 
@@ -137,56 +225,64 @@ func handleEpisodeSelection(selection *workflow.AnimeSelection) {
 		selection.Episodes = episodes
 	}
 
-	episode, err := ui.SelectEpisode(selection.Episodes, selection.Anime.Title)
+	batch, err := ui.SelectEpisodes(selection.Episodes, selection.ShowID, selection.Anime.Title)
 	if err != nil {
-		fmt.Printf("Error selecting episode: %v\n", err)
+		fail("Error selecting episode", err)
 		return
 	}
 
-	if episode != nil {
-		fmt.Printf("You chose episode: %s\n", *episode)
+	if batch != nil {
+		if batch.Action == ui.ActionPlay {
+			fmt.Printf("You chose episode: %s\n", batch.Episodes[0])
+		}
+		runEpisodeBatch(selection, batch)
+	}
+}
 
-		cfg, _ := config.Load()
+func playSelectedEpisode(selection *workflow.AnimeSelection, episode string) {
+	cfg, _ := config.Load()
 
-		fmt.Printf("Getting video source for episode %s...\n", *episode)
-		videoURL, err := scraper.GetVideoURL(selection.ShowID, *episode)
-		if err != nil {
-			fmt.Printf("Error getting video URL: %v\n", err)
-			return
-		}
+	fmt.Printf("Getting video source for episode %s...\n", episode)
+	videoURL, err := scraper.GetVideoURL(selection.ShowID, episode)
+	if err != nil {
+		fail("Error getting video URL", err)
+		return
+	}
 
-		if videoURL == "" {
-			fmt.Println("No video URL found for this episode.")
-			return
-		}
+	if videoURL == "" {
+		fmt.Println("No video URL found for this episode.")
+		return
+	}
 
-		fmt.Printf("Video source found! Starting playback...\n")
-		if cfg.AutoPlayNext {
-			fmt.Printf("Auto-play next episode: %s\n", getAutoPlayStatus(cfg.AutoPlayNext))
+	fmt.Printf("Video source found! Starting playback...\n")
+	if cfg.AutoPlayNext {
+		fmt.Printf("Auto-play next episode: %s\n", getAutoPlayStatus(cfg.AutoPlayNext))
 
-			playbackInfo := &player.PlaybackInfo{
-				ShowID:    selection.ShowID,
-				ShowTitle: selection.Anime.Title,
-				Episodes:  selection.Episodes,
-				Current:   *episode,
-				VideoURL:  videoURL,
-			}
+		playbackInfo := &player.PlaybackInfo{
+			ShowID:    selection.ShowID,
+			ShowTitle: selection.Anime.Title,
+			Episodes:  selection.Episodes,
+			Current:   episode,
+			VideoURL:  videoURL,
+			MalID:     scraper.GetMalID(selection.ShowID),
+		}
 
-			getVideoURLFunc := func(showID, ep string) (string, error) {
-				fmt.Printf("Getting next episode source...\n")
-				return scraper.GetVideoURL(showID, ep)
-			}
+		getVideoURLFunc := func(showID, ep string) (string, error) {
+			fmt.Printf("Getting next episode source...\n")
+			return scraper.GetVideoURL(showID, ep)
+		}
 
-			if err := player.PlayWithAutoNext(playbackInfo, getVideoURLFunc); err != nil {
-				fmt.Printf("Error playing video: %v\n", err)
-			}
-		} else {
-			if err := player.Play(videoURL); err != nil {
-				fmt.Printf("Error playing video: %v\n", err)
-			}
+		if err := player.PlayWithAutoNext(playbackInfo, getVideoURLFunc); err != nil {
+			fail("Error playing video", err)
+		}
+	} else {
+		if err := player.Play(videoURL); err != nil {
+			fail("Error playing video", err)
 		}
 	}
 }
+
 func init() {
 	rootCmd.AddCommand(browseCmd)
+	browseCmd.Flags().StringVar(&browseMode, "mode", "", "Browse mode to open: search, recent, catalog or watchlist")
 }
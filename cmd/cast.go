@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/internal/cast"
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/proxy"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/internal/ui"
+	"github.com/keircn/karu/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	castDevice  string
+	castTimeout time.Duration
+)
+
+var castCmd = &cobra.Command{
+	Use:   "cast [query]",
+	Short: "Cast anime to a TV or media renderer on your network",
+	Long:  `Discover UPnP/DLNA media renderers on the local network and play episodes on them, with pause, seek, stop and auto-next controls.`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var query string
+		if len(args) > 0 {
+			query = args[0]
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			cfg = &config.DefaultConfig
+		}
+
+		selection, err := workflow.GetAnimeSelection(query)
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		episode, err := ui.SelectEpisode(selection.Episodes, selection.ShowID, selection.Anime.Title)
+		if err != nil {
+			fail("Error selecting episode", err)
+			return
+		}
+		if episode == nil {
+			return
+		}
+
+		fmt.Println("Searching for media renderers...")
+		renderers, err := cast.Discover(context.Background(), castTimeout)
+		if err != nil {
+			fail("Error discovering devices", err)
+			return
+		}
+
+		if castDevice != "" {
+			var matched []*cast.Renderer
+			for _, renderer := range renderers {
+				if strings.Contains(strings.ToLower(renderer.Name), strings.ToLower(castDevice)) {
+					matched = append(matched, renderer)
+				}
+			}
+			renderers = matched
+		}
+
+		if len(renderers) == 0 {
+			fmt.Println("No media renderers found on the network.")
+			return
+		}
+
+		renderer, err := ui.SelectRenderer(renderers)
+		if err != nil {
+			fail("Error selecting device", err)
+			return
+		}
+		if renderer == nil {
+			return
+		}
+
+		localIP, err := renderer.LocalAddrFor()
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		srv, err := proxy.New(net.JoinHostPort("0.0.0.0", strconv.Itoa(cfg.ProxyPort)), scraper.StreamHeaders())
+		if err != nil {
+			fail("Error starting stream proxy", err)
+			return
+		}
+		defer srv.Close()
+		srv.SetPublicHost(localIP)
+
+		autoNext := cfg.AutoPlayNext
+		if cmd.Flags().Changed("auto-next") {
+			autoNext, _ = cmd.Flags().GetBool("auto-next")
+		}
+
+		err = cast.Run(cast.SessionOptions{
+			Renderer:    renderer,
+			ShowID:      selection.ShowID,
+			ShowTitle:   selection.Anime.Title,
+			Episodes:    selection.Episodes,
+			Current:     *episode,
+			AutoNext:    autoNext,
+			GetVideoURL: scraper.GetVideoURL,
+			MediaURL:    srv.URL,
+		})
+		if err != nil {
+			fail("Error casting", err)
+		}
+	},
+}
+
+func init() {
+	castCmd.Flags().StringVarP(&castDevice, "device", "d", "", "Only use renderers whose name contains this text")
+	castCmd.Flags().DurationVar(&castTimeout, "timeout", 3*time.Second, "How long to wait for devices to answer discovery")
+	castCmd.Flags().Bool("auto-next", false, "Play the next episode when one finishes (defaults to auto_play_next)")
+	rootCmd.AddCommand(castCmd)
+}
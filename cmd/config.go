@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 
 	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -20,17 +28,16 @@ var configGetCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			fail("Error loading config", err)
 			return
 		}
 
 		key := args[0]
-		value := cfg.Get(key)
-		if value == "" {
+		if !slices.Contains(config.Keys(), key) {
 			fmt.Printf("Unknown config key: %s\n", key)
 			return
 		}
-		fmt.Printf("%s = %s\n", key, value)
+		fmt.Printf("%s = %s\n", key, cfg.Get(key))
 	},
 }
 
@@ -39,9 +46,9 @@ var configSetCmd = &cobra.Command{
 	Short: "Set a configuration value",
 	Args:  cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.Load()
+		cfg, err := loadConfigForRepair()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			fail("Error loading config", err)
 			return
 		}
 
@@ -49,40 +56,73 @@ var configSetCmd = &cobra.Command{
 		value := args[1]
 
 		if err := cfg.Set(key, value); err != nil {
-			fmt.Printf("Error setting config: %v\n", err)
+			fail("Error setting config", err)
 			return
 		}
 
-		fmt.Printf("Set %s = %s\n", key, value)
+		fmt.Printf("Set %s = %s\n", key, cfg.Get(key))
 	},
 }
 
+var configListOrigin bool
+
 var configListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all configuration values",
+	Long: `List every configuration value. With --origin, also show where each value
+came from: the built-in default, the config file, a profile, a KARU_*
+environment variable or a command-line flag.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg, err := config.Load()
 		if err != nil {
-			fmt.Printf("Error loading config: %v\n", err)
+			fail("Error loading config", err)
+			return
+		}
+
+		if outputJSON {
+			printConfigJSON(cfg)
 			return
 		}
 
-		keys := []string{"player", "player_args", "quality", "download_dir", "auto_play_next", "show_subtitles"}
 		fmt.Println("Current configuration:")
-		for _, key := range keys {
+		for _, key := range config.Keys() {
 			value := cfg.Get(key)
+			if configListOrigin {
+				fmt.Printf("  %s = %s  (%s)\n", key, value, cfg.Origin(key))
+				continue
+			}
 			fmt.Printf("  %s = %s\n", key, value)
 		}
+
+		if profiles := cfg.Profiles(); len(profiles) > 0 {
+			fmt.Printf("\nProfiles: %s\n", strings.Join(profiles, ", "))
+		}
 	},
 }
 
+func printConfigJSON(cfg *config.Config) {
+	type valueJSON struct {
+		Value  string `json:"value"`
+		Origin string `json:"origin"`
+	}
+
+	values := make(map[string]valueJSON)
+	for _, key := range config.Keys() {
+		values[key] = valueJSON{Value: cfg.Get(key), Origin: cfg.Origin(key).String()}
+	}
+	printJSON(struct {
+		Values   map[string]valueJSON `json:"values"`
+		Profiles []string             `json:"profiles,omitempty"`
+	}{values, cfg.Profiles()})
+}
+
 var configResetCmd = &cobra.Command{
 	Use:   "reset",
 	Short: "Reset configuration to defaults",
 	Run: func(cmd *cobra.Command, args []string) {
 		defaultCfg := config.DefaultConfig
 		if err := config.Save(&defaultCfg); err != nil {
-			fmt.Printf("Error resetting config: %v\n", err)
+			fail("Error resetting config", err)
 			return
 		}
 		fmt.Println("Configuration reset to defaults")
@@ -95,18 +135,156 @@ var configPathCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		path, err := config.GetConfigPath()
 		if err != nil {
-			fmt.Printf("Error getting config path: %v\n", err)
+			fail("Error getting config path", err)
 			return
 		}
 		fmt.Println(path)
 	},
 }
 
+var configEditRaw bool
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit configuration interactively",
+	Long: `Edit every configuration value in an interactive form that shows each key's
+type, default and allowed values, and validates changes as you type.
+
+With --raw, open the config file in $VISUAL or $EDITOR instead. The file is
+validated after the editor exits and is only saved once it is valid.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if configEditRaw {
+			if err := editConfigFile(); err != nil {
+				fail("Error", err)
+			}
+			return
+		}
+
+		cfg, err := loadConfigForRepair()
+		if err != nil {
+			fail("Error loading config", err)
+			return
+		}
+
+		changes, err := ui.EditConfig(cfg)
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+		if len(changes) == 0 {
+			fmt.Println("No changes made.")
+			return
+		}
+
+		if err := cfg.Update(changes); err != nil {
+			fail("Error saving config", err)
+			return
+		}
+		fmt.Printf("Saved %d setting(s)\n", len(changes))
+	},
+}
+
+func loadConfigForRepair() (*config.Config, error) {
+	cfg, problems, err := config.LoadForRepair()
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring invalid setting: %v\n", problem)
+	}
+	return cfg, err
+}
+
+func editConfigFile() error {
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "karu-config-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(original); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	for {
+		if err := runEditor(tmp.Name()); err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return err
+		}
+		if string(data) == string(original) {
+			fmt.Println("No changes made.")
+			return nil
+		}
+
+		problems := config.CheckFile(path, data, original)
+		if len(problems) == 0 {
+			if err := config.WriteFile(path, data, 0644); err != nil {
+				return err
+			}
+			fmt.Printf("Saved %s\n", path)
+			return nil
+		}
+
+		fmt.Println("The edited config has problems:")
+		for _, problem := range problems {
+			fmt.Printf("  %v\n", problem)
+		}
+		fmt.Print("Edit again? (Y/n): ")
+		response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		response = strings.TrimSpace(response)
+
+		if err != nil || response == "n" || response == "N" {
+			fmt.Println("Discarded changes.")
+			return nil
+		}
+	}
+}
+
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	parts := strings.Fields(editor)
+	editorCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", parts[0], err)
+	}
+	return nil
+}
+
 func init() {
 	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configListCmd)
 	configCmd.AddCommand(configResetCmd)
 	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configEditCmd)
+
+	configListCmd.Flags().BoolVar(&configListOrigin, "origin", false, "Show where each value came from")
+	configEditCmd.Flags().BoolVar(&configEditRaw, "raw", false, "Edit the config file in $EDITOR")
 	rootCmd.AddCommand(configCmd)
 }
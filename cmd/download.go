@@ -3,14 +3,18 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/scraper"
 	"github.com/keircn/karu/internal/ui"
 	"github.com/keircn/karu/internal/workflow"
+	"github.com/keircn/karu/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 var (
 	downloadAll   bool
 	downloadRange string
+	downloadPick  string
 )
 
 var downloadCmd = &cobra.Command{
@@ -24,9 +28,20 @@ var downloadCmd = &cobra.Command{
 			query = args[0]
 		}
 
-		selection, err := workflow.GetAnimeSelection(query)
+		if downloadPick != "" && !downloadAll && downloadRange == "" {
+			fail("Error", errors.New(errors.ValidationError, "--pick needs --all or --range"))
+			return
+		}
+
+		var selection *workflow.AnimeSelection
+		var err error
+		if downloadPick != "" {
+			selection, err = workflow.PickAnimeSelection(query, downloadPick)
+		} else {
+			selection, err = workflow.GetAnimeSelection(query)
+		}
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			fail("Error", err)
 			return
 		}
 
@@ -37,7 +52,7 @@ var downloadCmd = &cobra.Command{
 			opts := workflow.DownloadOptions{All: true}
 			result, err := workflow.DownloadEpisodes(selection, opts)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				fail("Error", err)
 				return
 			}
 			workflow.PrintDownloadSummary(result)
@@ -46,22 +61,24 @@ var downloadCmd = &cobra.Command{
 			opts := workflow.DownloadOptions{Range: downloadRange}
 			result, err := workflow.DownloadEpisodes(selection, opts)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
+				fail("Error", err)
 				return
 			}
 			workflow.PrintDownloadSummary(result)
 		} else {
-			episode, err := ui.SelectEpisode(selection.Episodes, selection.Anime.Title)
+			batch, err := ui.SelectEpisodes(selection.Episodes, selection.ShowID, selection.Anime.Title)
 			if err != nil {
-				fmt.Printf("Error selecting episode: %v\n", err)
+				fail("Error selecting episode", err)
 				return
 			}
 
-			if episode != nil {
-				opts := workflow.DownloadOptions{Range: *episode}
+			if batch != nil && batch.Action != ui.ActionPlay {
+				runEpisodeBatch(selection, batch)
+			} else if batch != nil {
+				opts := workflow.DownloadOptions{Range: batch.Episodes[0]}
 				result, err := workflow.DownloadEpisodes(selection, opts)
 				if err != nil {
-					fmt.Printf("Error: %v\n", err)
+					fail("Error", err)
 					return
 				}
 				workflow.PrintDownloadSummary(result)
@@ -77,13 +94,18 @@ var downloadListCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fm, err := workflow.NewFileManager()
 		if err != nil {
-			fmt.Printf("Error initializing file manager: %v\n", err)
+			fail("Error initializing file manager", err)
 			return
 		}
 
 		downloads, err := fm.ListDownloads()
 		if err != nil {
-			fmt.Printf("Error listing downloads: %v\n", err)
+			fail("Error listing downloads", err)
+			return
+		}
+
+		if outputJSON {
+			printJSON(downloads)
 			return
 		}
 
@@ -109,13 +131,18 @@ var downloadCleanCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fm, err := workflow.NewFileManager()
 		if err != nil {
-			fmt.Printf("Error initializing file manager: %v\n", err)
+			fail("Error initializing file manager", err)
 			return
 		}
 
 		downloads, err := fm.ListDownloads()
 		if err != nil {
-			fmt.Printf("Error listing downloads: %v\n", err)
+			fail("Error listing downloads", err)
+			return
+		}
+
+		if outputJSON {
+			printJSON(downloads)
 			return
 		}
 
@@ -135,7 +162,7 @@ var downloadCleanCmd = &cobra.Command{
 
 		removed, err := fm.CleanDownloads()
 		if err != nil {
-			fmt.Printf("Error cleaning downloads: %v\n", err)
+			fail("Error cleaning downloads", err)
 			return
 		}
 
@@ -143,11 +170,100 @@ var downloadCleanCmd = &cobra.Command{
 	},
 }
 
+var downloadQueueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "List episodes queued for download",
+	Long: `List the episodes queued for download. Select several episodes in the
+episode picker and queue them, then download them all with
+'karu download queue run'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		queue, err := config.GetDownloadQueue()
+		if err != nil {
+			fail("Error loading download queue", err)
+			return
+		}
+
+		if outputJSON {
+			printJSON(queue)
+			return
+		}
+
+		if len(queue) == 0 {
+			fmt.Println("The download queue is empty.")
+			return
+		}
+
+		fmt.Println("Queued downloads:")
+		for _, queued := range queue {
+			fmt.Printf("  %s - episode %s (queued %s)\n",
+				queued.Title,
+				queued.Episode,
+				queued.QueuedAt.Format("2006-01-02 15:04"))
+		}
+	},
+}
+
+var downloadQueueRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Download every queued episode",
+	Run: func(cmd *cobra.Command, args []string) {
+		result, err := workflow.RunDownloadQueue()
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		if result.Total == 0 {
+			fmt.Println("The download queue is empty.")
+			return
+		}
+		workflow.PrintDownloadSummary(result)
+	},
+}
+
+var downloadQueueClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every episode from the download queue",
+	Run: func(cmd *cobra.Command, args []string) {
+		queue, err := config.GetDownloadQueue()
+		if err != nil {
+			fail("Error loading download queue", err)
+			return
+		}
+
+		for _, queued := range queue {
+			if err := config.RemoveQueuedDownload(queued.ShowID, queued.Episode); err != nil {
+				fail("Error clearing download queue", err)
+				return
+			}
+		}
+		fmt.Printf("Removed %d episodes from the download queue.\n", len(queue))
+	},
+}
+
+func downloadFromApp(showID, title string, episodes []string) (int, error) {
+	selection := &workflow.AnimeSelection{
+		Anime:  &scraper.Anime{Title: title, URL: scraper.ShowURL(showID)},
+		ShowID: showID,
+	}
+
+	result, err := workflow.DownloadEpisodes(selection, workflow.DownloadOptions{Episodes: episodes})
+	if err != nil {
+		return 0, err
+	}
+	workflow.PrintDownloadSummary(result)
+	return result.Successful, nil
+}
+
 func init() {
 	downloadCmd.Flags().BoolVarP(&downloadAll, "all", "a", false, "Download all episodes")
 	downloadCmd.Flags().StringVarP(&downloadRange, "range", "r", "", "Download episode range (e.g., 1-5 or 1,3,5)")
+	downloadCmd.Flags().StringVar(&downloadPick, "pick", "", "Choose a search result by position, exact title or show ID without prompting")
 
 	downloadCmd.AddCommand(downloadListCmd)
 	downloadCmd.AddCommand(downloadCleanCmd)
+	downloadQueueCmd.AddCommand(downloadQueueRunCmd)
+	downloadQueueCmd.AddCommand(downloadQueueClearCmd)
+	downloadCmd.AddCommand(downloadQueueCmd)
 	rootCmd.AddCommand(downloadCmd)
 }
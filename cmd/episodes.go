@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/player"
+	"github.com/keircn/karu/internal/releases"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/internal/ui"
+	"github.com/keircn/karu/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var episodesCmd = &cobra.Command{
+	Use:   "episodes <show>",
+	Short: "List the episodes of a show",
+	Long: `List the episodes of a show with their watched state.
+
+The show is a show ID, a show URL or the exact title of a show in your
+history.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		showID, title := lookupShow(args[0])
+
+		episodes, err := scraper.GetEpisodes(showID)
+		if err != nil {
+			fail("Error getting episodes", err)
+			return
+		}
+
+		printEpisodes(showID, title, releases.SortEpisodes(episodes))
+	},
+}
+
+var sourcesCmd = &cobra.Command{
+	Use:   "sources <show> <episode>",
+	Short: "List the video sources of an episode",
+	Long: `List the available qualities and stream URLs of an episode.
+
+The show is a show ID, a show URL or the exact title of a show in your
+history. The episode is an episode number, first, latest or next.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		showID, _ := lookupShow(args[0])
+
+		episodes, err := scraper.GetEpisodes(showID)
+		if err != nil {
+			fail("Error getting episodes", err)
+			return
+		}
+
+		episode, err := workflow.PickEpisode(showID, episodes, args[1])
+		if err != nil {
+			fail("Error selecting episode", err)
+			return
+		}
+
+		qualities, err := scraper.GetAvailableQualities(showID, episode)
+		if err != nil {
+			fail("Error getting video qualities", err)
+			return
+		}
+
+		if outputJSON {
+			printJSON(qualities.Options)
+			return
+		}
+
+		for i, option := range qualities.Options {
+			marker := " "
+			if i == qualities.Default {
+				marker = "*"
+			}
+			kind := "mp4"
+			if option.IsHLS {
+				kind = "hls"
+			}
+			fmt.Printf("%s %-8s %-4s %-12s %s\n", marker, option.Quality, kind, option.Source, option.URL)
+		}
+	},
+}
+
+// lookupShow turns a show argument into a show ID, using the history to
+// accept exact titles and to look up the title of a known show.
+func lookupShow(show string) (string, string) {
+	showID := config.ShowIDFromURL(show)
+
+	history, err := config.LoadHistory()
+	if err != nil {
+		return showID, ""
+	}
+	if entry, exists := history.Find(showID); exists {
+		return showID, entry.Title
+	}
+	for _, entry := range history.Search(show) {
+		if strings.EqualFold(entry.Title, show) {
+			return entry.ShowID, entry.Title
+		}
+	}
+	return showID, ""
+}
+
+func runEpisodeBatch(selection *workflow.AnimeSelection, batch *ui.EpisodeBatch) {
+	episodes := batch.Episodes
+
+	switch batch.Action {
+	case ui.ActionPlay:
+		playSelectedEpisode(selection, episodes[0])
+
+	case ui.ActionPlaylist:
+		playPlaylist(selection, episodes)
+
+	case ui.ActionDownload:
+		result, err := workflow.DownloadEpisodes(selection, workflow.DownloadOptions{Episodes: episodes})
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+		workflow.PrintDownloadSummary(result)
+
+	case ui.ActionQueue:
+		if err := workflow.QueueDownloads(selection, episodes); err != nil {
+			fail("Error queueing downloads", err)
+			return
+		}
+		fmt.Printf("Queued %d episodes. Run 'karu download queue run' to download them.\n", len(episodes))
+
+	case ui.ActionMarkWatched, ui.ActionMarkUnwatched:
+		watched := batch.Action == ui.ActionMarkWatched
+		history, err := config.LoadHistory()
+		if err != nil {
+			fail("Error loading history", err)
+			return
+		}
+		if err := history.SetWatched(selection.ShowID, episodes, watched); err != nil {
+			fail("Error updating history", err)
+			return
+		}
+		state := "watched"
+		if !watched {
+			state = "unwatched"
+		}
+		fmt.Printf("Marked %d episodes of %s as %s.\n", len(episodes), selection.Anime.Title, state)
+	}
+}
+
+func playPlaylist(selection *workflow.AnimeSelection, episodes []string) {
+	cfg, _ := config.Load()
+
+	fmt.Printf("Playing %d episodes of %s...\n", len(episodes), selection.Anime.Title)
+	playbackInfo := &player.PlaybackInfo{
+		ShowID:    selection.ShowID,
+		ShowTitle: selection.Anime.Title,
+		Episodes:  episodes,
+		Current:   episodes[0],
+		MalID:     scraper.GetMalID(selection.ShowID),
+		Playlist:  true,
+	}
+
+	getVideoURLFunc := func(showID, ep string) (string, error) {
+		return scraper.GetVideoURLWithQuality(showID, ep, cfg.Quality)
+	}
+
+	if err := player.PlayWithAutoNext(playbackInfo, getVideoURLFunc); err != nil {
+		fail("Error playing video", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(episodesCmd)
+	rootCmd.AddCommand(sourcesCmd)
+}
@@ -2,9 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/releases"
+	"github.com/keircn/karu/internal/transfer"
 	"github.com/keircn/karu/internal/ui"
 	"github.com/keircn/karu/internal/workflow"
 	"github.com/spf13/cobra"
@@ -15,27 +23,35 @@ var historyCmd = &cobra.Command{
 	Short: "Manage search history",
 	Long:  `View, search, and manage your anime search history.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if !requireInteractive("use 'karu history list --json'") {
+			return
+		}
+
 		selection, err := workflow.GetAnimeSelectionFromHistory()
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			fail("Error", err)
 			return
 		}
 
 		fmt.Printf("You chose: %s\n", selection.Anime.Title)
 
-		episode, err := ui.SelectEpisode(selection.Episodes, selection.Anime.Title)
+		batch, err := ui.SelectEpisodes(selection.Episodes, selection.ShowID, selection.Anime.Title)
 		if err != nil {
-			fmt.Printf("Error selecting episode: %v\n", err)
+			fail("Error selecting episode", err)
+			return
+		}
+
+		if batch != nil && batch.Action != ui.ActionPlay {
+			runEpisodeBatch(selection, batch)
 			return
 		}
 
-		if episode != nil {
-			fmt.Printf("You chose episode: %s\n", *episode)
+		if batch != nil {
+			fmt.Printf("You chose episode: %s\n", batch.Episodes[0])
 
 			history, _ := config.LoadHistory()
 			if history != nil {
-				episodeNum, _ := strconv.Atoi(*episode)
-				history.UpdateProgress(selection.Anime.Title, episodeNum)
+				history.Touch(selection.ShowID)
 			}
 		}
 	},
@@ -47,11 +63,16 @@ var historyListCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		history, err := config.LoadHistory()
 		if err != nil {
-			fmt.Printf("Error loading history: %v\n", err)
+			fail("Error loading history", err)
 			return
 		}
 
 		entries := history.GetRecent(0)
+		if outputJSON {
+			printJSON(entries)
+			return
+		}
+
 		if len(entries) == 0 {
 			fmt.Println("No history entries found.")
 			return
@@ -62,7 +83,7 @@ var historyListCmd = &cobra.Command{
 		for i, entry := range entries {
 			progress := ""
 			if entry.TotalEps > 0 {
-				progress = fmt.Sprintf(" (%d/%d)", entry.LastWatched, entry.TotalEps)
+				progress = fmt.Sprintf(" (%d/%d)", entry.WatchedCount(), entry.TotalEps)
 			}
 			fmt.Printf("%d. %s%s\n", i+1, entry.Title, progress)
 			fmt.Printf("   Query: %s\n", entry.Query)
@@ -85,11 +106,16 @@ var historyRecentCmd = &cobra.Command{
 
 		history, err := config.LoadHistory()
 		if err != nil {
-			fmt.Printf("Error loading history: %v\n", err)
+			fail("Error loading history", err)
 			return
 		}
 
 		entries := history.GetRecent(limit)
+		if outputJSON {
+			printJSON(entries)
+			return
+		}
+
 		if len(entries) == 0 {
 			fmt.Println("No recent history entries found.")
 			return
@@ -100,7 +126,7 @@ var historyRecentCmd = &cobra.Command{
 		for i, entry := range entries {
 			progress := ""
 			if entry.TotalEps > 0 {
-				progress = fmt.Sprintf(" (%d/%d)", entry.LastWatched, entry.TotalEps)
+				progress = fmt.Sprintf(" (%d/%d)", entry.WatchedCount(), entry.TotalEps)
 			}
 			fmt.Printf("%d. %s%s\n", i+1, entry.Title, progress)
 			fmt.Printf("   %s\n\n", entry.Timestamp.Format("Jan 2, 2006 15:04"))
@@ -122,11 +148,16 @@ var historyPopularCmd = &cobra.Command{
 
 		history, err := config.LoadHistory()
 		if err != nil {
-			fmt.Printf("Error loading history: %v\n", err)
+			fail("Error loading history", err)
 			return
 		}
 
 		entries := history.GetMostWatched(limit)
+		if outputJSON {
+			printJSON(entries)
+			return
+		}
+
 		if len(entries) == 0 {
 			fmt.Println("No history entries found.")
 			return
@@ -137,7 +168,7 @@ var historyPopularCmd = &cobra.Command{
 		for i, entry := range entries {
 			progress := ""
 			if entry.TotalEps > 0 {
-				progress = fmt.Sprintf(" (%d/%d)", entry.LastWatched, entry.TotalEps)
+				progress = fmt.Sprintf(" (%d/%d)", entry.WatchedCount(), entry.TotalEps)
 			}
 			fmt.Printf("%d. %s%s\n", i+1, entry.Title, progress)
 			fmt.Printf("   Watched %d times\n\n", entry.AccessCount)
@@ -154,11 +185,16 @@ var historySearchCmd = &cobra.Command{
 
 		history, err := config.LoadHistory()
 		if err != nil {
-			fmt.Printf("Error loading history: %v\n", err)
+			fail("Error loading history", err)
 			return
 		}
 
 		entries := history.Search(query)
+		if outputJSON {
+			printJSON(entries)
+			return
+		}
+
 		if len(entries) == 0 {
 			fmt.Printf("No history entries found matching '%s'.\n", query)
 			return
@@ -169,7 +205,7 @@ var historySearchCmd = &cobra.Command{
 		for i, entry := range entries {
 			progress := ""
 			if entry.TotalEps > 0 {
-				progress = fmt.Sprintf(" (%d/%d)", entry.LastWatched, entry.TotalEps)
+				progress = fmt.Sprintf(" (%d/%d)", entry.WatchedCount(), entry.TotalEps)
 			}
 			fmt.Printf("%d. %s%s\n", i+1, entry.Title, progress)
 			fmt.Printf("   Query: %s\n", entry.Query)
@@ -184,12 +220,12 @@ var historyClearCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		history, err := config.LoadHistory()
 		if err != nil {
-			fmt.Printf("Error loading history: %v\n", err)
+			fail("Error loading history", err)
 			return
 		}
 
 		if err := history.Clear(); err != nil {
-			fmt.Printf("Error clearing history: %v\n", err)
+			fail("Error clearing history", err)
 			return
 		}
 
@@ -197,6 +233,306 @@ var historyClearCmd = &cobra.Command{
 	},
 }
 
+var historyMarkCmd = &cobra.Command{
+	Use:   "mark <show> <episodes>",
+	Short: "Mark episodes as watched",
+	Long: `Mark episodes of a show in your history as watched.
+
+The show can be given as its ID or as part of its title. Episodes accept
+single numbers, ranges and lists, e.g. "5", "1-12" or "1-3,7,12.5", or
+"all" to mark every known episode.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		setWatched(args[0], args[1:], true)
+	},
+}
+
+var historyUnmarkCmd = &cobra.Command{
+	Use:   "unmark <show> <episodes>",
+	Short: "Clear the watch record of episodes",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		setWatched(args[0], args[1:], false)
+	},
+}
+
+var (
+	historyExportFormat string
+	historyImportFormat string
+	historyOutput       string
+	historyDryRun       bool
+	historyConflict     string
+)
+
+var historyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export history and watchlist",
+	Long:  `Export your watch history and watchlist as karu JSON or CSV.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		history, err := config.LoadHistory()
+		if err != nil {
+			fail("Error loading history", err)
+			return
+		}
+
+		watchlist, err := config.LoadWatchlist()
+		if err != nil {
+			fail("Error loading watchlist", err)
+			return
+		}
+
+		export := func(out io.Writer) error {
+			return transfer.Export(out, historyExportFormat, history, watchlist)
+		}
+		if historyOutput == "" || historyOutput == "-" {
+			if err := export(os.Stdout); err != nil {
+				fail("Error exporting history", err)
+			}
+			return
+		}
+
+		if err := writeFileAtomic(historyOutput, export); err != nil {
+			fail("Error exporting history", err)
+			return
+		}
+		fmt.Printf("Exported %d history and %d watchlist entries to %s\n",
+			len(history.Entries), len(watchlist.Entries), historyOutput)
+	},
+}
+
+var historyImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import history from karu, CSV, MyAnimeList or ani-cli files",
+	Long: `Import watch history and watchlist entries.
+
+Supported inputs are karu JSON exports (or a copied history.json or
+watchlist.json), karu CSV exports, MyAnimeList XML exports (optionally
+gzipped) and ani-cli's ani-hsts history file. The format is detected from the
+file name and contents unless --format is given.
+
+When a show already exists locally, --conflict decides which side wins:
+"newest" keeps the most recently updated entry, "progress" keeps the entry
+with the most watched episodes. Use --dry-run to preview the changes.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := transfer.ValidConflictRule(historyConflict); err != nil {
+			fail("Error", err)
+			return
+		}
+
+		path := args[0]
+		info, err := os.Stat(path)
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		data, err := os.ReadFile(path)
+		if err == nil {
+			data, err = transfer.Decompress(data)
+		}
+		if err != nil {
+			fail("Error reading "+path, err)
+			return
+		}
+
+		format := historyImportFormat
+		if format == "" || format == "auto" {
+			format = transfer.DetectFormat(path, data)
+		}
+
+		records, err := transfer.Parse(data, format, info.ModTime())
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+		fmt.Printf("Read %d entries from %s (%s)\n", len(records), path, format)
+
+		transfer.Resolve(records, func(title string) {
+			fmt.Printf("Looking up %s...\n", title)
+		})
+
+		history, err := config.LoadHistory()
+		if err != nil {
+			fail("Error loading history", err)
+			return
+		}
+		watchlist, err := config.LoadWatchlist()
+		if err != nil {
+			fail("Error loading watchlist", err)
+			return
+		}
+
+		plan := transfer.BuildPlan(records, historyConflict, history, watchlist)
+		for _, change := range plan.Changes {
+			line := fmt.Sprintf("%-10s %s", change.Action, change.Title)
+			if change.Reason != "" {
+				line += fmt.Sprintf(" (%s)", change.Reason)
+			}
+			fmt.Println(line)
+		}
+
+		summary := fmt.Sprintf("%d added, %d replaced, %d kept, %d not found",
+			plan.Count(transfer.ActionAdd), plan.Count(transfer.ActionReplace),
+			plan.Count(transfer.ActionKeep), plan.Count(transfer.ActionUnresolved))
+
+		if historyDryRun {
+			fmt.Printf("\nDry run, nothing was saved: %s\n", summary)
+			return
+		}
+
+		if err := plan.Apply(); err != nil {
+			fail("Error saving imported entries", err)
+			return
+		}
+		fmt.Printf("\nImported: %s\n", summary)
+	},
+}
+
+func setWatched(show string, specs []string, watched bool) {
+	history, err := config.LoadHistory()
+	if err != nil {
+		fail("Error loading history", err)
+		return
+	}
+
+	entry, err := findHistoryEntry(history, show)
+	if err != nil {
+		fail("Error", err)
+		return
+	}
+
+	episodes, err := selectEpisodes(specs, entry)
+	if err != nil {
+		fail("Error", err)
+		return
+	}
+
+	if err := history.SetWatched(entry.ShowID, episodes, watched); err != nil {
+		fail("Error saving history", err)
+		return
+	}
+
+	action := "Marked"
+	if !watched {
+		action = "Unmarked"
+	}
+	fmt.Printf("%s %d episode(s) of %s\n", action, len(episodes), entry.Title)
+}
+
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	err = write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func findHistoryEntry(history *config.History, show string) (config.HistoryEntry, error) {
+	if entry, exists := history.Find(show); exists {
+		return entry, nil
+	}
+
+	matches := history.Search(show)
+	for _, match := range matches {
+		if strings.EqualFold(match.Title, show) {
+			return match, nil
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return config.HistoryEntry{}, fmt.Errorf("no history entry matches '%s'", show)
+	case 1:
+		return matches[0], nil
+	}
+
+	titles := make([]string, len(matches))
+	for i, match := range matches {
+		titles[i] = fmt.Sprintf("%s (%s)", match.Title, match.ShowID)
+	}
+	return config.HistoryEntry{}, fmt.Errorf("'%s' matches several shows: %s", show, strings.Join(titles, ", "))
+}
+
+func selectEpisodes(specs []string, entry config.HistoryEntry) ([]string, error) {
+	var episodes []string
+	add := func(episode string) {
+		if !slices.Contains(episodes, episode) {
+			episodes = append(episodes, episode)
+		}
+	}
+
+	for _, part := range strings.Split(strings.Join(specs, ","), ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			continue
+
+		case part == "all":
+			if entry.TotalEps <= 0 && len(entry.Episodes) == 0 {
+				return nil, fmt.Errorf("episode count unknown, give an explicit range")
+			}
+			for n := 1; n <= entry.TotalEps; n++ {
+				add(strconv.Itoa(n))
+			}
+			for _, episode := range releases.SortEpisodes(slices.Collect(maps.Keys(entry.Episodes))) {
+				add(episode)
+			}
+
+		case strings.Contains(part, "-"):
+			start, end, _ := strings.Cut(part, "-")
+			first, err1 := strconv.Atoi(strings.TrimSpace(start))
+			last, err2 := strconv.Atoi(strings.TrimSpace(end))
+			if err1 != nil || err2 != nil || first < 1 || last < first {
+				return nil, fmt.Errorf("invalid episode range '%s'", part)
+			}
+			for n := first; n <= last; n++ {
+				add(strconv.Itoa(n))
+			}
+
+		default:
+			if _, err := strconv.ParseFloat(part, 64); err != nil {
+				return nil, fmt.Errorf("invalid episode '%s'", part)
+			}
+			add(part)
+		}
+	}
+
+	if len(episodes) == 0 {
+		return nil, fmt.Errorf("no episodes given")
+	}
+
+	if entry.TotalEps > 0 {
+		beyond := 0
+		for _, episode := range episodes {
+			if _, recorded := entry.Episodes[episode]; recorded {
+				continue
+			}
+			if n, err := strconv.ParseFloat(episode, 64); err == nil && n > float64(entry.TotalEps) {
+				beyond++
+			}
+		}
+		if beyond > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %d episode(s) are past the %d known episodes of %s\n", beyond, entry.TotalEps, entry.Title)
+		}
+	}
+	return episodes, nil
+}
+
 func init() {
 	rootCmd.AddCommand(historyCmd)
 
@@ -205,4 +541,14 @@ func init() {
 	historyCmd.AddCommand(historyPopularCmd)
 	historyCmd.AddCommand(historySearchCmd)
 	historyCmd.AddCommand(historyClearCmd)
+	historyCmd.AddCommand(historyMarkCmd)
+	historyCmd.AddCommand(historyUnmarkCmd)
+	historyCmd.AddCommand(historyExportCmd)
+	historyCmd.AddCommand(historyImportCmd)
+
+	historyExportCmd.Flags().StringVarP(&historyExportFormat, "format", "f", transfer.FormatJSON, "Output format (json or csv)")
+	historyExportCmd.Flags().StringVarP(&historyOutput, "output", "o", "", "Write to this file instead of stdout")
+	historyImportCmd.Flags().StringVarP(&historyImportFormat, "format", "f", "auto", "Input format (auto, json, csv, mal or ani-cli)")
+	historyImportCmd.Flags().BoolVar(&historyDryRun, "dry-run", false, "Show what would change without saving")
+	historyImportCmd.Flags().StringVar(&historyConflict, "conflict", transfer.ConflictNewest, "Conflict rule for existing shows (newest or progress)")
 }
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/keircn/karu/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Show the active key bindings",
+	Long: `Show the key bindings for every interactive screen.
+
+Bindings can be changed in the [keys] section of the config file, grouped by
+screen, for example:
+
+  [keys.player]
+  next = ["n", "right"]
+  quit = "x"
+
+Use "space" for the space bar. ctrl+c always quits and cannot be rebound.
+If any binding is invalid or two actions on the same screen share a key,
+all custom bindings are ignored until the problem is fixed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(keyProblems) > 0 {
+			fmt.Println("Custom key bindings are not in use:")
+			for _, problem := range keyProblems {
+				fmt.Printf("  %v\n", problem)
+			}
+			fmt.Println()
+		}
+
+		for i, screen := range ui.Keys.Screens() {
+			if i > 0 {
+				fmt.Println()
+			}
+			title := fmt.Sprintf("%s (%s)", screen.Description, screen.Name)
+			if screen.Extends != "" {
+				title += fmt.Sprintf(", plus %s keys", screen.Extends)
+			}
+			fmt.Println(title)
+
+			for _, action := range screen.Actions {
+				help := action.Binding.Help()
+				fmt.Printf("  %-18s %-16s %s\n", action.Name, help.Key, help.Desc)
+			}
+		}
+
+		fmt.Printf("\n%s always force quits.\n", ui.ForceQuitKey)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+}
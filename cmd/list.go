@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listStatus string
+	listScore  int
+	listNotes  string
+	listTags   []string
+	listUntags []string
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list [subcommand]",
+	Short: "Manage your watchlist",
+	Long:  `Keep track of shows you are watching, plan to watch, have completed, put on hold or dropped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !requireInteractive("use 'karu list show --json'") {
+			return
+		}
+		handleWatchlistMode("")
+	},
+}
+
+var listAddCmd = &cobra.Command{
+	Use:   "add <show>",
+	Short: "Add a show to your watchlist",
+	Long: `Add a show to your watchlist. The show is looked up in your history first
+and searched for otherwise.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		status, err := config.ParseWatchStatus(listStatus)
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		anime, err := resolveShow(strings.Join(args, " "))
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+		if anime == nil {
+			return
+		}
+
+		watchlist, err := config.LoadWatchlist()
+		if err != nil {
+			fail("Error loading watchlist", err)
+			return
+		}
+
+		showID := config.ShowIDFromURL(anime.URL)
+		added, err := watchlist.Add(showID, anime.Title, anime.URL, status)
+		if err != nil {
+			fail("Error saving watchlist", err)
+			return
+		}
+		if !added {
+			fmt.Printf("%s is already in your watchlist, use 'karu list status' to change it\n", anime.Title)
+			return
+		}
+
+		if err := watchlist.Update(showID, func(entry *config.WatchlistEntry) error {
+			return applyListFlags(cmd, entry)
+		}); err != nil {
+			fail("Error", err)
+			return
+		}
+
+		fmt.Printf("Added %s to your watchlist as %s\n", anime.Title, status)
+	},
+}
+
+var listRemoveCmd = &cobra.Command{
+	Use:   "remove <show>",
+	Short: "Remove a show from your watchlist",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		watchlist, err := config.LoadWatchlist()
+		if err != nil {
+			fail("Error loading watchlist", err)
+			return
+		}
+
+		entry, err := findWatchlistEntry(watchlist, strings.Join(args, " "))
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		if _, err := watchlist.Remove(entry.ShowID); err != nil {
+			fail("Error saving watchlist", err)
+			return
+		}
+
+		fmt.Printf("Removed %s from your watchlist\n", entry.Title)
+	},
+}
+
+var listStatusCmd = &cobra.Command{
+	Use:   "status <show> [status]",
+	Short: "Show or change the status, score, notes and tags of a show",
+	Long: `Show or change a watchlist entry. Valid statuses are watching, planned,
+completed, on-hold and dropped.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		watchlist, err := config.LoadWatchlist()
+		if err != nil {
+			fail("Error loading watchlist", err)
+			return
+		}
+
+		entry, err := findWatchlistEntry(watchlist, args[0])
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		if len(args) == 1 && cmd.Flags().NFlag() == 0 {
+			printWatchlistEntry(entry)
+			return
+		}
+
+		err = watchlist.Update(entry.ShowID, func(e *config.WatchlistEntry) error {
+			if len(args) == 2 {
+				status, err := config.ParseWatchStatus(args[1])
+				if err != nil {
+					return err
+				}
+				e.SetStatus(status)
+			}
+			return applyListFlags(cmd, e)
+		})
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		updated, _ := watchlist.Find(entry.ShowID)
+		printWatchlistEntry(updated)
+	},
+}
+
+var listShowCmd = &cobra.Command{
+	Use:   "show [status]",
+	Short: "Print your watchlist",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		statuses := config.WatchStatuses
+		if len(args) == 1 {
+			status, err := config.ParseWatchStatus(args[0])
+			if err != nil {
+				fail("Error", err)
+				return
+			}
+			statuses = []config.WatchStatus{status}
+		}
+
+		watchlist, err := config.LoadWatchlist()
+		if err != nil {
+			fail("Error loading watchlist", err)
+			return
+		}
+
+		if outputJSON {
+			var entries []config.WatchlistEntry
+			for _, status := range statuses {
+				entries = append(entries, watchlist.ByStatus(status)...)
+			}
+			printJSON(entries)
+			return
+		}
+
+		printed := false
+		for _, status := range statuses {
+			entries := watchlist.ByStatus(status)
+			if len(entries) == 0 {
+				continue
+			}
+
+			heading := fmt.Sprintf("%s (%d)", strings.ToUpper(string(status)), len(entries))
+			fmt.Println(heading)
+			fmt.Println(strings.Repeat("=", len(heading)))
+			for i, entry := range entries {
+				fmt.Printf("%d. %s\n", i+1, entry.Title)
+				fmt.Printf("   %s\n", ui.DescribeWatchlistEntry(entry))
+				if entry.Notes != "" {
+					fmt.Printf("   %s\n", entry.Notes)
+				}
+			}
+			fmt.Println()
+			printed = true
+		}
+
+		if !printed {
+			fmt.Println("No shows found in your watchlist.")
+		}
+	},
+}
+
+func resolveShow(query string) (*scraper.Anime, error) {
+	if history, err := config.LoadHistory(); err == nil {
+		if entry, err := findHistoryEntry(history, query); err == nil {
+			return &scraper.Anime{Title: entry.Title, URL: entry.URL}, nil
+		}
+	}
+
+	fmt.Printf("Searching for: %s...\n", query)
+	animes, err := scraper.Search(query)
+	if err != nil {
+		return nil, fmt.Errorf("searching for anime: %w", err)
+	}
+	if len(animes) == 0 {
+		return nil, fmt.Errorf("no anime found")
+	}
+
+	return ui.SelectAnime(animes)
+}
+
+func findWatchlistEntry(watchlist *config.Watchlist, show string) (config.WatchlistEntry, error) {
+	if entry, exists := watchlist.Find(show); exists {
+		return entry, nil
+	}
+
+	matches := watchlist.Search(show)
+	for _, match := range matches {
+		if strings.EqualFold(match.Title, show) {
+			return match, nil
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return config.WatchlistEntry{}, fmt.Errorf("no watchlist entry matches '%s'", show)
+	case 1:
+		return matches[0], nil
+	}
+
+	titles := make([]string, len(matches))
+	for i, match := range matches {
+		titles[i] = fmt.Sprintf("%s (%s)", match.Title, match.ShowID)
+	}
+	return config.WatchlistEntry{}, fmt.Errorf("'%s' matches several shows: %s", show, strings.Join(titles, ", "))
+}
+
+func applyListFlags(cmd *cobra.Command, entry *config.WatchlistEntry) error {
+	if cmd.Flags().Changed("score") {
+		if err := entry.SetScore(listScore); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("notes") {
+		entry.Notes = listNotes
+	}
+	entry.AddTags(listTags...)
+	entry.RemoveTags(listUntags...)
+	return nil
+}
+
+func printWatchlistEntry(entry config.WatchlistEntry) {
+	if outputJSON {
+		printJSON(entry)
+		return
+	}
+
+	fmt.Printf("%s (%s)\n", entry.Title, entry.ShowID)
+	fmt.Printf("  Status: %s\n", entry.Status)
+	if entry.Score > 0 {
+		fmt.Printf("  Score:  %d/10\n", entry.Score)
+	}
+	if len(entry.Tags) > 0 {
+		fmt.Printf("  Tags:   %s\n", strings.Join(entry.Tags, ", "))
+	}
+	if entry.Notes != "" {
+		fmt.Printf("  Notes:  %s\n", entry.Notes)
+	}
+	fmt.Printf("  Added:  %s\n", entry.AddedAt.Format("Jan 2, 2006"))
+	if entry.CompletedAt != nil {
+		fmt.Printf("  Completed: %s\n", entry.CompletedAt.Format("Jan 2, 2006"))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.AddCommand(listAddCmd)
+	listCmd.AddCommand(listRemoveCmd)
+	listCmd.AddCommand(listStatusCmd)
+	listCmd.AddCommand(listShowCmd)
+
+	listAddCmd.Flags().StringVarP(&listStatus, "status", "s", string(config.StatusPlanned), "Initial status")
+	for _, c := range []*cobra.Command{listAddCmd, listStatusCmd} {
+		c.Flags().IntVar(&listScore, "score", 0, "Score from 1 to 10 (0 clears it)")
+		c.Flags().StringVar(&listNotes, "notes", "", "Free-form notes")
+		c.Flags().StringSliceVar(&listTags, "tag", nil, "Add tags (repeatable or comma separated)")
+	}
+	listStatusCmd.Flags().StringSliceVar(&listUntags, "untag", nil, "Remove tags")
+}
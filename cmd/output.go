@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/pkg/errors"
+)
+
+var (
+	outputJSON bool
+	exitCode   int
+)
+
+type showJSON struct {
+	Index     int      `json:"index,omitempty"`
+	ShowID    string   `json:"show_id"`
+	Title     string   `json:"title"`
+	URL       string   `json:"url"`
+	Episodes  string   `json:"available_episodes,omitempty"`
+	AltTitles []string `json:"alt_titles,omitempty"`
+	Thumbnail string   `json:"thumbnail,omitempty"`
+}
+
+type episodeJSON struct {
+	Episode  string  `json:"episode"`
+	Watched  bool    `json:"watched"`
+	Progress float64 `json:"progress"`
+}
+
+type episodeListJSON struct {
+	ShowID   string        `json:"show_id"`
+	Title    string        `json:"title,omitempty"`
+	Episodes []episodeJSON `json:"episodes"`
+}
+
+type streamJSON struct {
+	ShowID  string `json:"show_id"`
+	Title   string `json:"title,omitempty"`
+	Episode string `json:"episode"`
+	scraper.QualityOption
+}
+
+type errorJSON struct {
+	Message string           `json:"message"`
+	Error   string           `json:"error"`
+	Type    errors.ErrorType `json:"type,omitempty"`
+}
+
+// fail reports a command error and sets the exit status for its ErrorType.
+// With --json the error is written to stderr as a JSON object.
+func fail(message string, err error) {
+	exitCode = errors.ExitCode(err)
+
+	if outputJSON {
+		encoder := json.NewEncoder(os.Stderr)
+		encoder.Encode(errorJSON{Message: message, Error: err.Error(), Type: errors.TypeOf(err)})
+		return
+	}
+	fmt.Printf("%s: %v\n", message, err)
+}
+
+func printJSON(v any) {
+	if value := reflect.ValueOf(v); value.Kind() == reflect.Slice && value.IsNil() {
+		v = []any{}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		fail("Error encoding JSON", err)
+		return
+	}
+	buf.WriteTo(os.Stdout)
+}
+
+func printShows(animes []scraper.Anime) {
+	if outputJSON {
+		shows := make([]showJSON, len(animes))
+		for i, anime := range animes {
+			shows[i] = showJSON{
+				Index:     i + 1,
+				ShowID:    config.ShowIDFromURL(anime.URL),
+				Title:     anime.Title,
+				URL:       anime.URL,
+				Episodes:  anime.Episodes,
+				AltTitles: anime.AltTitles,
+				Thumbnail: anime.Thumbnail,
+			}
+		}
+		printJSON(shows)
+		return
+	}
+
+	if len(animes) == 0 {
+		fmt.Println("No anime found.")
+		return
+	}
+	for i, anime := range animes {
+		fmt.Printf("%3d. %s [%s]\n", i+1, anime.Title, config.ShowIDFromURL(anime.URL))
+	}
+}
+
+func printEpisodes(showID, title string, episodes []string) {
+	history, _ := config.LoadHistory()
+
+	list := episodeListJSON{ShowID: showID, Title: title, Episodes: make([]episodeJSON, len(episodes))}
+	for i, episode := range episodes {
+		list.Episodes[i].Episode = episode
+		if history == nil {
+			continue
+		}
+		if record, exists := history.GetEpisode(showID, episode); exists {
+			list.Episodes[i].Watched = record.Completed
+			list.Episodes[i].Progress = record.Progress()
+		}
+	}
+
+	if outputJSON {
+		printJSON(list)
+		return
+	}
+
+	if title != "" {
+		fmt.Printf("%s (%d episodes)\n", title, len(episodes))
+	}
+	for _, episode := range list.Episodes {
+		switch {
+		case episode.Watched:
+			fmt.Printf("  %s ✓\n", episode.Episode)
+		case episode.Progress > 0:
+			fmt.Printf("  %s (%.0f%%)\n", episode.Episode, episode.Progress*100)
+		default:
+			fmt.Printf("  %s\n", episode.Episode)
+		}
+	}
+}
+
+// requireInteractive rejects commands that can only open a TUI when JSON
+// output was requested.
+func requireInteractive(hint string) bool {
+	if !outputJSON {
+		return true
+	}
+	fail("Error", errors.New(errors.ValidationError, "this command is interactive and has no JSON output; "+hint))
+	return false
+}
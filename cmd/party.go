@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+
+	"github.com/keircn/karu/internal/party"
+	"github.com/keircn/karu/internal/player"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/internal/ui"
+	"github.com/keircn/karu/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	partyAddr      string
+	partyName      string
+	partyTolerance float64
+)
+
+var partyCmd = &cobra.Command{
+	Use:   "party",
+	Short: "Watch anime in sync with others on your network",
+	Long:  `Host or join a watch party where every participant's mpv stays in sync for play, pause, seek and episode changes.`,
+}
+
+var partyHostCmd = &cobra.Command{
+	Use:   "host [query]",
+	Short: "Host a watch party",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var query string
+		if len(args) > 0 {
+			query = args[0]
+		}
+
+		selection, err := workflow.GetAnimeSelection(query)
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		episode, err := ui.SelectEpisode(selection.Episodes, selection.ShowID, selection.Anime.Title)
+		if err != nil {
+			fail("Error selecting episode", err)
+			return
+		}
+
+		if episode == nil {
+			return
+		}
+
+		session := party.Session{
+			ShowID:    selection.ShowID,
+			ShowTitle: selection.Anime.Title,
+			MalID:     scraper.GetMalID(selection.ShowID),
+			Episodes:  selection.Episodes,
+			Episode:   *episode,
+		}
+
+		addr, err := partyListenAddr()
+		if err != nil {
+			fail("Error selecting address", err)
+			return
+		}
+
+		if addr == "" {
+			return
+		}
+
+		host, err := party.NewHost(addr, resolvePartyName(), session)
+		if err != nil {
+			fail("Error starting party", err)
+			return
+		}
+
+		fmt.Printf("Watch party listening on %s with session code %s\n", host.Addr(), host.Code())
+		for _, addr := range joinAddresses(host.Addr()) {
+			fmt.Printf("  Others can join with: karu party join %s %s\n", addr, host.Code())
+		}
+
+		err = player.PlayParty(player.PartyOptions{
+			Peer:         host,
+			Session:      session,
+			Participants: host.Participants(),
+			GetVideoURL:  scraper.GetVideoURL,
+		})
+		if err != nil {
+			fail("Error running watch party", err)
+		}
+	},
+}
+
+var partyJoinCmd = &cobra.Command{
+	Use:   "join <addr> <code>",
+	Short: "Join a watch party",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("Joining watch party at %s...\n", args[0])
+
+		guest, session, participants, err := party.Join(args[0], resolvePartyName(), args[1])
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		fmt.Printf("Joined as %s, watching %s\n", guest.Name(), session.ShowTitle)
+
+		err = player.PlayParty(player.PartyOptions{
+			Peer:         guest,
+			Session:      *session,
+			Participants: participants,
+			Tolerance:    partyTolerance,
+			GetVideoURL:  scraper.GetVideoURL,
+		})
+		if err != nil {
+			fail("Error running watch party", err)
+		}
+	},
+}
+
+func resolvePartyName() string {
+	if partyName != "" {
+		return partyName
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		return hostname
+	}
+	return "guest"
+}
+
+// partyListenAddr returns the address given with --addr, or asks which of
+// this machine's addresses to listen on. A bare host listens on the default
+// port.
+func partyListenAddr() (string, error) {
+	if partyAddr != "" {
+		if _, _, err := net.SplitHostPort(partyAddr); err != nil {
+			return net.JoinHostPort(partyAddr, strconv.Itoa(party.DefaultPort)), nil
+		}
+		return partyAddr, nil
+	}
+
+	choice, err := ui.SelectPartyAddress(append(localIPs(), "127.0.0.1"))
+	if err != nil || choice == nil {
+		return "", err
+	}
+	return net.JoinHostPort(*choice, strconv.Itoa(party.DefaultPort)), nil
+}
+
+// joinAddresses lists the addresses guests can use, which are all of this
+// machine's when the host listens on every interface.
+func joinAddresses(listenAddr string) []string {
+	host, _, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsUnspecified() {
+		return []string{listenAddr}
+	}
+	return localAddresses(listenAddr)
+}
+
+func localAddresses(listenAddr string) []string {
+	_, port, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		return nil
+	}
+
+	var addrs []string
+	for _, ip := range localIPs() {
+		addrs = append(addrs, net.JoinHostPort(ip, port))
+	}
+	return addrs
+}
+
+func localIPs() []string {
+	interfaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+
+	var ips []string
+	for _, addr := range interfaceAddrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+			continue
+		}
+		ips = append(ips, ipNet.IP.String())
+	}
+	return ips
+}
+
+func init() {
+	partyHostCmd.Flags().StringVar(&partyAddr, "addr", "", "Address to listen on (asks when not set)")
+	partyCmd.PersistentFlags().StringVarP(&partyName, "name", "n", "", "Name shown to other participants")
+	partyJoinCmd.Flags().Float64Var(&partyTolerance, "tolerance", player.DefaultSyncTolerance, "Allowed drift in seconds before resyncing")
+
+	partyCmd.AddCommand(partyHostCmd)
+	partyCmd.AddCommand(partyJoinCmd)
+	rootCmd.AddCommand(partyCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/proxy"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/internal/ui"
+	"github.com/keircn/karu/internal/workflow"
+	"github.com/keircn/karu/pkg/validation"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proxyBind string
+	proxyPort int
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy [stream-url]",
+	Short: "Serve a stream through a local header-injecting proxy",
+	Long: `Start a local HTTP server that fetches a resolved stream with the headers the source requires.
+HLS playlists are rewritten so every segment also goes through the proxy, and Range requests are passed through.
+Without a URL, an episode is selected interactively.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var videoURL string
+		if len(args) > 0 {
+			videoURL = args[0]
+		} else {
+			selection, err := workflow.GetAnimeSelection("")
+			if err != nil {
+				fail("Error", err)
+				return
+			}
+
+			episode, err := ui.SelectEpisode(selection.Episodes, selection.ShowID, selection.Anime.Title)
+			if err != nil {
+				fail("Error selecting episode", err)
+				return
+			}
+			if episode == nil {
+				return
+			}
+
+			fmt.Printf("Getting video source for episode %s...\n", *episode)
+			videoURL, err = scraper.GetVideoURL(selection.ShowID, *episode)
+			if err != nil {
+				fail("Error getting video URL", err)
+				return
+			}
+		}
+
+		if err := validation.ValidateURL(videoURL); err != nil {
+			fail("Error", err)
+			return
+		}
+
+		port := proxyPort
+		if !cmd.Flags().Changed("port") {
+			if cfg, err := config.Load(); err == nil {
+				port = cfg.ProxyPort
+			}
+		}
+
+		srv, err := proxy.New(net.JoinHostPort(proxyBind, strconv.Itoa(port)), scraper.StreamHeaders())
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+		defer srv.Close()
+
+		if ip := net.ParseIP(proxyBind); ip != nil && ip.IsUnspecified() {
+			if addrs := localAddresses(srv.Addr()); len(addrs) > 0 {
+				host, _, _ := net.SplitHostPort(addrs[0])
+				srv.SetPublicHost(host)
+			}
+		}
+
+		fmt.Printf("Proxy listening on %s\n", srv.Addr())
+		fmt.Printf("Stream URL: %s\n", srv.URL(videoURL))
+		fmt.Println("Press Ctrl+C to stop.")
+
+		select {}
+	},
+}
+
+func init() {
+	proxyCmd.Flags().StringVar(&proxyBind, "bind", "127.0.0.1", "Address to bind the proxy to")
+	proxyCmd.Flags().IntVarP(&proxyPort, "port", "p", 0, "Port to listen on (0 picks a free port)")
+	rootCmd.AddCommand(proxyCmd)
+}
@@ -1,29 +1,165 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/hooks"
+	"github.com/keircn/karu/internal/updatecheck"
+	"github.com/keircn/karu/internal/version"
+	"github.com/keircn/karu/pkg/errors"
+	"github.com/keircn/karu/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
+var (
+	rootProfile  string
+	keyProblems  []error
+	updateNotice string
+	updateResult chan config.UpdateCheck
+)
+
+var configFlags = map[string]string{
+	"player":       "player",
+	"quality":      "quality",
+	"download-dir": "download_dir",
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "karu",
 	Short: "A fast and pretty CLI for watching anime",
-	Long:  `Karu is a command-line interface for discovering and watching anime with interactive browsing and seamless video playback.`,
+	Long: `Karu is a command-line interface for discovering and watching anime with interactive browsing and seamless video playback.
+
+Settings are read from the config file, then KARU_* environment variables
+(for example KARU_QUALITY=720p), then the global flags below. Use --profile
+to apply a named profile from the config file.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if rootProfile != "" {
+			config.SetProfile(rootProfile)
+		}
+
+		for flag, key := range configFlags {
+			if !cmd.Flags().Changed(flag) {
+				continue
+			}
+			value, _ := cmd.Flags().GetString(flag)
+			if err := config.SetFlagOverride(key, value); err != nil {
+				return err
+			}
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			ui.UseTheme("")
+			// The config commands report the error themselves, and reset and
+			// path, set and edit have to keep working to repair a broken config.
+			if cmd.Parent() == configCmd {
+				return nil
+			}
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			if errors.TypeOf(err) != errors.ConfigError {
+				err = errors.Wrap(err, errors.ConfigError, "invalid configuration")
+			}
+			return err
+		}
+
+		keyProblems = ui.LoadKeyMap(cfg.KeyBindings())
+		if len(keyProblems) > 0 && cmd != keysCmd {
+			fmt.Fprintln(os.Stderr, "Warning: ignoring custom key bindings (run 'karu keys' for details)")
+		}
+		if cmd != versionCmd {
+			startUpdateCheck(cfg)
+		}
+		ui.UseTheme(cfg.Theme)
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		showUpdateNotice()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
 }
 
+func init() {
+	config.RegisterThemes(ui.ThemeNames())
+	config.RegisterKeyBindingCheck(func(bindings map[string]map[string][]string) []error {
+		keymap := ui.DefaultKeyMap()
+		problems := keymap.Override(bindings)
+		return append(problems, keymap.Conflicts()...)
+	})
+
+	rootCmd.PersistentFlags().StringVar(&rootProfile, "profile", "", "Use a named profile from the config file")
+	rootCmd.PersistentFlags().String("player", "", "Video player to use for this run")
+	rootCmd.PersistentFlags().String("quality", "", "Preferred video quality for this run")
+	rootCmd.PersistentFlags().String("download-dir", "", "Download directory for this run")
+	rootCmd.PersistentFlags().BoolVar(&outputJSON, "json", false, "Print machine-readable JSON instead of text or a TUI")
+}
+
+func startUpdateCheck(cfg *config.Config) {
+	if !updatecheck.Enabled(cfg.CheckUpdates) {
+		return
+	}
+
+	cached, due := updatecheck.Due(cfg.CheckUpdates)
+	updateNotice = updatecheck.Notice(cached, version.Get().Version)
+	if !due {
+		return
+	}
+
+	// The attempt is recorded first, so a check cut short when the command
+	// exits is not retried on every run until the interval has passed.
+	attempt := cached
+	attempt.CheckedAt = time.Now()
+	config.PutUpdateCheck(attempt)
+
+	updateResult = make(chan config.UpdateCheck, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if check, err := updatecheck.Latest(ctx, cfg.UpdateEndpoint); err == nil {
+			updateResult <- check
+		}
+		close(updateResult)
+	}()
+}
+
+func showUpdateNotice() {
+	if updateResult != nil {
+		select {
+		case check, ok := <-updateResult:
+			if ok {
+				updateNotice = updatecheck.Notice(check, version.Get().Version)
+			}
+		case <-time.After(time.Second):
+		}
+	}
+
+	if updateNotice != "" {
+		fmt.Fprintln(os.Stderr, "\n"+ui.MutedStyle.Render(updateNotice))
+	}
+}
+
 func Execute() {
 	setupSignalHandling()
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	for _, hookErr := range hooks.Wait() {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", hookErr)
+	}
+	if err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(errors.ExitCode(err))
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
 	}
 }
 
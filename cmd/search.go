@@ -8,14 +8,23 @@ import (
 	"github.com/keircn/karu/internal/scraper"
 	"github.com/keircn/karu/internal/ui"
 	"github.com/keircn/karu/internal/workflow"
+	"github.com/keircn/karu/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 var searchCmd = &cobra.Command{
 	Use:   "search [query]",
 	Short: "Search for anime by title",
-	Long:  `Search for anime by title and interactively select episodes to watch.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Search for anime by title and interactively select episodes to watch.
+Press esc to go back a step (from episodes to the results, for example) and
+alt+right to go forward again.
+
+Use --pick to choose a result without prompting, by its position in the
+results (1 is the first), its exact title or its show ID. Add --episode to
+choose an episode (a number, first, latest or next) and --print-url to print
+its stream URL instead of playing it. With --json, results, episodes and
+stream URLs are printed as JSON.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var query string
 		if len(args) > 0 {
@@ -24,41 +33,54 @@ var searchCmd = &cobra.Command{
 
 		autoQuality, _ := cmd.Flags().GetBool("auto-quality")
 		useHistory, _ := cmd.Flags().GetBool("history")
+		pick, _ := cmd.Flags().GetString("pick")
+		episodeSelector, _ := cmd.Flags().GetString("episode")
+		printURL, _ := cmd.Flags().GetBool("print-url")
 
-		var selection *workflow.AnimeSelection
-		var err error
+		if outputJSON || pick != "" || episodeSelector != "" || printURL {
+			runScriptedSearch(query, pick, episodeSelector, printURL)
+			return
+		}
 
-		if useHistory {
-			selection, err = workflow.GetAnimeSelectionFromHistory()
-		} else {
-			selection, err = workflow.GetAnimeSelection(query)
+		if !useHistory {
+			if err := ui.RunApp(ui.AppOptions{Query: query, AutoQuality: autoQuality, Download: downloadFromApp}); err != nil {
+				fail("Error", err)
+			}
+			return
 		}
 
+		selection, err := workflow.GetAnimeSelectionFromHistory()
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			fail("Error", err)
 			return
 		}
 
 		fmt.Printf("You chose: %s\n", selection.Anime.Title)
 
-		episode, err := ui.SelectEpisode(selection.Episodes, selection.Anime.Title)
+		batch, err := ui.SelectEpisodes(selection.Episodes, selection.ShowID, selection.Anime.Title)
 		if err != nil {
-			fmt.Printf("Error selecting episode: %v\n", err)
+			fail("Error selecting episode", err)
 			return
 		}
 
-		if episode != nil {
-			fmt.Printf("You chose episode: %s\n", *episode)
+		if batch != nil && batch.Action != ui.ActionPlay {
+			runEpisodeBatch(selection, batch)
+			return
+		}
 
-			scraper.PreloadAdjacentEpisodes(selection.ShowID, selection.Episodes, *episode)
+		if batch != nil {
+			episode := batch.Episodes[0]
+			fmt.Printf("You chose episode: %s\n", episode)
+
+			scraper.PreloadAdjacentEpisodes(selection.ShowID, selection.Episodes, episode)
 
 			cfg, _ := config.Load()
 
 			if autoQuality {
-				fmt.Printf("Getting video source for episode %s...\n", *episode)
-				videoURL, err := scraper.GetVideoURLWithQuality(selection.ShowID, *episode, cfg.Quality)
+				fmt.Printf("Getting video source for episode %s...\n", episode)
+				videoURL, err := scraper.GetVideoURLWithQuality(selection.ShowID, episode, cfg.Quality)
 				if err != nil {
-					fmt.Printf("Error getting video URL: %v\n", err)
+					fail("Error getting video URL", err)
 					return
 				}
 
@@ -70,8 +92,9 @@ var searchCmd = &cobra.Command{
 						ShowID:    selection.ShowID,
 						ShowTitle: selection.Anime.Title,
 						Episodes:  selection.Episodes,
-						Current:   *episode,
+						Current:   episode,
 						VideoURL:  videoURL,
+						MalID:     scraper.GetMalID(selection.ShowID),
 					}
 
 					getVideoURLFunc := func(showID, ep string) (string, error) {
@@ -80,26 +103,26 @@ var searchCmd = &cobra.Command{
 					}
 
 					if err := player.PlayWithAutoNext(playbackInfo, getVideoURLFunc); err != nil {
-						fmt.Printf("Error playing video: %v\n", err)
+						fail("Error playing video", err)
 					}
 				} else {
 					if err := player.Play(videoURL); err != nil {
-						fmt.Printf("Error playing video: %v\n", err)
+						fail("Error playing video", err)
 					}
 				}
 				return
 			}
 
-			fmt.Printf("Loading available qualities for episode %s...\n", *episode)
-			qualities, err := scraper.GetAvailableQualities(selection.ShowID, *episode)
+			fmt.Printf("Loading available qualities for episode %s...\n", episode)
+			qualities, err := scraper.GetAvailableQualities(selection.ShowID, episode)
 			if err != nil {
-				fmt.Printf("Error getting video qualities: %v\n", err)
+				fail("Error getting video qualities", err)
 				return
 			}
 
 			selectedQuality, err := ui.SelectQuality(qualities)
 			if err != nil {
-				fmt.Printf("Error selecting quality: %v\n", err)
+				fail("Error selecting quality", err)
 				return
 			}
 
@@ -118,8 +141,9 @@ var searchCmd = &cobra.Command{
 					ShowID:    selection.ShowID,
 					ShowTitle: selection.Anime.Title,
 					Episodes:  selection.Episodes,
-					Current:   *episode,
+					Current:   episode,
 					VideoURL:  selectedQuality.URL,
+					MalID:     scraper.GetMalID(selection.ShowID),
 				}
 
 				getVideoURLFunc := func(showID, ep string) (string, error) {
@@ -140,17 +164,83 @@ var searchCmd = &cobra.Command{
 				}
 
 				if err := player.PlayWithAutoNext(playbackInfo, getVideoURLFunc); err != nil {
-					fmt.Printf("Error playing video: %v\n", err)
+					fail("Error playing video", err)
 				}
 			} else {
 				if err := player.Play(selectedQuality.URL); err != nil {
-					fmt.Printf("Error playing video: %v\n", err)
+					fail("Error playing video", err)
 				}
 			}
 		}
 	},
 }
 
+// runScriptedSearch is the non-interactive form of search used by scripts.
+func runScriptedSearch(query, pick, episodeSelector string, printURL bool) {
+	if query == "" {
+		fail("Error", errors.New(errors.ValidationError, "a search query is required with --json, --pick, --episode or --print-url"))
+		return
+	}
+	if pick == "" && (episodeSelector != "" || printURL) {
+		fail("Error", errors.New(errors.ValidationError, "--episode and --print-url need --pick"))
+		return
+	}
+
+	if pick == "" {
+		animes, err := scraper.Search(query)
+		if err != nil {
+			fail("Error searching", err)
+			return
+		}
+		printShows(animes)
+		return
+	}
+
+	selection, err := workflow.PickAnimeSelection(query, pick)
+	if err != nil {
+		fail("Error selecting anime", err)
+		return
+	}
+	showID, title := selection.ShowID, selection.Anime.Title
+
+	if episodeSelector == "" {
+		if !printURL {
+			printEpisodes(showID, title, selection.Episodes)
+			return
+		}
+		episodeSelector = "next"
+	}
+
+	episode, err := workflow.PickEpisode(showID, selection.Episodes, episodeSelector)
+	if err != nil {
+		fail("Error selecting episode", err)
+		return
+	}
+
+	if printURL || outputJSON {
+		printStreamURL(showID, title, episode)
+		return
+	}
+
+	fmt.Printf("Playing %s episode %s\n", title, episode)
+	playSelectedEpisode(selection, episode)
+}
+
+func printStreamURL(showID, title, episode string) {
+	cfg, _ := config.Load()
+	option, err := scraper.GetPreferredQuality(showID, episode, cfg.Quality)
+	if err != nil {
+		fail("Error getting video URL", err)
+		return
+	}
+
+	if outputJSON {
+		printJSON(streamJSON{ShowID: showID, Title: title, Episode: episode, QualityOption: *option})
+		return
+	}
+	fmt.Println(option.URL)
+}
+
 func getAutoPlayStatus(enabled bool) string {
 	if enabled {
 		return "enabled"
@@ -162,4 +252,7 @@ func init() {
 	rootCmd.AddCommand(searchCmd)
 	searchCmd.Flags().BoolP("auto-quality", "a", false, "Automatically select quality based on config")
 	searchCmd.Flags().BoolP("history", "H", false, "Browse search history instead of searching")
+	searchCmd.Flags().String("pick", "", "Choose a result by position, exact title or show ID without prompting")
+	searchCmd.Flags().String("episode", "", "Choose an episode (number, first, latest or next) without prompting")
+	searchCmd.Flags().Bool("print-url", false, "Print the stream URL instead of playing it")
 }
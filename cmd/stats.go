@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var statsSince string
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how much you have been watching",
+	Long: `Show watch statistics: episodes and estimated hours watched, completed
+shows, daily streaks, an activity heatmap and weekly and monthly breakdowns.
+
+Use --since with a date (2025-01-01, 2025-01, 2025) or a span (30d, 12w, 6m,
+1y) to limit the statistics to recent activity.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		now := time.Now()
+
+		var since time.Time
+		if statsSince != "" {
+			var err error
+			since, err = stats.ParseSince(statsSince, now)
+			if err != nil {
+				fail("Error", err)
+				return
+			}
+		}
+
+		events, err := config.GetWatchEvents(since)
+		if err != nil {
+			fail("Error loading watch log", err)
+			return
+		}
+
+		history, err := config.LoadHistory()
+		if err != nil {
+			fail("Error loading history", err)
+			return
+		}
+
+		watchlist, err := config.LoadWatchlist()
+		if err != nil {
+			fail("Error loading watchlist", err)
+			return
+		}
+
+		showIDs := make([]string, 0, len(history.Entries))
+		for _, entry := range history.Entries {
+			showIDs = append(showIDs, entry.ShowID)
+		}
+		genres := make(map[string][]string)
+		if metadata, err := config.GetShowMetadata(showIDs); err == nil {
+			for showID, entry := range metadata {
+				genres[showID] = entry.Genres
+			}
+		}
+
+		result := stats.Compute(events, history, watchlist, genres, since, now)
+
+		if outputJSON {
+			printJSON(result)
+			return
+		}
+
+		printStats(result, now)
+	},
+}
+
+func printStats(result *stats.Stats, now time.Time) {
+	title := "Watch Statistics"
+	if result.Since != nil {
+		title += fmt.Sprintf(" since %s", result.Since.Format("Jan 2, 2006"))
+	}
+	fmt.Println(title)
+	fmt.Println(strings.Repeat("=", len(title)))
+
+	if result.Episodes == 0 {
+		fmt.Println("Nothing watched yet.")
+		return
+	}
+
+	fmt.Printf("Episodes watched:  %d\n", result.Episodes)
+	fmt.Printf("Time watched:      %.1f hours\n", result.Hours)
+	fmt.Printf("Shows watched:     %d\n", result.Shows)
+	fmt.Printf("Shows completed:   %d\n", result.ShowsCompleted)
+	fmt.Printf("Current streak:    %s\n", pluralDays(result.CurrentStreak))
+	fmt.Printf("Longest streak:    %s\n", pluralDays(result.LongestStreak))
+
+	weeks := 52
+	if result.Since != nil {
+		weeks = min(weeks, int(now.Sub(*result.Since).Hours()/24/7)+1)
+	}
+	fmt.Printf("\nActivity\n\n%s", stats.Heatmap(result.Days, now, weeks))
+
+	printCounts("Top shows", result.TopShows)
+	printCounts("Top genres", result.TopGenres)
+
+	printPeriods("Weekly", result.Weeks, 8, func(period stats.Period) string {
+		return "Week of " + period.Start.Format("Jan 2")
+	})
+	printPeriods("Monthly", result.Months, 12, func(period stats.Period) string {
+		return period.Start.Format("Jan 2006")
+	})
+}
+
+func printCounts(title string, counts []stats.Count) {
+	if len(counts) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", title)
+	for i, count := range counts {
+		fmt.Printf("%d. %s (%d episodes)\n", i+1, count.Name, count.Episodes)
+	}
+}
+
+func printPeriods(title string, periods []stats.Period, limit int, label func(stats.Period) string) {
+	if len(periods) > limit {
+		periods = periods[len(periods)-limit:]
+	}
+
+	fmt.Printf("\n%s\n", title)
+	for _, period := range periods {
+		fmt.Printf("%-16s %4d episodes  %5.1f hours\n", label(period), period.Episodes, period.Hours)
+	}
+}
+
+func pluralDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsSince, "since", "", "Only include activity since this date or span (e.g. 2025-01-01, 30d, 6m)")
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/internal/anilist"
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/ui"
+	"github.com/keircn/karu/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncPull bool
+	syncYear int
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Synchronise progress with external tracking services",
+}
+
+var syncAniListCmd = &cobra.Command{
+	Use:   "anilist",
+	Short: "Push local progress to AniList, or pull your AniList list",
+	Long: `Push the progress of every show in your history to AniList.
+
+With --pull, your AniList anime list is imported into the local watchlist
+instead. Shows are matched to AniList automatically; use 'karu sync anilist
+map' to fix a show that was matched wrongly or could not be matched.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		service, err := newAniListService()
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		if syncPull {
+			pullAniList(ctx, service)
+			return
+		}
+
+		history, err := config.LoadHistory()
+		if err != nil {
+			fail("Error loading history", err)
+			return
+		}
+
+		pushed := 0
+		for _, entry := range history.GetRecent(0) {
+			if anilist.Progress(entry) == 0 {
+				continue
+			}
+
+			progress, err := service.PushProgress(ctx, entry.ShowID, entry.Title)
+			if err != nil {
+				fmt.Printf("! %s: %v\n", entry.Title, err)
+				continue
+			}
+			fmt.Printf("✓ %s: episode %d\n", entry.Title, progress)
+			pushed++
+		}
+
+		fmt.Printf("\nUpdated %d show(s) on AniList.\n", pushed)
+	},
+}
+
+var syncAniListMapCmd = &cobra.Command{
+	Use:   "map <show> [anilist-id]",
+	Short: "Link a show to an AniList entry",
+	Long: `Link a show from your history or watchlist to an AniList entry. Without an
+ID, AniList is searched by title (and --year) and you pick the right entry.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		service, err := newAniListService()
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		showID, title, err := findKnownShow(args[0])
+		if err != nil {
+			fail("Error", err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var media *anilist.Media
+		if len(args) == 2 {
+			mediaID, err := strconv.Atoi(args[1])
+			if err != nil || mediaID <= 0 {
+				fail("Error", errors.New(errors.ValidationError, "invalid AniList ID '"+args[1]+"'"))
+				return
+			}
+			media, err = service.Client().GetMedia(ctx, mediaID)
+			if err != nil {
+				fail("Error", err)
+				return
+			}
+		} else {
+			results, err := service.Client().SearchMedia(ctx, title, syncYear)
+			if err != nil {
+				fail("Error", err)
+				return
+			}
+			if len(results) == 0 {
+				fmt.Printf("No AniList entries found for %s.\n", title)
+				return
+			}
+			media, err = ui.SelectAniListMedia(results)
+			if err != nil {
+				fail("Error selecting entry", err)
+				return
+			}
+			if media == nil {
+				return
+			}
+		}
+
+		if err := service.Map(showID, media.ID); err != nil {
+			fail("Error saving mapping", err)
+			return
+		}
+
+		fmt.Printf("Linked %s to AniList entry %s (%d).\n", title, media.Title.Preferred(), media.ID)
+	},
+}
+
+func newAniListService() (*anilist.Service, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.DefaultConfig
+	}
+	return anilist.NewService(cfg)
+}
+
+func pullAniList(ctx context.Context, service *anilist.Service) {
+	fmt.Println("Importing your AniList list...")
+
+	result, err := service.Pull(ctx)
+	if result == nil {
+		fail("Error", err)
+		return
+	}
+
+	for _, title := range result.Added {
+		fmt.Printf("+ %s\n", title)
+	}
+	for _, title := range result.Updated {
+		fmt.Printf("~ %s\n", title)
+	}
+	for _, title := range result.Unmatched {
+		fmt.Printf("? %s (no matching show found)\n", title)
+	}
+
+	fmt.Printf("\nAdded %d, updated %d, unmatched %d.\n", len(result.Added), len(result.Updated), len(result.Unmatched))
+	if err != nil {
+		fail("Error", err)
+	}
+}
+
+func findKnownShow(show string) (string, string, error) {
+	if history, err := config.LoadHistory(); err == nil {
+		if entry, err := findHistoryEntry(history, show); err == nil {
+			return entry.ShowID, entry.Title, nil
+		}
+	}
+
+	watchlist, err := config.LoadWatchlist()
+	if err != nil {
+		return "", "", err
+	}
+
+	entry, err := findWatchlistEntry(watchlist, show)
+	if err != nil {
+		return "", "", fmt.Errorf("'%s' is not in your history or watchlist", strings.TrimSpace(show))
+	}
+	return entry.ShowID, entry.Title, nil
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.AddCommand(syncAniListCmd)
+	syncAniListCmd.AddCommand(syncAniListMapCmd)
+
+	syncAniListCmd.Flags().BoolVar(&syncPull, "pull", false, "Import your AniList list into the local watchlist")
+	syncAniListMapCmd.Flags().IntVar(&syncYear, "year", 0, "Only search AniList entries that aired in this year")
+}
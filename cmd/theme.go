@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var themeCmd = &cobra.Command{
+	Use:   "theme",
+	Short: "List, preview and choose color themes",
+	Long: `Manage the color theme used by every menu and player screen.
+
+The theme is stored in the "theme" config key. Setting the NO_COLOR
+environment variable always selects the no-color theme.`,
+}
+
+var themeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available themes",
+	Run: func(cmd *cobra.Command, args []string) {
+		active := ui.CurrentTheme().Name
+		if outputJSON {
+			type themeJSON struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+				Active      bool   `json:"active"`
+			}
+
+			var themes []themeJSON
+			for _, theme := range ui.Themes() {
+				themes = append(themes, themeJSON{theme.Name, theme.Description, theme.Name == active})
+			}
+			printJSON(themes)
+			return
+		}
+
+		for _, theme := range ui.Themes() {
+			marker := " "
+			if theme.Name == active {
+				marker = "*"
+			}
+			fmt.Printf("%s %-14s %s\n", marker, theme.Name, theme.Description)
+		}
+	},
+}
+
+var themePreviewCmd = &cobra.Command{
+	Use:   "preview [name]",
+	Short: "Preview one or all themes",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		themes := ui.Themes()
+		if len(args) == 1 {
+			theme, exists := ui.LookupTheme(args[0])
+			if !exists {
+				fmt.Printf("Unknown theme: %s\n", args[0])
+				return
+			}
+			themes = []ui.Theme{theme}
+		}
+
+		for i, theme := range themes {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s - %s\n\n", theme.Name, theme.Description)
+			fmt.Print(theme.Preview())
+		}
+	},
+}
+
+var themeSetCmd = &cobra.Command{
+	Use:   "set [name]",
+	Short: "Set the color theme",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load()
+		if err != nil {
+			fail("Error loading config", err)
+			return
+		}
+
+		if err := cfg.Set("theme", args[0]); err != nil {
+			fail("Error", err)
+			return
+		}
+
+		fmt.Printf("Theme set to %s\n", args[0])
+		if os.Getenv("NO_COLOR") != "" {
+			fmt.Println("NO_COLOR is set, so colors stay disabled until it is unset.")
+		}
+	},
+}
+
+func init() {
+	themeCmd.AddCommand(themeListCmd)
+	themeCmd.AddCommand(themePreviewCmd)
+	themeCmd.AddCommand(themeSetCmd)
+	rootCmd.AddCommand(themeCmd)
+}
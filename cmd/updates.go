@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keircn/karu/internal/releases"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/internal/ui"
+	"github.com/keircn/karu/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var updatesCmd = &cobra.Command{
+	Use:   "updates",
+	Short: "Check followed shows for new episodes",
+	Long: `Check the shows you are watching for newly released episodes.
+
+Followed shows are the watchlist entries marked as watching plus any show in
+your history that you have started but not finished. Pick a show from the
+result list to jump straight to its next unwatched episode.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !outputJSON {
+			fmt.Println("Checking followed shows for new episodes...")
+		}
+
+		updates, err := releases.Check()
+		if err != nil && updates == nil {
+			fail("Error checking for updates", err)
+			return
+		}
+
+		if outputJSON {
+			printJSON(updates)
+			return
+		}
+
+		if err != nil {
+			fmt.Printf("Warning: failed to save episode counts: %v\n", err)
+		}
+
+		if len(updates) == 0 {
+			fmt.Println("You are not following any shows. Add some with 'karu list add <show> --status watching'.")
+			return
+		}
+
+		printUpdates(updates)
+
+		choice, err := ui.SelectShowUpdate(updates)
+		if err != nil {
+			fail("Error selecting show", err)
+			return
+		}
+		if choice == nil {
+			return
+		}
+
+		selection := &workflow.AnimeSelection{
+			Anime:    &scraper.Anime{Title: choice.Title, URL: choice.URL},
+			ShowID:   choice.ShowID,
+			Episodes: choice.Episodes,
+		}
+		fmt.Printf("You chose: %s, episode %s\n", choice.Title, choice.NextEpisode)
+		playSelectedEpisode(selection, choice.NextEpisode)
+	},
+}
+
+func printUpdates(updates []releases.ShowUpdate) {
+	newCount := 0
+	for _, update := range updates {
+		if update.HasNew() {
+			newCount++
+		}
+	}
+
+	fmt.Printf("\n%d of %d followed shows have new episodes:\n", newCount, len(updates))
+	fmt.Println("==========================================")
+	for _, update := range updates {
+		switch {
+		case update.Error != "":
+			fmt.Printf("! %s: %s\n", update.Title, update.Error)
+		case update.HasNew():
+			fmt.Printf("+ %s: %d new (%s)\n", update.Title, len(update.NewEpisodes), strings.Join(update.NewEpisodes, ", "))
+		default:
+			fmt.Printf("  %s: no new episodes\n", update.Title)
+		}
+		if update.Error == "" && update.Unwatched > 0 {
+			fmt.Printf("    %d unwatched, next up: episode %s\n", update.Unwatched, update.NextEpisode)
+		}
+	}
+	fmt.Println()
+}
+
+func init() {
+	rootCmd.AddCommand(updatesCmd)
+}
@@ -1,19 +1,98 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/updatecheck"
+	"github.com/keircn/karu/internal/version"
 	"github.com/spf13/cobra"
 )
 
+var versionCheck bool
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of karu",
+	Long: `Print the version, commit, build date and Go version of this karu binary.
+
+With --check, also look up the latest release right away. Otherwise karu
+checks in the background as often as the check_updates setting allows
+(daily, weekly or never) and mentions a new release found by the previous
+check after a command finishes.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("karu v0.2.0")
+		info := version.Get()
+		if outputJSON {
+			printVersionJSON(info)
+			return
+		}
+
+		fmt.Printf("karu %s\n", info.Version)
+		if info.Commit != "" {
+			fmt.Printf("  commit:   %s\n", info.Commit)
+		}
+		if info.Date != "" {
+			fmt.Printf("  built:    %s\n", info.Date)
+		}
+		fmt.Printf("  go:       %s\n", info.GoVersion)
+		fmt.Printf("  platform: %s\n", info.Platform)
+
+		if !versionCheck {
+			return
+		}
+
+		fmt.Println()
+		check, err := checkLatestRelease()
+		if err != nil {
+			fail("Error checking for updates", err)
+			return
+		}
+
+		switch {
+		case version.Newer(check.Latest, info.Version):
+			fmt.Println(updatecheck.Notice(check, info.Version))
+		case !info.IsRelease():
+			fmt.Printf("Latest release is %s (this is a development build)\n", check.Latest)
+		default:
+			fmt.Printf("You are running the latest version (%s)\n", check.Latest)
+		}
 	},
 }
 
+func checkLatestRelease() (config.UpdateCheck, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.UpdateCheck{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return updatecheck.Latest(ctx, cfg.UpdateEndpoint)
+}
+
+func printVersionJSON(info version.Info) {
+	result := struct {
+		version.Info
+		Latest          string `json:"latest,omitempty"`
+		UpdateAvailable bool   `json:"update_available,omitempty"`
+	}{Info: info}
+
+	if versionCheck {
+		check, err := checkLatestRelease()
+		if err != nil {
+			fail("Error checking for updates", err)
+			return
+		}
+		result.Latest = check.Latest
+		result.UpdateAvailable = version.Newer(check.Latest, info.Version)
+	}
+	printJSON(result)
+}
+
 func init() {
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check for a newer release now")
 	rootCmd.AddCommand(versionCmd)
 }
@@ -0,0 +1,288 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/pkg/errors"
+	"github.com/keircn/karu/pkg/graphql"
+	karuhttp "github.com/keircn/karu/pkg/http"
+)
+
+const (
+	DefaultEndpoint = "https://graphql.anilist.co"
+	AuthorizeURL    = "https://anilist.co/api/v2/oauth/authorize"
+)
+
+type MediaListStatus string
+
+const (
+	StatusCurrent   MediaListStatus = "CURRENT"
+	StatusPlanning  MediaListStatus = "PLANNING"
+	StatusCompleted MediaListStatus = "COMPLETED"
+	StatusDropped   MediaListStatus = "DROPPED"
+	StatusPaused    MediaListStatus = "PAUSED"
+	StatusRepeating MediaListStatus = "REPEATING"
+)
+
+type Title struct {
+	Romaji  string `json:"romaji"`
+	English string `json:"english"`
+	Native  string `json:"native"`
+}
+
+func (t Title) Preferred() string {
+	if t.English != "" {
+		return t.English
+	}
+	if t.Romaji != "" {
+		return t.Romaji
+	}
+	return t.Native
+}
+
+func (t Title) Matches(name string) bool {
+	for _, title := range []string{t.Romaji, t.English, t.Native} {
+		if title != "" && strings.EqualFold(strings.TrimSpace(title), strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+type Media struct {
+	ID         int    `json:"id"`
+	IDMal      int    `json:"idMal"`
+	Title      Title  `json:"title"`
+	SeasonYear int    `json:"seasonYear"`
+	Episodes   int    `json:"episodes"`
+	Format     string `json:"format"`
+}
+
+type User struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ListEntry struct {
+	MediaID  int             `json:"mediaId"`
+	Status   MediaListStatus `json:"status"`
+	Progress int             `json:"progress"`
+	Score    float64         `json:"score"`
+	Notes    string          `json:"notes"`
+	Media    Media           `json:"media"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+func checkErrors(errs []graphqlError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+	return errors.New(errors.NetworkError, "anilist: "+strings.Join(messages, "; "))
+}
+
+const viewerQuery = `query {
+	Viewer {
+		id
+		name
+	}
+}`
+
+const mediaFields = `
+	id
+	idMal
+	title { romaji english native }
+	seasonYear
+	episodes
+	format`
+
+const searchQuery = `query ($search: String, $year: Int) {
+	Page(perPage: 10) {
+		media(search: $search, seasonYear: $year, type: ANIME) {` + mediaFields + `
+		}
+	}
+}`
+
+const mediaQuery = `query ($id: Int) {
+	Media(id: $id, type: ANIME) {` + mediaFields + `
+	}
+}`
+
+const listQuery = `query ($userId: Int) {
+	MediaListCollection(userId: $userId, type: ANIME) {
+		lists {
+			entries {
+				mediaId
+				status
+				progress
+				score(format: POINT_10)
+				notes
+				media {` + mediaFields + `
+				}
+			}
+		}
+	}
+}`
+
+const saveEntryMutation = `mutation ($mediaId: Int, $progress: Int, $status: MediaListStatus) {
+	SaveMediaListEntry(mediaId: $mediaId, progress: $progress, status: $status) {
+		id
+		progress
+		status
+	}
+}`
+
+type Client struct {
+	endpoint   string
+	httpClient *karuhttp.Client
+}
+
+func NewClient(endpoint, token string) *Client {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	opts := []karuhttp.ClientOption{
+		karuhttp.WithTimeout(10 * time.Second),
+		karuhttp.WithUserAgent("karu"),
+		karuhttp.WithHeader("Accept", "application/json"),
+	}
+	if token != "" {
+		opts = append(opts, karuhttp.WithHeader("Authorization", "Bearer "+token))
+	}
+
+	return &Client{
+		endpoint:   endpoint,
+		httpClient: karuhttp.NewClient(opts...),
+	}
+}
+
+func (c *Client) query(query string) *graphql.QueryBuilder {
+	return graphql.NewQueryBuilder(c.endpoint, c.httpClient).SetQuery(query)
+}
+
+func (c *Client) Viewer(ctx context.Context) (*User, error) {
+	var result struct {
+		Data struct {
+			Viewer User `json:"Viewer"`
+		} `json:"data"`
+		Errors []graphqlError `json:"errors"`
+	}
+
+	if err := c.query(viewerQuery).Execute(ctx, &result); err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to fetch AniList user")
+	}
+	if err := checkErrors(result.Errors); err != nil {
+		return nil, err
+	}
+
+	return &result.Data.Viewer, nil
+}
+
+func (c *Client) SearchMedia(ctx context.Context, title string, year int) ([]Media, error) {
+	var result struct {
+		Data struct {
+			Page struct {
+				Media []Media `json:"media"`
+			} `json:"Page"`
+		} `json:"data"`
+		Errors []graphqlError `json:"errors"`
+	}
+
+	qb := c.query(searchQuery).AddVariable("search", title)
+	if year > 0 {
+		qb.AddVariable("year", year)
+	}
+
+	if err := qb.Execute(ctx, &result); err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to search AniList")
+	}
+	if err := checkErrors(result.Errors); err != nil {
+		return nil, err
+	}
+
+	return result.Data.Page.Media, nil
+}
+
+func (c *Client) GetMedia(ctx context.Context, id int) (*Media, error) {
+	var result struct {
+		Data struct {
+			Media Media `json:"Media"`
+		} `json:"data"`
+		Errors []graphqlError `json:"errors"`
+	}
+
+	if err := c.query(mediaQuery).AddVariable("id", id).Execute(ctx, &result); err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to fetch AniList media")
+	}
+	if err := checkErrors(result.Errors); err != nil {
+		return nil, err
+	}
+
+	return &result.Data.Media, nil
+}
+
+func (c *Client) GetList(ctx context.Context, userID int) ([]ListEntry, error) {
+	var result struct {
+		Data struct {
+			MediaListCollection struct {
+				Lists []struct {
+					Entries []ListEntry `json:"entries"`
+				} `json:"lists"`
+			} `json:"MediaListCollection"`
+		} `json:"data"`
+		Errors []graphqlError `json:"errors"`
+	}
+
+	if err := c.query(listQuery).AddVariable("userId", userID).Execute(ctx, &result); err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to fetch AniList list")
+	}
+	if err := checkErrors(result.Errors); err != nil {
+		return nil, err
+	}
+
+	var entries []ListEntry
+	seen := make(map[int]bool)
+	for _, list := range result.Data.MediaListCollection.Lists {
+		for _, entry := range list.Entries {
+			if seen[entry.MediaID] {
+				continue
+			}
+			seen[entry.MediaID] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func (c *Client) SaveProgress(ctx context.Context, mediaID, progress int, status MediaListStatus) error {
+	var result struct {
+		Errors []graphqlError `json:"errors"`
+	}
+
+	qb := c.query(saveEntryMutation).
+		AddVariable("mediaId", mediaID).
+		AddVariable("progress", progress)
+	if status != "" {
+		qb.AddVariable("status", string(status))
+	}
+
+	if err := qb.Execute(ctx, &result); err != nil {
+		return errors.Wrap(err, errors.NetworkError, "failed to update AniList progress")
+	}
+	return checkErrors(result.Errors)
+}
+
+func AuthorizationURL(clientID string) string {
+	return fmt.Sprintf("%s?client_id=%s&response_type=token", AuthorizeURL, clientID)
+}
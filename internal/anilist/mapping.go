@@ -0,0 +1,77 @@
+package anilist
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/keircn/karu/internal/config"
+)
+
+type Mappings struct {
+	Shows map[string]int `json:"shows"`
+}
+
+func GetMappingsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	karuConfigDir := filepath.Join(configDir, "karu")
+	if err := os.MkdirAll(karuConfigDir, 0755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(karuConfigDir, "anilist_mappings.json"), nil
+}
+
+func LoadMappings() (*Mappings, error) {
+	mappings := &Mappings{Shows: make(map[string]int)}
+
+	mappingsPath, err := GetMappingsPath()
+	if err != nil {
+		return mappings, err
+	}
+
+	err = config.ReadFile(mappingsPath, func(data []byte) error {
+		*mappings = Mappings{}
+		return json.Unmarshal(data, mappings)
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return mappings, err
+	}
+	if mappings.Shows == nil {
+		mappings.Shows = make(map[string]int)
+	}
+
+	return mappings, nil
+}
+
+func SaveMappings(mappings *Mappings) error {
+	mappingsPath, err := GetMappingsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(mappings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return config.WriteFile(mappingsPath, data, 0644)
+}
+
+func (m *Mappings) Set(showID string, mediaID int) error {
+	m.Shows[showID] = mediaID
+	return SaveMappings(m)
+}
+
+func (m *Mappings) ShowFor(mediaID int) (string, bool) {
+	for showID, id := range m.Shows {
+		if id == mediaID {
+			return showID, true
+		}
+	}
+	return "", false
+}
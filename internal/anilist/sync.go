@@ -0,0 +1,275 @@
+package anilist
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/pkg/errors"
+)
+
+var ErrNotAuthenticated = errors.New(errors.ValidationError, "not logged in to AniList, run 'karu auth anilist' first")
+
+type Service struct {
+	client   *Client
+	auth     *config.AniListAuth
+	mappings *Mappings
+}
+
+func NewService(cfg *config.Config) (*Service, error) {
+	auth, err := config.LoadAuth()
+	if err != nil {
+		return nil, err
+	}
+	if auth.AniList == nil || auth.AniList.Token == "" {
+		return nil, ErrNotAuthenticated
+	}
+
+	mappings, err := LoadMappings()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		client:   NewClient(cfg.AniListEndpoint, auth.AniList.Token),
+		auth:     auth.AniList,
+		mappings: mappings,
+	}, nil
+}
+
+func (s *Service) Client() *Client {
+	return s.client
+}
+
+func (s *Service) Map(showID string, mediaID int) error {
+	return s.mappings.Set(showID, mediaID)
+}
+
+func (s *Service) ResolveMediaID(ctx context.Context, showID, title string) (int, error) {
+	if mediaID, exists := s.mappings.Shows[showID]; exists {
+		return mediaID, nil
+	}
+
+	if info, err := scraper.GetShowInfo(showID); err == nil && info.AniListID != "" {
+		if mediaID, err := strconv.Atoi(info.AniListID); err == nil && mediaID > 0 {
+			return mediaID, s.mappings.Set(showID, mediaID)
+		}
+	}
+
+	results, err := s.client.SearchMedia(ctx, title, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	var matches []Media
+	for _, media := range results {
+		if media.Title.Matches(title) {
+			matches = append(matches, media)
+		}
+	}
+	if len(matches) != 1 {
+		return 0, errors.New(errors.ValidationError,
+			fmt.Sprintf("no unique AniList match for %s, map it with 'karu sync anilist map'", title))
+	}
+
+	return matches[0].ID, s.mappings.Set(showID, matches[0].ID)
+}
+
+func (s *Service) PushProgress(ctx context.Context, showID, title string) (int, error) {
+	history, err := config.LoadHistory()
+	if err != nil {
+		return 0, err
+	}
+
+	entry, exists := history.Find(showID)
+	if !exists {
+		return 0, nil
+	}
+
+	progress := Progress(entry)
+	if progress == 0 {
+		return 0, nil
+	}
+
+	mediaID, err := s.ResolveMediaID(ctx, showID, title)
+	if err != nil {
+		return 0, err
+	}
+
+	status := StatusCurrent
+	if watchlist, err := config.LoadWatchlist(); err == nil {
+		if listed, exists := watchlist.Find(showID); exists {
+			status = FromWatchStatus(listed.Status)
+		}
+	}
+	if media, err := s.client.GetMedia(ctx, mediaID); err == nil && media.Episodes > 0 && progress >= media.Episodes {
+		status = StatusCompleted
+	} else if status == StatusPlanning || status == StatusCompleted {
+		status = StatusCurrent
+	}
+
+	return progress, s.client.SaveProgress(ctx, mediaID, progress, status)
+}
+
+type PullResult struct {
+	Added     []string
+	Updated   []string
+	Unmatched []string
+}
+
+func (s *Service) Pull(ctx context.Context) (*PullResult, error) {
+	entries, err := s.client.GetList(ctx, s.auth.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlist, err := config.LoadWatchlist()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PullResult{}
+	for _, entry := range entries {
+		title := entry.Media.Title.Preferred()
+
+		showID, showTitle, found := s.findShow(entry.Media)
+		if !found {
+			result.Unmatched = append(result.Unmatched, title)
+			continue
+		}
+
+		if _, listed := watchlist.Find(showID); listed {
+			result.Updated = append(result.Updated, showTitle)
+		} else {
+			if _, err := watchlist.Add(showID, showTitle, scraper.ShowURL(showID), ToWatchStatus(entry.Status)); err != nil {
+				return result, err
+			}
+			result.Added = append(result.Added, showTitle)
+		}
+
+		if err := watchlist.Update(showID, func(listed *config.WatchlistEntry) error {
+			listed.SetStatus(ToWatchStatus(entry.Status))
+			if entry.Score > 0 {
+				listed.Score = int(math.Round(entry.Score))
+			}
+			if entry.Notes != "" {
+				listed.Notes = entry.Notes
+			}
+			return nil
+		}); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Service) findShow(media Media) (string, string, bool) {
+	if showID, exists := s.mappings.ShowFor(media.ID); exists {
+		if info, err := scraper.GetShowInfo(showID); err == nil {
+			return showID, info.Name, true
+		}
+		return showID, media.Title.Preferred(), true
+	}
+
+	var fallback *scraper.Anime
+	for _, query := range []string{media.Title.Romaji, media.Title.English} {
+		if query == "" {
+			continue
+		}
+
+		animes, err := scraper.Search(query)
+		if err != nil {
+			continue
+		}
+
+		for i, anime := range animes {
+			showID := config.ShowIDFromURL(anime.URL)
+			if i < 5 {
+				if info, err := scraper.GetShowInfo(showID); err == nil && info.AniListID == strconv.Itoa(media.ID) {
+					s.mappings.Set(showID, media.ID)
+					return showID, anime.Title, true
+				}
+			}
+			if fallback == nil && media.Title.Matches(anime.Title) {
+				fallback = &animes[i]
+			}
+		}
+	}
+
+	if fallback != nil {
+		showID := config.ShowIDFromURL(fallback.URL)
+		s.mappings.Set(showID, media.ID)
+		return showID, fallback.Title, true
+	}
+
+	return "", "", false
+}
+
+func Progress(entry config.HistoryEntry) int {
+	progress := 0
+	for episode, record := range entry.Episodes {
+		if record == nil || !record.Completed {
+			continue
+		}
+		if n, err := strconv.Atoi(episode); err == nil && n > progress {
+			progress = n
+		}
+	}
+	return progress
+}
+
+func FromWatchStatus(status config.WatchStatus) MediaListStatus {
+	switch status {
+	case config.StatusPlanned:
+		return StatusPlanning
+	case config.StatusCompleted:
+		return StatusCompleted
+	case config.StatusOnHold:
+		return StatusPaused
+	case config.StatusDropped:
+		return StatusDropped
+	default:
+		return StatusCurrent
+	}
+}
+
+func ToWatchStatus(status MediaListStatus) config.WatchStatus {
+	switch status {
+	case StatusPlanning:
+		return config.StatusPlanned
+	case StatusCompleted:
+		return config.StatusCompleted
+	case StatusPaused:
+		return config.StatusOnHold
+	case StatusDropped:
+		return config.StatusDropped
+	default:
+		return config.StatusWatching
+	}
+}
+
+func SyncShow(showID, title string) error {
+	cfg, err := config.Load()
+	if err != nil || !cfg.AniListSync || showID == "" {
+		return nil
+	}
+
+	service, err := NewService(cfg)
+	if err == ErrNotAuthenticated {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	_, err = service.PushProgress(ctx, showID, title)
+	return err
+}
@@ -0,0 +1,131 @@
+package aniskip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/pkg/errors"
+	karuhttp "github.com/keircn/karu/pkg/http"
+)
+
+const DefaultBaseURL = "https://api.aniskip.com/v2"
+
+type Interval struct {
+	Type  string
+	Start float64
+	End   float64
+}
+
+func (i Interval) Label() string {
+	switch i.Type {
+	case "op", "mixed-op":
+		return "Opening"
+	case "ed", "mixed-ed":
+		return "Ending"
+	case "recap":
+		return "Recap"
+	default:
+		return i.Type
+	}
+}
+
+func (i Interval) Contains(position float64) bool {
+	return position >= i.Start && position < i.End
+}
+
+type skipTimesResponse struct {
+	Found   bool `json:"found"`
+	Results []struct {
+		Interval struct {
+			StartTime float64 `json:"startTime"`
+			EndTime   float64 `json:"endTime"`
+		} `json:"interval"`
+		SkipType string `json:"skipType"`
+	} `json:"results"`
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *karuhttp.Client
+}
+
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: karuhttp.NewClient(karuhttp.WithTimeout(5 * time.Second)),
+	}
+}
+
+func (c *Client) GetSkipTimes(ctx context.Context, malID, episode string) ([]Interval, error) {
+	if malID == "" {
+		return nil, errors.New(errors.ValidationError, "no MyAnimeList ID for show")
+	}
+
+	episodeNum, err := strconv.ParseFloat(episode, 64)
+	if err != nil || episodeNum != float64(int(episodeNum)) {
+		return nil, errors.New(errors.ValidationError, fmt.Sprintf("episode %s has no skip data", episode))
+	}
+
+	params := url.Values{}
+	for _, skipType := range []string{"op", "ed", "mixed-op", "mixed-ed", "recap"} {
+		params.Add("types[]", skipType)
+	}
+	params.Set("episodeLength", "0")
+
+	endpoint := fmt.Sprintf("%s/skip-times/%s/%d?%s", c.baseURL, url.PathEscape(malID), int(episodeNum), params.Encode())
+
+	resp, err := c.httpClient.Get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(errors.NetworkError, fmt.Sprintf("skip times request failed with status %d", resp.StatusCode))
+	}
+
+	var result skipTimesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to decode skip times")
+	}
+
+	if !result.Found {
+		return nil, nil
+	}
+
+	intervals := make([]Interval, 0, len(result.Results))
+	for _, r := range result.Results {
+		if r.Interval.EndTime <= r.Interval.StartTime {
+			continue
+		}
+		intervals = append(intervals, Interval{
+			Type:  r.SkipType,
+			Start: r.Interval.StartTime,
+			End:   r.Interval.EndTime,
+		})
+	}
+
+	return intervals, nil
+}
+
+func Find(intervals []Interval, position float64) (Interval, bool) {
+	for _, interval := range intervals {
+		if interval.Contains(position) {
+			return interval, true
+		}
+	}
+	return Interval{}, false
+}
@@ -0,0 +1,276 @@
+package cast
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/pkg/errors"
+)
+
+const (
+	StatePlaying        = "PLAYING"
+	StatePaused         = "PAUSED_PLAYBACK"
+	StateStopped        = "STOPPED"
+	StateTransitioning  = "TRANSITIONING"
+	StateNoMediaPresent = "NO_MEDIA_PRESENT"
+)
+
+type Renderer struct {
+	Name        string
+	Model       string
+	UDN         string
+	Location    string
+	ControlURL  string
+	ServiceType string
+	client      *http.Client
+}
+
+type PositionInfo struct {
+	Position time.Duration
+	Duration time.Duration
+}
+
+// Remaining is clamped at zero, as renderers can report a position past
+// the end of the track.
+func (p PositionInfo) Remaining() time.Duration {
+	return max(p.Duration-p.Position, 0)
+}
+
+type soapArg struct {
+	Name  string
+	Value string
+}
+
+type soapEnvelope struct {
+	Body struct {
+		Fault *struct {
+			String string `xml:"faultstring"`
+			Detail string `xml:",innerxml"`
+		} `xml:"Fault"`
+		Content []byte `xml:",innerxml"`
+	} `xml:"Body"`
+}
+
+func (r *Renderer) String() string {
+	if r.Model != "" {
+		return fmt.Sprintf("%s (%s)", r.Name, r.Model)
+	}
+	return r.Name
+}
+
+func (r *Renderer) LocalAddrFor() (string, error) {
+	u, err := url.Parse(r.ControlURL)
+	if err != nil {
+		return "", err
+	}
+
+	host := u.Host
+	if !strings.Contains(host, ":") {
+		host = net.JoinHostPort(host, "80")
+	}
+
+	conn, err := net.Dial("udp", host)
+	if err != nil {
+		return "", errors.Wrap(err, errors.NetworkError, "failed to find a route to the renderer")
+	}
+	defer conn.Close()
+
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return addr.IP.String(), nil
+}
+
+func (r *Renderer) SetAVTransportURI(ctx context.Context, mediaURL, title, mimeType string) error {
+	_, err := r.call(ctx, "SetAVTransportURI",
+		soapArg{"InstanceID", "0"},
+		soapArg{"CurrentURI", mediaURL},
+		soapArg{"CurrentURIMetaData", didlMetadata(mediaURL, title, mimeType)},
+	)
+	return err
+}
+
+func (r *Renderer) Play(ctx context.Context) error {
+	_, err := r.call(ctx, "Play", soapArg{"InstanceID", "0"}, soapArg{"Speed", "1"})
+	return err
+}
+
+func (r *Renderer) Pause(ctx context.Context) error {
+	_, err := r.call(ctx, "Pause", soapArg{"InstanceID", "0"})
+	return err
+}
+
+func (r *Renderer) Stop(ctx context.Context) error {
+	_, err := r.call(ctx, "Stop", soapArg{"InstanceID", "0"})
+	return err
+}
+
+func (r *Renderer) Seek(ctx context.Context, position time.Duration) error {
+	if position < 0 {
+		position = 0
+	}
+	_, err := r.call(ctx, "Seek",
+		soapArg{"InstanceID", "0"},
+		soapArg{"Unit", "REL_TIME"},
+		soapArg{"Target", FormatDuration(position)},
+	)
+	return err
+}
+
+func (r *Renderer) GetTransportState(ctx context.Context) (string, error) {
+	body, err := r.call(ctx, "GetTransportInfo", soapArg{"InstanceID", "0"})
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		State string `xml:"CurrentTransportState"`
+	}
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return "", errors.Wrap(err, errors.NetworkError, "failed to parse transport info")
+	}
+	return resp.State, nil
+}
+
+func (r *Renderer) GetPositionInfo(ctx context.Context) (PositionInfo, error) {
+	body, err := r.call(ctx, "GetPositionInfo", soapArg{"InstanceID", "0"})
+	if err != nil {
+		return PositionInfo{}, err
+	}
+
+	var resp struct {
+		TrackDuration string `xml:"TrackDuration"`
+		RelTime       string `xml:"RelTime"`
+	}
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return PositionInfo{}, errors.Wrap(err, errors.NetworkError, "failed to parse position info")
+	}
+
+	return PositionInfo{
+		Position: ParseDuration(resp.RelTime),
+		Duration: ParseDuration(resp.TrackDuration),
+	}, nil
+}
+
+func (r *Renderer) call(ctx context.Context, action string, args ...soapArg) ([]byte, error) {
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	body.WriteString(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(&body, `<u:%s xmlns:u="%s">`, action, r.ServiceType)
+	for _, arg := range args {
+		fmt.Fprintf(&body, "<%s>%s</%s>", arg.Name, html.EscapeString(arg.Value), arg.Name)
+	}
+	fmt.Fprintf(&body, `</u:%s></s:Body></s:Envelope>`, action)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.ControlURL, strings.NewReader(body.String()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", fmt.Sprintf(`"%s#%s"`, r.ServiceType, action))
+
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, errors.NetworkError, "%s request failed", action)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDescriptionBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope soapEnvelope
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return nil, errors.Wrapf(err, errors.NetworkError, "invalid %s response", action)
+	}
+
+	if envelope.Body.Fault != nil || resp.StatusCode != http.StatusOK {
+		detail := fmt.Sprintf("status %d", resp.StatusCode)
+		if envelope.Body.Fault != nil {
+			detail = strings.TrimSpace(envelope.Body.Fault.String)
+			if code := extractTag(envelope.Body.Fault.Detail, "errorDescription"); code != "" {
+				detail = code
+			}
+		}
+		return nil, errors.New(errors.PlayerError, fmt.Sprintf("renderer rejected %s: %s", action, detail))
+	}
+
+	return envelope.Body.Content, nil
+}
+
+func didlMetadata(mediaURL, title, mimeType string) string {
+	if mimeType == "" {
+		mimeType = "video/mp4"
+	}
+	return fmt.Sprintf(`<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/">`+
+		`<item id="0" parentID="-1" restricted="1"><dc:title>%s</dc:title><upnp:class>object.item.videoItem</upnp:class>`+
+		`<res protocolInfo="http-get:*:%s:*">%s</res></item></DIDL-Lite>`,
+		html.EscapeString(title), mimeType, html.EscapeString(mediaURL))
+}
+
+func extractTag(data, tag string) string {
+	start := strings.Index(data, "<"+tag+">")
+	if start == -1 {
+		return ""
+	}
+	start += len(tag) + 2
+	end := strings.Index(data[start:], "</"+tag+">")
+	if end == -1 {
+		return ""
+	}
+	return data[start : start+end]
+}
+
+func FormatDuration(d time.Duration) string {
+	total := int(d.Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, (total/60)%60, total%60)
+}
+
+func ParseDuration(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "NOT_IMPLEMENTED" {
+		return 0
+	}
+
+	if dot := strings.Index(value, "."); dot != -1 {
+		value = value[:dot]
+	}
+
+	parts := strings.Split(value, ":")
+	var seconds int
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func MimeTypeFor(mediaURL string) string {
+	lower := strings.ToLower(mediaURL)
+	switch {
+	case strings.Contains(lower, ".m3u8"):
+		return "application/vnd.apple.mpegurl"
+	case strings.Contains(lower, ".mkv"):
+		return "video/x-matroska"
+	case strings.Contains(lower, ".webm"):
+		return "video/webm"
+	default:
+		return "video/mp4"
+	}
+}
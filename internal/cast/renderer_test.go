@@ -0,0 +1,195 @@
+package cast
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const avTransport = "urn:schemas-upnp-org:service:AVTransport:1"
+
+type fakeCall struct {
+	action string
+	args   map[string]string
+}
+
+// fakeRenderer answers AVTransport SOAP requests the way a DLNA renderer
+// does and records what it was asked to do.
+type fakeRenderer struct {
+	calls     []fakeCall
+	responses map[string]string
+	faults    map[string]string
+}
+
+func (f *fakeRenderer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	soapAction := strings.Trim(r.Header.Get("SOAPAction"), `"`)
+	service, action, ok := strings.Cut(soapAction, "#")
+	if r.Method != http.MethodPost || !ok || service != avTransport {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	var envelope struct {
+		Body struct {
+			Action struct {
+				XMLName xml.Name
+				Args    []struct {
+					XMLName xml.Name
+					Value   string `xml:",chardata"`
+				} `xml:",any"`
+			} `xml:",any"`
+		} `xml:"Body"`
+	}
+	if err := xml.Unmarshal(body, &envelope); err != nil || envelope.Body.Action.XMLName.Local != action {
+		http.Error(w, "bad envelope", http.StatusBadRequest)
+		return
+	}
+
+	call := fakeCall{action: action, args: make(map[string]string)}
+	for _, arg := range envelope.Body.Action.Args {
+		call.args[arg.XMLName.Local] = arg.Value
+	}
+	f.calls = append(f.calls, call)
+
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	if fault, exists := f.faults[action]; exists {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault>`+
+			`<faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail><UPnPError xmlns="urn:schemas-upnp-org:control-1-0">`+
+			`<errorCode>701</errorCode><errorDescription>%s</errorDescription></UPnPError></detail></s:Fault></s:Body></s:Envelope>`, fault)
+		return
+	}
+	fmt.Fprintf(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`+
+		`<u:%sResponse xmlns:u="%s">%s</u:%sResponse></s:Body></s:Envelope>`, action, avTransport, f.responses[action], action)
+}
+
+func newFakeRenderer(t *testing.T) (*fakeRenderer, *Renderer) {
+	t.Helper()
+	fake := &fakeRenderer{responses: make(map[string]string), faults: make(map[string]string)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, &Renderer{
+		Name:        "Fake TV",
+		ControlURL:  server.URL + "/AVTransport/control",
+		ServiceType: avTransport,
+		client:      server.Client(),
+	}
+}
+
+func TestSetAVTransportURI(t *testing.T) {
+	fake, renderer := newFakeRenderer(t)
+
+	mediaURL := "http://192.168.1.2:8080/stream/abc/episode.m3u8?a=1&b=2"
+	if err := renderer.SetAVTransportURI(context.Background(), mediaURL, "Frieren <1>", MimeTypeFor(mediaURL)); err != nil {
+		t.Fatalf("SetAVTransportURI: %v", err)
+	}
+
+	if len(fake.calls) != 1 || fake.calls[0].action != "SetAVTransportURI" {
+		t.Fatalf("calls = %+v, want one SetAVTransportURI", fake.calls)
+	}
+	args := fake.calls[0].args
+	if args["InstanceID"] != "0" {
+		t.Errorf("InstanceID = %q, want 0", args["InstanceID"])
+	}
+	if args["CurrentURI"] != mediaURL {
+		t.Errorf("CurrentURI = %q, want %q", args["CurrentURI"], mediaURL)
+	}
+
+	var didl struct {
+		Item struct {
+			Title string `xml:"title"`
+			Res   struct {
+				ProtocolInfo string `xml:"protocolInfo,attr"`
+				URL          string `xml:",chardata"`
+			} `xml:"res"`
+		} `xml:"item"`
+	}
+	if err := xml.Unmarshal([]byte(args["CurrentURIMetaData"]), &didl); err != nil {
+		t.Fatalf("metadata is not valid DIDL-Lite: %v", err)
+	}
+	if didl.Item.Title != "Frieren <1>" || didl.Item.Res.URL != mediaURL {
+		t.Errorf("metadata title %q and URL %q do not match", didl.Item.Title, didl.Item.Res.URL)
+	}
+	if didl.Item.Res.ProtocolInfo != "http-get:*:application/vnd.apple.mpegurl:*" {
+		t.Errorf("protocolInfo = %q", didl.Item.Res.ProtocolInfo)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	fake, renderer := newFakeRenderer(t)
+
+	if err := renderer.Play(context.Background()); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	if len(fake.calls) != 1 || fake.calls[0].action != "Play" {
+		t.Fatalf("calls = %+v, want one Play", fake.calls)
+	}
+	if speed := fake.calls[0].args["Speed"]; speed != "1" {
+		t.Errorf("Speed = %q, want 1", speed)
+	}
+}
+
+func TestPlayFault(t *testing.T) {
+	fake, renderer := newFakeRenderer(t)
+	fake.faults["Play"] = "Transition not available"
+
+	err := renderer.Play(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Transition not available") {
+		t.Fatalf("Play error = %v, want the renderer's fault description", err)
+	}
+}
+
+func TestGetPositionInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  string
+		position  time.Duration
+		duration  time.Duration
+		remaining time.Duration
+	}{
+		{
+			name:      "playing",
+			response:  "<Track>1</Track><TrackDuration>0:24:00.000</TrackDuration><RelTime>0:01:05.500</RelTime>",
+			position:  65 * time.Second,
+			duration:  24 * time.Minute,
+			remaining: 24*time.Minute - 65*time.Second,
+		},
+		{
+			name:     "unknown duration",
+			response: "<TrackDuration>NOT_IMPLEMENTED</TrackDuration><RelTime>00:00:10</RelTime>",
+			position: 10 * time.Second,
+		},
+		{
+			name:     "past the end",
+			response: "<TrackDuration>00:20:00</TrackDuration><RelTime>00:20:03</RelTime>",
+			position: 20*time.Minute + 3*time.Second,
+			duration: 20 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, renderer := newFakeRenderer(t)
+			fake.responses["GetPositionInfo"] = tt.response
+
+			info, err := renderer.GetPositionInfo(context.Background())
+			if err != nil {
+				t.Fatalf("GetPositionInfo: %v", err)
+			}
+			if info.Position != tt.position || info.Duration != tt.duration {
+				t.Errorf("got %v / %v, want %v / %v", info.Position, info.Duration, tt.position, tt.duration)
+			}
+			if info.Remaining() != tt.remaining {
+				t.Errorf("Remaining() = %v, want %v", info.Remaining(), tt.remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package cast
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/keircn/karu/internal/hooks"
+	"github.com/keircn/karu/internal/player"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+const seekStep = 30 * time.Second
+
+type SessionOptions struct {
+	Renderer    *Renderer
+	ShowID      string
+	ShowTitle   string
+	Episodes    []string
+	Current     string
+	AutoNext    bool
+	GetVideoURL func(showID, episode string) (string, error)
+	MediaURL    func(videoURL string) string
+}
+
+type sessionModel struct {
+	opts       SessionOptions
+	index      int
+	state      string
+	position   PositionInfo
+	status     string
+	loading    bool
+	stopped    bool
+	sawPlaying bool
+	quitting   bool
+	videoURL   string
+}
+
+type castTickMsg struct{}
+
+type castStatusMsg struct {
+	state    string
+	position PositionInfo
+	err      error
+}
+
+type castLoadedMsg struct {
+	episode  string
+	videoURL string
+	err      error
+}
+
+type castActionMsg struct {
+	status string
+	err    error
+}
+
+func castTitleStyle() lipgloss.Style {
+	return ui.AccentStyle.Bold(true).MarginBottom(1)
+}
+
+func castStatusStyle() lipgloss.Style {
+	return ui.StatusStyle
+}
+
+func castHelpStyle() lipgloss.Style {
+	return ui.MutedStyle.MarginTop(1)
+}
+
+func Run(opts SessionOptions) error {
+	index := -1
+	for i, ep := range opts.Episodes {
+		if ep == opts.Current {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("current episode not found in episode list")
+	}
+
+	m := &sessionModel{
+		opts:  opts,
+		index: index,
+	}
+
+	_, err := tea.NewProgram(m).Run()
+
+	if m.quitting {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		opts.Renderer.Stop(ctx)
+	}
+	return err
+}
+
+func (m *sessionModel) Init() tea.Cmd {
+	return tea.Batch(m.loadEpisode(), castTick())
+}
+
+func castTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return castTickMsg{} })
+}
+
+func (m *sessionModel) episode() string {
+	return m.opts.Episodes[m.index]
+}
+
+func (m *sessionModel) loadEpisode() tea.Cmd {
+	m.loading = true
+	m.stopped = false
+	m.sawPlaying = false
+	m.status = fmt.Sprintf("Loading episode %s...", m.episode())
+
+	opts := m.opts
+	episode := m.episode()
+	return func() tea.Msg {
+		videoURL, err := opts.GetVideoURL(opts.ShowID, episode)
+		if err != nil {
+			return castLoadedMsg{episode: episode, err: err}
+		}
+
+		mediaURL := videoURL
+		if opts.MediaURL != nil {
+			mediaURL = opts.MediaURL(videoURL)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		title := fmt.Sprintf("%s - Episode %s", opts.ShowTitle, episode)
+		if err := opts.Renderer.SetAVTransportURI(ctx, mediaURL, title, MimeTypeFor(videoURL)); err != nil {
+			return castLoadedMsg{episode: episode, err: err}
+		}
+		if err := opts.Renderer.Play(ctx); err != nil {
+			return castLoadedMsg{episode: episode, err: err}
+		}
+		return castLoadedMsg{episode: episode, videoURL: videoURL}
+	}
+}
+
+func (m *sessionModel) poll() tea.Cmd {
+	renderer := m.opts.Renderer
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		state, err := renderer.GetTransportState(ctx)
+		if err != nil {
+			return castStatusMsg{err: err}
+		}
+		position, _ := renderer.GetPositionInfo(ctx)
+		return castStatusMsg{state: state, position: position}
+	}
+}
+
+func (m *sessionModel) action(status string, fn func(ctx context.Context) error) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return castActionMsg{status: status, err: fn(ctx)}
+	}
+}
+
+func (m *sessionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	renderer := m.opts.Renderer
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		keys := ui.Keys.Cast
+		switch {
+		case msg.String() == ui.ForceQuitKey || key.Matches(msg, keys.Quit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, keys.Pause):
+			if m.state == StatePlaying {
+				return m, m.action("Paused", renderer.Pause)
+			}
+			return m, m.action("Playing", renderer.Play)
+		case key.Matches(msg, keys.SeekBack):
+			target := m.position.Position - seekStep
+			return m, m.action("Seeked to "+FormatDuration(target), func(ctx context.Context) error {
+				return renderer.Seek(ctx, target)
+			})
+		case key.Matches(msg, keys.SeekForward):
+			target := m.position.Position + seekStep
+			return m, m.action("Seeked to "+FormatDuration(target), func(ctx context.Context) error {
+				return renderer.Seek(ctx, target)
+			})
+		case key.Matches(msg, keys.Stop):
+			m.stopped = true
+			return m, m.action("Stopped", renderer.Stop)
+		case key.Matches(msg, keys.Next):
+			if m.index < len(m.opts.Episodes)-1 {
+				m.index++
+				return m, m.loadEpisode()
+			}
+			m.status = "Already at last episode"
+		case key.Matches(msg, keys.Prev):
+			if m.index > 0 {
+				m.index--
+				return m, m.loadEpisode()
+			}
+			m.status = "Already at first episode"
+		}
+
+	case castTickMsg:
+		if m.loading {
+			return m, castTick()
+		}
+		return m, tea.Batch(m.poll(), castTick())
+
+	case castLoadedMsg:
+		if msg.episode != m.episode() {
+			return m, nil
+		}
+		m.loading = false
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Error: %v", msg.err)
+			payload := m.hookPayload()
+			payload.Error = msg.err.Error()
+			hooks.Fire(hooks.Error, payload)
+			return m, nil
+		}
+		m.videoURL = msg.videoURL
+		m.status = fmt.Sprintf("Casting episode %s to %s", msg.episode, renderer.Name)
+		hooks.Fire(hooks.PlayStart, m.hookPayload())
+
+	case castStatusMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Renderer unreachable: %v", msg.err)
+			return m, nil
+		}
+		m.state = msg.state
+		m.position = msg.position
+
+		if msg.state == StatePlaying {
+			m.sawPlaying = true
+		}
+		if msg.state == StateStopped && m.sawPlaying && !m.stopped {
+			m.sawPlaying = false
+			if m.opts.ShowID != "" {
+				player.RecordProgress(m.opts.ShowID, m.episode(), 0, 0, true)
+			}
+			hooks.Fire(hooks.EpisodeFinished, m.hookPayload())
+			if m.opts.AutoNext && m.index < len(m.opts.Episodes)-1 {
+				m.index++
+				return m, m.loadEpisode()
+			}
+			m.status = "Playback finished"
+		}
+
+	case castActionMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Error: %v", msg.err)
+		} else {
+			m.status = msg.status
+		}
+	}
+
+	return m, nil
+}
+
+func (m *sessionModel) View() string {
+	if m.quitting {
+		return castTitleStyle().Render("Casting stopped")
+	}
+
+	title := castTitleStyle().Render("Karu Cast: " + m.opts.Renderer.Name)
+
+	state := m.state
+	if state == "" {
+		state = "UNKNOWN"
+	}
+
+	episode := fmt.Sprintf("%s - Episode %s (%d/%d)",
+		m.opts.ShowTitle, m.episode(), m.index+1, len(m.opts.Episodes))
+
+	progress := fmt.Sprintf("%s / %s (%s left) • %s",
+		FormatDuration(m.position.Position), FormatDuration(m.position.Duration), FormatDuration(m.position.Remaining()), state)
+
+	autoNext := "disabled"
+	if m.opts.AutoNext {
+		autoNext = "enabled"
+	}
+
+	keys := ui.Keys.Cast
+	help := castHelpStyle().Render(ui.HelpLine(keys.Pause, keys.SeekBack, keys.SeekForward, keys.Next, keys.Prev, keys.Stop, keys.Quit))
+
+	return fmt.Sprintf("%s\n%s\n%s\n%s\nAuto-next: %s\n%s\n",
+		title, episode, progress, castStatusStyle().Render(m.status), autoNext, help)
+}
+
+func (m *sessionModel) hookPayload() hooks.Payload {
+	return hooks.Payload{
+		ShowID:  m.opts.ShowID,
+		Title:   m.opts.ShowTitle,
+		Episode: m.episode(),
+		URL:     m.videoURL,
+	}
+}
@@ -0,0 +1,208 @@
+package cast
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keircn/karu/pkg/errors"
+)
+
+const (
+	ssdpAddr            = "239.255.255.250:1900"
+	mediaRendererType   = "urn:schemas-upnp-org:device:MediaRenderer:1"
+	avTransportService  = "urn:schemas-upnp-org:service:AVTransport:1"
+	deviceFetchTimeout  = 5 * time.Second
+	maxDescriptionBytes = 1024 * 1024
+)
+
+type deviceDescription struct {
+	URLBase string `xml:"URLBase"`
+	Device  device `xml:"device"`
+}
+
+type device struct {
+	FriendlyName string    `xml:"friendlyName"`
+	Manufacturer string    `xml:"manufacturer"`
+	ModelName    string    `xml:"modelName"`
+	UDN          string    `xml:"UDN"`
+	Services     []service `xml:"serviceList>service"`
+	Devices      []device  `xml:"deviceList>device"`
+}
+
+type service struct {
+	ServiceType string `xml:"serviceType"`
+	ControlURL  string `xml:"controlURL"`
+}
+
+func (d device) findService(serviceType string) (service, bool) {
+	for _, s := range d.Services {
+		if strings.HasPrefix(s.ServiceType, strings.TrimSuffix(serviceType, "1")) {
+			return s, true
+		}
+	}
+	for _, child := range d.Devices {
+		if s, ok := child.findService(serviceType); ok {
+			return s, true
+		}
+	}
+	return service{}, false
+}
+
+func Discover(ctx context.Context, timeout time.Duration) ([]*Renderer, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to open discovery socket")
+	}
+	defer conn.Close()
+
+	target, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to resolve SSDP address")
+	}
+
+	mx := int(timeout.Seconds())
+	if mx < 1 {
+		mx = 1
+	}
+	request := strings.Join([]string{
+		"M-SEARCH * HTTP/1.1",
+		"HOST: " + ssdpAddr,
+		`MAN: "ssdp:discover"`,
+		fmt.Sprintf("MX: %d", mx),
+		"ST: " + mediaRendererType,
+		"", "",
+	}, "\r\n")
+
+	for i := 0; i < 2; i++ {
+		if _, err := conn.WriteTo([]byte(request), target); err != nil {
+			return nil, errors.Wrap(err, errors.NetworkError, "failed to send discovery request")
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetReadDeadline(deadline)
+
+	locations := make(map[string]bool)
+	buf := make([]byte, 8192)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+
+		if location := resp.Header.Get("Location"); location != "" {
+			locations[location] = true
+		}
+	}
+
+	var (
+		renderers []*Renderer
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		seen      = make(map[string]bool)
+	)
+
+	for location := range locations {
+		wg.Add(1)
+		go func(location string) {
+			defer wg.Done()
+			renderer, err := LoadRenderer(ctx, location)
+			if err != nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if renderer.UDN != "" && seen[renderer.UDN] {
+				return
+			}
+			seen[renderer.UDN] = true
+			renderers = append(renderers, renderer)
+		}(location)
+	}
+	wg.Wait()
+
+	return renderers, nil
+}
+
+func LoadRenderer(ctx context.Context, location string) (*Renderer, error) {
+	ctx, cancel := context.WithTimeout(ctx, deviceFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to fetch device description")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(errors.NetworkError, fmt.Sprintf("device description request failed with status %d", resp.StatusCode))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDescriptionBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	var desc deviceDescription
+	if err := xml.Unmarshal(body, &desc); err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to parse device description")
+	}
+
+	svc, ok := desc.Device.findService(avTransportService)
+	if !ok {
+		return nil, errors.New(errors.ValidationError, "device has no AVTransport service")
+	}
+
+	base := location
+	if desc.URLBase != "" {
+		base = desc.URLBase
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	controlURL, err := baseURL.Parse(svc.ControlURL)
+	if err != nil {
+		return nil, err
+	}
+
+	name := desc.Device.FriendlyName
+	if name == "" {
+		name = baseURL.Host
+	}
+
+	return &Renderer{
+		Name:        name,
+		Model:       strings.TrimSpace(desc.Device.Manufacturer + " " + desc.Device.ModelName),
+		UDN:         desc.Device.UDN,
+		Location:    location,
+		ControlURL:  controlURL.String(),
+		ServiceType: svc.ServiceType,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+type AniListAuth struct {
+	Token    string `json:"token"`
+	UserID   int    `json:"user_id"`
+	UserName string `json:"user_name"`
+}
+
+type Auth struct {
+	AniList *AniListAuth `json:"anilist,omitempty"`
+}
+
+func GetAuthPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	karuConfigDir := filepath.Join(configDir, "karu")
+	if err := os.MkdirAll(karuConfigDir, 0755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(karuConfigDir, "auth.json"), nil
+}
+
+func LoadAuth() (*Auth, error) {
+	auth := &Auth{}
+
+	authPath, err := GetAuthPath()
+	if err != nil {
+		return auth, err
+	}
+
+	err = ReadFile(authPath, func(data []byte) error {
+		*auth = Auth{}
+		return json.Unmarshal(data, auth)
+	})
+	if os.IsNotExist(err) {
+		return auth, nil
+	}
+
+	return auth, err
+}
+
+func SaveAuth(auth *Auth) error {
+	authPath, err := GetAuthPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(auth, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return WriteFile(authPath, data, 0600)
+}
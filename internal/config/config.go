@@ -1,12 +1,10 @@
 package config
 
 import (
-	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
 
 	"github.com/keircn/karu/pkg/errors"
@@ -14,16 +12,39 @@ import (
 )
 
 type Config struct {
-	Player            string `json:"player"`
-	PlayerArgs        string `json:"player_args"`
-	Quality           string `json:"quality"`
-	DownloadDir       string `json:"download_dir"`
-	AutoPlayNext      bool   `json:"auto_play_next"`
-	ShowSubtitles     bool   `json:"show_subtitles"`
-	CacheTTL          int    `json:"cache_ttl_minutes"`
-	RequestTimeout    int    `json:"request_timeout_seconds"`
-	ConcurrentWorkers int    `json:"concurrent_workers"`
-	PreloadEpisodes   int    `json:"preload_episodes"`
+	Player             string `json:"player"`
+	PlayerArgs         string `json:"player_args"`
+	Quality            string `json:"quality"`
+	DownloadDir        string `json:"download_dir"`
+	AutoPlayNext       bool   `json:"auto_play_next"`
+	ShowSubtitles      bool   `json:"show_subtitles"`
+	Theme              string `json:"theme"`
+	CacheTTL           int    `json:"cache_ttl_minutes"`
+	RequestTimeout     int    `json:"request_timeout_seconds"`
+	ConcurrentWorkers  int    `json:"concurrent_workers"`
+	PreloadEpisodes    int    `json:"preload_episodes"`
+	AutoSkip           bool   `json:"auto_skip"`
+	AniSkipURL         string `json:"aniskip_url"`
+	UseProxy           bool   `json:"use_proxy"`
+	ProxyPort          int    `json:"proxy_port"`
+	OnPlayStart        string `json:"on_play_start"`
+	OnEpisodeFinished  string `json:"on_episode_finished"`
+	OnDownloadComplete string `json:"on_download_complete"`
+	OnError            string `json:"on_error"`
+	HookTimeout        int    `json:"hook_timeout_seconds"`
+	AniListEndpoint    string `json:"anilist_endpoint"`
+	AniListClientID    string `json:"anilist_client_id"`
+	AniListSync        bool   `json:"anilist_sync"`
+	CheckUpdates       string `json:"check_updates"`
+	UpdateEndpoint     string `json:"update_endpoint"`
+	ShowImages         string `json:"show_images"`
+
+	path     string
+	origins  map[string]Origin
+	base     map[string]string
+	profiles map[string]map[string]any
+	keys     map[string]map[string][]string
+	invalid  map[string]any
 }
 
 var DefaultConfig = Config{
@@ -33,10 +54,21 @@ var DefaultConfig = Config{
 	DownloadDir:       getDefaultDownloadDir(),
 	AutoPlayNext:      false,
 	ShowSubtitles:     true,
+	Theme:             "default",
 	CacheTTL:          15,
 	RequestTimeout:    10,
 	ConcurrentWorkers: 4,
 	PreloadEpisodes:   5,
+	AutoSkip:          false,
+	AniSkipURL:        "https://api.aniskip.com/v2",
+	UseProxy:          false,
+	ProxyPort:         0,
+	HookTimeout:       10,
+	AniListEndpoint:   "https://graphql.anilist.co",
+	AniListSync:       true,
+	CheckUpdates:      "daily",
+	UpdateEndpoint:    "https://api.github.com/repos/keircn/karu/releases/latest",
+	ShowImages:        "auto",
 }
 
 func getDefaultPlayer() string {
@@ -163,52 +195,6 @@ func getDefaultDownloadDir() string {
 	return filepath.Join(homeDir, "Downloads", "karu")
 }
 
-func GetConfigPath() (string, error) {
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return "", errors.Wrap(err, errors.ConfigError, "failed to get user config directory")
-	}
-
-	karuConfigDir := filepath.Join(configDir, "karu")
-	if err := validation.EnsureDirectoryExists(karuConfigDir); err != nil {
-		return "", err
-	}
-
-	return filepath.Join(karuConfigDir, "config.json"), nil
-}
-
-func Load() (*Config, error) {
-	configPath, err := GetConfigPath()
-	if err != nil {
-		return &DefaultConfig, nil
-	}
-
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		config := DefaultConfig
-		if err := Save(&config); err != nil {
-			return &DefaultConfig, nil
-		}
-		return &config, nil
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return &DefaultConfig, errors.Wrap(err, errors.ConfigError, "failed to read config file")
-	}
-
-	config := DefaultConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return &DefaultConfig, errors.Wrap(err, errors.ConfigError, "failed to parse config file")
-	}
-
-	if err := config.validate(); err != nil {
-		return &DefaultConfig, err
-	}
-
-	config.applyDefaults()
-	return &config, nil
-}
-
 func (c *Config) validate() error {
 	if err := validation.ValidateNonEmptyString(c.Player, "player"); err != nil {
 		return err
@@ -238,6 +224,14 @@ func (c *Config) validate() error {
 		return errors.New(errors.ValidationError, "preload_episodes must be non-negative")
 	}
 
+	if c.ProxyPort < 0 || c.ProxyPort > 65535 {
+		return errors.New(errors.ValidationError, "proxy_port must be between 0 and 65535")
+	}
+
+	if c.HookTimeout < 0 {
+		return errors.New(errors.ValidationError, "hook_timeout_seconds must be non-negative")
+	}
+
 	return nil
 }
 
@@ -254,95 +248,15 @@ func (c *Config) applyDefaults() {
 	if c.PreloadEpisodes < 0 {
 		c.PreloadEpisodes = DefaultConfig.PreloadEpisodes
 	}
-}
-
-func Save(config *Config) error {
-	if err := config.validate(); err != nil {
-		return err
+	if c.AniSkipURL == "" {
+		c.AniSkipURL = DefaultConfig.AniSkipURL
 	}
-
-	configPath, err := GetConfigPath()
-	if err != nil {
-		return err
+	if c.HookTimeout <= 0 {
+		c.HookTimeout = DefaultConfig.HookTimeout
 	}
-
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return errors.Wrap(err, errors.ConfigError, "failed to marshal config")
+	if c.AniListEndpoint == "" {
+		c.AniListEndpoint = DefaultConfig.AniListEndpoint
 	}
-
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		return errors.Wrap(err, errors.ConfigError, "failed to write config file")
-	}
-
-	return nil
-}
-
-func (c *Config) Set(key, value string) error {
-	switch key {
-	case "player":
-		if err := validation.ValidateNonEmptyString(value, "player"); err != nil {
-			return err
-		}
-		c.Player = value
-
-	case "player_args":
-		c.PlayerArgs = value
-
-	case "quality":
-		if err := validation.ValidateNonEmptyString(value, "quality"); err != nil {
-			return err
-		}
-		c.Quality = value
-
-	case "download_dir":
-		if err := validation.ValidateNonEmptyString(value, "download_dir"); err != nil {
-			return err
-		}
-		c.DownloadDir = value
-
-	case "auto_play_next":
-		c.AutoPlayNext = value == "true"
-
-	case "show_subtitles":
-		c.ShowSubtitles = value == "true"
-
-	case "cache_ttl_minutes":
-		ttl, err := validation.ValidatePositiveInt(value, "cache_ttl_minutes")
-		if err != nil {
-			return err
-		}
-		c.CacheTTL = ttl
-
-	case "request_timeout_seconds":
-		timeout, err := validation.ValidatePositiveInt(value, "request_timeout_seconds")
-		if err != nil {
-			return err
-		}
-		c.RequestTimeout = timeout
-
-	case "concurrent_workers":
-		workers, err := validation.ValidatePositiveInt(value, "concurrent_workers")
-		if err != nil {
-			return err
-		}
-		c.ConcurrentWorkers = workers
-
-	case "preload_episodes":
-		episodes, err := strconv.Atoi(value)
-		if err != nil {
-			return errors.Wrapf(err, errors.ValidationError, "invalid preload_episodes: must be a number")
-		}
-		if episodes < 0 {
-			return errors.New(errors.ValidationError, "preload_episodes must be non-negative")
-		}
-		c.PreloadEpisodes = episodes
-
-	default:
-		return errors.New(errors.ValidationError, "unknown config key: "+key)
-	}
-
-	return Save(c)
 }
 
 func (c *Config) GetFallbackPlayers() []string {
@@ -366,43 +280,9 @@ func (c *Config) ValidatePlayer() error {
 	fallbacks := c.GetFallbackPlayers()
 	for _, player := range fallbacks {
 		if isPlayerAvailable(player) || fileExists(player) {
-			c.Player = player
-			return Save(c)
+			return c.Set("player", player)
 		}
 	}
 
 	return errors.New(errors.ValidationError, "no valid video player found. Please install mpv, vlc, or configure a custom player path")
 }
-
-func (c *Config) Get(key string) string {
-	switch key {
-	case "player":
-		return c.Player
-	case "player_args":
-		return c.PlayerArgs
-	case "quality":
-		return c.Quality
-	case "download_dir":
-		return c.DownloadDir
-	case "auto_play_next":
-		if c.AutoPlayNext {
-			return "true"
-		}
-		return "false"
-	case "show_subtitles":
-		if c.ShowSubtitles {
-			return "true"
-		}
-		return "false"
-	case "cache_ttl_minutes":
-		return strconv.Itoa(c.CacheTTL)
-	case "request_timeout_seconds":
-		return strconv.Itoa(c.RequestTimeout)
-	case "concurrent_workers":
-		return strconv.Itoa(c.ConcurrentWorkers)
-	case "preload_episodes":
-		return strconv.Itoa(c.PreloadEpisodes)
-	default:
-		return ""
-	}
-}
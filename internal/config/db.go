@@ -0,0 +1,271 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/keircn/karu/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	BucketHistory       = "history"
+	BucketHistoryRecent = "history_recent"
+	BucketWatchLog      = "watch_log"
+	BucketWatchlist     = "watchlist"
+	BucketDownloads     = "downloads"
+	BucketDownloadQueue = "download_queue"
+	BucketCache         = "cache"
+	BucketMeta          = "meta"
+
+	databaseTimeout = 10 * time.Second
+)
+
+var buckets = []string{
+	BucketHistory,
+	BucketHistoryRecent,
+	BucketWatchLog,
+	BucketWatchlist,
+	BucketDownloads,
+	BucketDownloadQueue,
+	BucketCache,
+	BucketMeta,
+}
+
+type Tx interface {
+	Get(bucket, key string, value any) (bool, error)
+	Put(bucket, key string, value any) error
+	Delete(bucket, key string) error
+	ForEach(bucket string, reverse bool, fn func(key string, data []byte) bool) error
+	ForEachPrefix(bucket, prefix string, fn func(key string, data []byte) bool) error
+}
+
+type Repository interface {
+	View(fn func(Tx) error) error
+	Update(fn func(Tx) error) error
+	Close() error
+}
+
+type boltRepository struct {
+	db *bolt.DB
+}
+
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func GetDatabasePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	karuConfigDir := filepath.Join(configDir, "karu")
+	if err := os.MkdirAll(karuConfigDir, 0755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(karuConfigDir, "karu.db"), nil
+}
+
+// preparedPath is the database that buckets and migrations were checked
+// for in this process. Later opens skip the checks, and reads open the
+// database read-only so they take no write lock and do no fsyncs.
+var (
+	preparedMu   sync.Mutex
+	preparedPath string
+)
+
+func OpenRepository() (Repository, error) {
+	return openRepository(false)
+}
+
+func openRepository(readOnly bool) (*boltRepository, error) {
+	path, err := GetDatabasePath()
+	if err != nil {
+		return nil, err
+	}
+
+	preparedMu.Lock()
+	prepared := preparedPath == path
+	preparedMu.Unlock()
+
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: databaseTimeout, ReadOnly: readOnly && prepared})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ConfigError, "failed to open database")
+	}
+
+	repo := &boltRepository{db: db}
+	if !prepared {
+		if err := repo.prepare(); err != nil {
+			db.Close()
+			return nil, errors.Wrap(err, errors.ConfigError, "failed to prepare database")
+		}
+		preparedMu.Lock()
+		preparedPath = path
+		preparedMu.Unlock()
+	}
+
+	return repo, nil
+}
+
+func (r *boltRepository) prepare() error {
+	if r.isPrepared() {
+		return nil
+	}
+
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = migrateJSONStores(r)
+	}
+	if err == nil {
+		err = backfillWatchLog(r)
+	}
+	return err
+}
+
+func (r *boltRepository) isPrepared() bool {
+	prepared := false
+	r.db.View(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if tx.Bucket([]byte(name)) == nil {
+				return nil
+			}
+		}
+		var migrated, backfilled bool
+		meta := &boltTx{tx: tx}
+		meta.Get(BucketMeta, metaJSONMigrated, &migrated)
+		meta.Get(BucketMeta, metaWatchLogBackfilled, &backfilled)
+		prepared = migrated && backfilled
+		return nil
+	})
+	return prepared
+}
+
+func viewRepository(fn func(Tx) error) error {
+	repo, err := openRepository(true)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	return repo.View(fn)
+}
+
+func updateRepository(fn func(Tx) error) error {
+	repo, err := openRepository(false)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	return repo.Update(fn)
+}
+
+func (r *boltRepository) View(fn func(Tx) error) error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+func (r *boltRepository) Update(fn func(Tx) error) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+func (r *boltRepository) Close() error {
+	return r.db.Close()
+}
+
+func (t *boltTx) bucket(name string) (*bolt.Bucket, error) {
+	bucket := t.tx.Bucket([]byte(name))
+	if bucket == nil {
+		return nil, errors.New(errors.ConfigError, "unknown database bucket '"+name+"'")
+	}
+	return bucket, nil
+}
+
+func (t *boltTx) Get(bucket, key string, value any) (bool, error) {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return false, err
+	}
+
+	data := b.Get([]byte(key))
+	if data == nil {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+func (t *boltTx) Put(bucket, key string, value any) error {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), data)
+}
+
+func (t *boltTx) Delete(bucket, key string) error {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete([]byte(key))
+}
+
+func (t *boltTx) ForEach(bucket string, reverse bool, fn func(key string, data []byte) bool) error {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return err
+	}
+
+	cursor := b.Cursor()
+	if reverse {
+		for key, data := cursor.Last(); key != nil; key, data = cursor.Prev() {
+			if !fn(string(key), data) {
+				break
+			}
+		}
+		return nil
+	}
+
+	for key, data := cursor.First(); key != nil; key, data = cursor.Next() {
+		if !fn(string(key), data) {
+			break
+		}
+	}
+	return nil
+}
+
+func (t *boltTx) ForEachPrefix(bucket, prefix string, fn func(key string, data []byte) bool) error {
+	b, err := t.bucket(bucket)
+	if err != nil {
+		return err
+	}
+
+	cursor := b.Cursor()
+	for key, data := cursor.Seek([]byte(prefix)); key != nil && bytes.HasPrefix(key, []byte(prefix)); key, data = cursor.Next() {
+		if !fn(string(key), data) {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const (
+	metaJSONMigrated       = "json_migrated"
+	metaWatchLogBackfilled = "watch_log_backfilled"
+)
+
+func migrateJSONStores(repo *boltRepository) error {
+	var migrated []string
+
+	err := repo.Update(func(tx Tx) error {
+		var done bool
+		if _, err := tx.Get(BucketMeta, metaJSONMigrated, &done); err != nil || done {
+			return err
+		}
+
+		if historyPath, err := GetHistoryPath(); err == nil {
+			var history *History
+			err := ReadFile(historyPath, func(data []byte) error {
+				parsed, _, err := ParseHistory(data)
+				history = parsed
+				return err
+			})
+			if err == nil {
+				if err := writeHistory(tx, history); err != nil {
+					return err
+				}
+				migrated = append(migrated, historyPath)
+			} else if !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "Warning: could not import history.json: %v\n", err)
+			}
+		}
+
+		if watchlistPath, err := GetWatchlistPath(); err == nil {
+			var watchlist Watchlist
+			err := ReadFile(watchlistPath, func(data []byte) error {
+				watchlist = Watchlist{}
+				return json.Unmarshal(data, &watchlist)
+			})
+			if err == nil {
+				if err := writeWatchlist(tx, &watchlist); err != nil {
+					return err
+				}
+				migrated = append(migrated, watchlistPath)
+			} else if !os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "Warning: could not import watchlist.json: %v\n", err)
+			}
+		}
+
+		return tx.Put(BucketMeta, metaJSONMigrated, true)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range migrated {
+		os.Rename(path, path+".migrated")
+	}
+	return nil
+}
+
+func backfillWatchLog(repo *boltRepository) error {
+	return repo.Update(func(tx Tx) error {
+		var done bool
+		if _, err := tx.Get(BucketMeta, metaWatchLogBackfilled, &done); err != nil || done {
+			return err
+		}
+
+		var history History
+		if err := readHistory(tx, &history); err != nil {
+			return err
+		}
+
+		var events []WatchEvent
+		for _, entry := range history.Entries {
+			for episode, record := range entry.Episodes {
+				if record == nil || !record.Completed {
+					continue
+				}
+				events = append(events, WatchEvent{
+					ID:        entry.ID,
+					ShowID:    entry.ShowID,
+					Title:     entry.Title,
+					Episode:   episode,
+					WatchedAt: record.LastWatched,
+					Duration:  record.Duration,
+				})
+			}
+		}
+
+		if err := writeWatchEvents(tx, events); err != nil {
+			return err
+		}
+		return tx.Put(BucketMeta, metaWatchLogBackfilled, true)
+	})
+}
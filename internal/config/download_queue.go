@@ -0,0 +1,75 @@
+package config
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+type QueuedDownload struct {
+	ShowID   string    `json:"show_id"`
+	Title    string    `json:"title"`
+	Episode  string    `json:"episode"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+func queuedDownloadKey(showID, episode string) string {
+	return showID + ":" + episode
+}
+
+// Episodes already queued keep their place.
+func QueueDownloads(showID, title string, episodes []string) error {
+	now := time.Now()
+	return updateRepository(func(tx Tx) error {
+		for _, episode := range episodes {
+			key := queuedDownloadKey(showID, episode)
+			var existing QueuedDownload
+			found, err := tx.Get(BucketDownloadQueue, key, &existing)
+			if err != nil {
+				return err
+			}
+			if found {
+				continue
+			}
+			if err := tx.Put(BucketDownloadQueue, key, QueuedDownload{
+				ShowID:   showID,
+				Title:    title,
+				Episode:  episode,
+				QueuedAt: now,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func RemoveQueuedDownload(showID, episode string) error {
+	return updateRepository(func(tx Tx) error {
+		return tx.Delete(BucketDownloadQueue, queuedDownloadKey(showID, episode))
+	})
+}
+
+func GetDownloadQueue() ([]QueuedDownload, error) {
+	var queue []QueuedDownload
+	err := viewRepository(func(tx Tx) error {
+		var decodeErr error
+		err := tx.ForEach(BucketDownloadQueue, false, func(key string, data []byte) bool {
+			var queued QueuedDownload
+			if decodeErr = json.Unmarshal(data, &queued); decodeErr != nil {
+				return false
+			}
+			queue = append(queue, queued)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		return decodeErr
+	})
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].QueuedAt.Before(queue[j].QueuedAt)
+	})
+	return queue, err
+}
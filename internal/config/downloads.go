@@ -0,0 +1,47 @@
+package config
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DownloadRecord struct {
+	ShowID       string    `json:"show_id"`
+	Title        string    `json:"title"`
+	Episode      string    `json:"episode"`
+	Path         string    `json:"path"`
+	Size         int64     `json:"size"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
+
+func RecordDownload(record DownloadRecord) error {
+	return updateRepository(func(tx Tx) error {
+		return tx.Put(BucketDownloads, record.Path, record)
+	})
+}
+
+func RemoveDownload(path string) error {
+	return updateRepository(func(tx Tx) error {
+		return tx.Delete(BucketDownloads, path)
+	})
+}
+
+func GetDownloads() ([]DownloadRecord, error) {
+	var records []DownloadRecord
+	err := viewRepository(func(tx Tx) error {
+		var decodeErr error
+		err := tx.ForEach(BucketDownloads, false, func(key string, data []byte) bool {
+			var record DownloadRecord
+			if decodeErr = json.Unmarshal(data, &record); decodeErr != nil {
+				return false
+			}
+			records = append(records, record)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		return decodeErr
+	})
+	return records, err
+}
@@ -5,27 +5,86 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
+const (
+	HistoryVersion  = 3
+	DefaultProvider = "allanime"
+
+	CompletionThreshold = 0.9
+)
+
+type EpisodeRecord struct {
+	FirstWatched time.Time `json:"first_watched"`
+	LastWatched  time.Time `json:"last_watched"`
+	Position     float64   `json:"position"`
+	Duration     float64   `json:"duration"`
+	Completed    bool      `json:"completed"`
+	WatchCount   int       `json:"watch_count"`
+}
+
+func (r EpisodeRecord) Progress() float64 {
+	if r.Completed {
+		return 1
+	}
+	if r.Duration <= 0 {
+		return 0
+	}
+	return r.Position / r.Duration
+}
+
+func (r EpisodeRecord) InProgress() bool {
+	return !r.Completed && r.Position > 0
+}
+
 type HistoryEntry struct {
-	ID          string    `json:"id"`
-	Query       string    `json:"query"`
-	Title       string    `json:"title"`
-	URL         string    `json:"url"`
-	LastWatched int       `json:"last_watched"`
-	TotalEps    int       `json:"total_episodes"`
-	Timestamp   time.Time `json:"timestamp"`
-	AccessCount int       `json:"access_count"`
+	ID          string                    `json:"id"`
+	Provider    string                    `json:"provider"`
+	ShowID      string                    `json:"show_id"`
+	Query       string                    `json:"query"`
+	Title       string                    `json:"title"`
+	URL         string                    `json:"url"`
+	Episodes    map[string]*EpisodeRecord `json:"episodes,omitempty"`
+	LastEpisode string                    `json:"last_episode,omitempty"`
+	TotalEps    int                       `json:"total_episodes"`
+	Timestamp   time.Time                 `json:"timestamp"`
+	AccessCount int                       `json:"access_count"`
+
+	LegacyLastWatched int `json:"last_watched,omitempty"`
+}
+
+func (e HistoryEntry) Episode(episode string) (EpisodeRecord, bool) {
+	if record, exists := e.Episodes[episode]; exists && record != nil {
+		return *record, true
+	}
+	return EpisodeRecord{}, false
+}
+
+func (e HistoryEntry) WatchedCount() int {
+	count := 0
+	for _, record := range e.Episodes {
+		if record != nil && record.Completed {
+			count++
+		}
+	}
+	return count
 }
 
 type History struct {
+	Version    int            `json:"version"`
 	Entries    []HistoryEntry `json:"entries"`
 	MaxEntries int            `json:"max_entries"`
+
+	base   map[string]HistoryEntry
+	stored bool
+	events []WatchEvent
 }
 
 var DefaultHistory = History{
+	Version:    HistoryVersion,
 	Entries:    []HistoryEntry{},
 	MaxEntries: 50,
 }
@@ -45,70 +104,153 @@ func GetHistoryPath() (string, error) {
 }
 
 func LoadHistory() (*History, error) {
-	historyPath, err := GetHistoryPath()
-	if err != nil {
-		return &DefaultHistory, nil
-	}
-
-	if _, err := os.Stat(historyPath); os.IsNotExist(err) {
-		history := DefaultHistory
-		if err := SaveHistory(&history); err != nil {
-			return &DefaultHistory, nil
-		}
-		return &history, nil
-	}
-
-	data, err := os.ReadFile(historyPath)
+	history := DefaultHistory
+	err := viewRepository(func(tx Tx) error {
+		return readHistory(tx, &history)
+	})
 	if err != nil {
 		return &DefaultHistory, err
 	}
 
+	history.stored = true
+	history.snapshot()
+	return &history, nil
+}
+
+func ParseHistory(data []byte) (*History, bool, error) {
 	history := DefaultHistory
+	history.Version = 0
 	if err := json.Unmarshal(data, &history); err != nil {
-		return &DefaultHistory, err
+		return nil, false, err
 	}
 
 	if history.MaxEntries <= 0 {
 		history.MaxEntries = DefaultHistory.MaxEntries
 	}
 
-	return &history, nil
+	if history.Version < HistoryVersion {
+		history.migrate()
+		return &history, true, nil
+	}
+
+	return &history, false, nil
+}
+
+func (h *History) migrate() {
+	if h.Version < 2 {
+		migrated := make([]HistoryEntry, 0, len(h.Entries))
+		index := make(map[string]int)
+
+		for _, entry := range h.Entries {
+			if entry.ShowID == "" {
+				entry.ShowID = ShowIDFromURL(entry.URL)
+			}
+			if entry.ShowID != "" {
+				entry.Provider = DefaultProvider
+				entry.ID = MakeHistoryID(entry.Provider, entry.ShowID)
+			}
+
+			if i, exists := index[entry.ID]; exists {
+				existing := &migrated[i]
+				existing.AccessCount += entry.AccessCount
+				if entry.LegacyLastWatched > existing.LegacyLastWatched {
+					existing.LegacyLastWatched = entry.LegacyLastWatched
+				}
+				if entry.Timestamp.After(existing.Timestamp) {
+					existing.Title = entry.Title
+					existing.Query = entry.Query
+					existing.TotalEps = entry.TotalEps
+					existing.Timestamp = entry.Timestamp
+				}
+				continue
+			}
+
+			index[entry.ID] = len(migrated)
+			migrated = append(migrated, entry)
+		}
+
+		h.Entries = migrated
+	}
+
+	if h.Version < 3 {
+		for i := range h.Entries {
+			entry := &h.Entries[i]
+			if entry.Episodes == nil {
+				entry.Episodes = make(map[string]*EpisodeRecord)
+			}
+			for n := 1; n <= entry.LegacyLastWatched; n++ {
+				episode := strconv.Itoa(n)
+				if _, exists := entry.Episodes[episode]; exists {
+					continue
+				}
+				entry.Episodes[episode] = &EpisodeRecord{
+					FirstWatched: entry.Timestamp,
+					LastWatched:  entry.Timestamp,
+					Completed:    true,
+					WatchCount:   1,
+				}
+			}
+			if entry.LegacyLastWatched > 0 && entry.LastEpisode == "" {
+				entry.LastEpisode = strconv.Itoa(entry.LegacyLastWatched)
+			}
+			entry.LegacyLastWatched = 0
+		}
+	}
+
+	h.Version = HistoryVersion
 }
 
 func SaveHistory(history *History) error {
-	historyPath, err := GetHistoryPath()
-	if err != nil {
+	if err := updateRepository(func(tx Tx) error {
+		return writeHistory(tx, history)
+	}); err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(history, "", "  ")
-	if err != nil {
-		return err
+	history.stored = true
+	history.events = nil
+	history.snapshot()
+	return nil
+}
+
+func (h *History) find(showID string) int {
+	id := MakeHistoryID(DefaultProvider, showID)
+	for i, entry := range h.Entries {
+		if entry.ID == id {
+			return i
+		}
 	}
+	return -1
+}
 
-	return os.WriteFile(historyPath, data, 0644)
+func (h *History) Find(showID string) (HistoryEntry, bool) {
+	if i := h.find(showID); i != -1 {
+		return h.Entries[i], true
+	}
+	return HistoryEntry{}, false
 }
 
-func (h *History) AddEntry(query, title, url string, totalEps int) error {
+func (h *History) AddEntry(showID, query, title, url string, totalEps int) error {
 	now := time.Now()
 
-	for i, entry := range h.Entries {
-		if entry.Title == title {
-			h.Entries[i].Query = query
-			h.Entries[i].URL = url
-			h.Entries[i].TotalEps = totalEps
-			h.Entries[i].Timestamp = now
-			h.Entries[i].AccessCount++
-			return SaveHistory(h)
-		}
+	if i := h.find(showID); i != -1 {
+		h.Entries[i].Query = query
+		h.Entries[i].Title = title
+		h.Entries[i].URL = url
+		h.Entries[i].TotalEps = totalEps
+		h.Entries[i].Timestamp = now
+		h.Entries[i].AccessCount++
+		return SaveHistory(h)
 	}
 
 	newEntry := HistoryEntry{
-		ID:          generateID(title),
+		ID:          MakeHistoryID(DefaultProvider, showID),
+		Provider:    DefaultProvider,
+		ShowID:      showID,
 		Query:       query,
 		Title:       title,
 		URL:         url,
-		LastWatched: 1,
+		Episodes:    make(map[string]*EpisodeRecord),
 		TotalEps:    totalEps,
 		Timestamp:   now,
 		AccessCount: 1,
@@ -126,65 +268,169 @@ func (h *History) AddEntry(query, title, url string, totalEps int) error {
 	return SaveHistory(h)
 }
 
-func (h *History) UpdateProgress(title string, episode int) error {
-	for i, entry := range h.Entries {
-		if entry.Title == title {
-			if episode > h.Entries[i].LastWatched {
-				h.Entries[i].LastWatched = episode
-			}
-			h.Entries[i].Timestamp = time.Now()
-			h.Entries[i].AccessCount++
-			return SaveHistory(h)
-		}
+func (h *History) Put(entry HistoryEntry) {
+	if entry.Provider == "" {
+		entry.Provider = DefaultProvider
+	}
+	entry.ID = MakeHistoryID(entry.Provider, entry.ShowID)
+	if entry.Episodes == nil {
+		entry.Episodes = make(map[string]*EpisodeRecord)
+	}
+
+	if i := h.find(entry.ShowID); i != -1 {
+		h.Entries[i] = entry
+		return
+	}
+
+	h.Entries = append(h.Entries, entry)
+	if len(h.Entries) > h.MaxEntries {
+		h.MaxEntries = len(h.Entries)
+	}
+}
+
+func (h *History) Touch(showID string) error {
+	if i := h.find(showID); i != -1 {
+		h.Entries[i].Timestamp = time.Now()
+		h.Entries[i].AccessCount++
+		return SaveHistory(h)
 	}
 	return nil
 }
 
-func (h *History) GetProgress(title string) (int, bool) {
-	for _, entry := range h.Entries {
-		if entry.Title == title {
-			return entry.LastWatched, true
-		}
+func (h *History) RecordWatch(showID, episode string, position, duration float64, completed bool) error {
+	i := h.find(showID)
+	if i == -1 {
+		return nil
 	}
-	return 0, false
+
+	entry := &h.Entries[i]
+	if entry.Episodes == nil {
+		entry.Episodes = make(map[string]*EpisodeRecord)
+	}
+
+	now := time.Now()
+	record, exists := entry.Episodes[episode]
+	if !exists || record == nil {
+		record = &EpisodeRecord{FirstWatched: now}
+		entry.Episodes[episode] = record
+	}
+
+	if duration > 0 && position >= duration*CompletionThreshold {
+		completed = true
+	}
+
+	record.LastWatched = now
+	if duration > 0 {
+		record.Duration = duration
+	}
+	if completed {
+		record.Completed = true
+		record.WatchCount++
+		record.Position = 0
+		h.events = append(h.events, WatchEvent{
+			ID:        entry.ID,
+			ShowID:    entry.ShowID,
+			Title:     entry.Title,
+			Episode:   episode,
+			WatchedAt: now,
+			Duration:  record.Duration,
+		})
+	} else {
+		record.Position = position
+	}
+
+	entry.LastEpisode = episode
+	entry.Timestamp = now
+	entry.AccessCount++
+	return SaveHistory(h)
 }
 
-func (h *History) IsWatched(title string, episode int) bool {
-	for _, entry := range h.Entries {
-		if entry.Title == title {
-			return episode <= entry.LastWatched
+func (h *History) SetWatched(showID string, episodes []string, watched bool) error {
+	i := h.find(showID)
+	if i == -1 {
+		return nil
+	}
+
+	entry := &h.Entries[i]
+	if entry.Episodes == nil {
+		entry.Episodes = make(map[string]*EpisodeRecord)
+	}
+
+	now := time.Now()
+	for _, episode := range episodes {
+		if !watched {
+			delete(entry.Episodes, episode)
+			continue
+		}
+
+		record, exists := entry.Episodes[episode]
+		if !exists || record == nil {
+			record = &EpisodeRecord{FirstWatched: now}
+			entry.Episodes[episode] = record
+		}
+		record.LastWatched = now
+		record.Position = 0
+		record.Completed = true
+		if record.WatchCount == 0 {
+			record.WatchCount = 1
 		}
 	}
-	return false
+
+	return SaveHistory(h)
 }
 
-func (h *History) GetNextEpisode(title string) int {
-	for _, entry := range h.Entries {
-		if entry.Title == title {
-			if entry.LastWatched < entry.TotalEps {
-				return entry.LastWatched + 1
-			}
-			return entry.LastWatched
-		}
+func (h *History) GetEpisode(showID, episode string) (EpisodeRecord, bool) {
+	if i := h.find(showID); i != -1 {
+		return h.Entries[i].Episode(episode)
 	}
-	return 1
+	return EpisodeRecord{}, false
 }
 
-func (h *History) GetCompletionPercentage(title string) float64 {
-	for _, entry := range h.Entries {
-		if entry.Title == title && entry.TotalEps > 0 {
-			return float64(entry.LastWatched) / float64(entry.TotalEps) * 100
+func (h *History) IsWatched(showID, episode string) bool {
+	record, exists := h.GetEpisode(showID, episode)
+	return exists && record.Completed
+}
+
+func (h *History) GetWatchedCount(showID string) (int, bool) {
+	if i := h.find(showID); i != -1 {
+		return h.Entries[i].WatchedCount(), true
+	}
+	return 0, false
+}
+
+func (h *History) GetResumeEpisode(showID string, episodes []string) (string, bool) {
+	i := h.find(showID)
+	if i == -1 || h.Entries[i].LastEpisode == "" {
+		return "", false
+	}
+
+	entry := h.Entries[i]
+	for j, episode := range episodes {
+		if episode != entry.LastEpisode {
+			continue
+		}
+		if record, exists := entry.Episode(episode); exists && record.InProgress() {
+			return episode, true
 		}
+		if j+1 < len(episodes) {
+			return episodes[j+1], true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+func (h *History) GetCompletionPercentage(showID string) float64 {
+	if i := h.find(showID); i != -1 && h.Entries[i].TotalEps > 0 {
+		return float64(h.Entries[i].WatchedCount()) / float64(h.Entries[i].TotalEps) * 100
 	}
 	return 0
 }
 
-func (h *History) RemoveEntry(title string) error {
-	for i, entry := range h.Entries {
-		if entry.Title == title {
-			h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
-			return SaveHistory(h)
-		}
+func (h *History) RemoveEntry(showID string) error {
+	if i := h.find(showID); i != -1 {
+		h.Entries = append(h.Entries[:i], h.Entries[i+1:]...)
+		return SaveHistory(h)
 	}
 	return nil
 }
@@ -195,6 +441,12 @@ func (h *History) Clear() error {
 }
 
 func (h *History) GetRecent(limit int) []HistoryEntry {
+	if h.stored {
+		if entries, err := queryRecent(limit); err == nil {
+			return entries
+		}
+	}
+
 	entries := make([]HistoryEntry, len(h.Entries))
 	copy(entries, h.Entries)
 
@@ -210,6 +462,12 @@ func (h *History) GetRecent(limit int) []HistoryEntry {
 }
 
 func (h *History) GetMostWatched(limit int) []HistoryEntry {
+	if h.stored {
+		if entries, err := queryMostWatched(limit); err == nil {
+			return entries
+		}
+	}
+
 	entries := make([]HistoryEntry, len(h.Entries))
 	copy(entries, h.Entries)
 
@@ -228,6 +486,12 @@ func (h *History) GetMostWatched(limit int) []HistoryEntry {
 }
 
 func (h *History) Search(query string) []HistoryEntry {
+	if h.stored {
+		if matches, err := querySearch(query); err == nil {
+			return matches
+		}
+	}
+
 	var matches []HistoryEntry
 	queryLower := strings.ToLower(query)
 
@@ -245,6 +509,14 @@ func (h *History) Search(query string) []HistoryEntry {
 	return matches
 }
 
-func generateID(title string) string {
-	return strings.ReplaceAll(strings.ToLower(title), " ", "-")
+func MakeHistoryID(provider, showID string) string {
+	return provider + ":" + showID
+}
+
+func ShowIDFromURL(url string) string {
+	url = strings.TrimRight(url, "/")
+	if url == "" {
+		return ""
+	}
+	return url[strings.LastIndex(url, "/")+1:]
 }
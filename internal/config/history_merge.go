@@ -0,0 +1,158 @@
+package config
+
+import (
+	"sort"
+)
+
+func (h *History) snapshot() {
+	h.base = make(map[string]HistoryEntry, len(h.Entries))
+	for _, entry := range h.Entries {
+		h.base[entry.ID] = copyEntry(entry)
+	}
+}
+
+func (h *History) merge(disk *History) {
+	diskEntries := make(map[string]HistoryEntry, len(disk.Entries))
+	for _, entry := range disk.Entries {
+		diskEntries[entry.ID] = entry
+	}
+
+	merged := make([]HistoryEntry, 0, len(h.Entries)+len(disk.Entries))
+	seen := make(map[string]bool, len(h.Entries))
+	for _, local := range h.Entries {
+		seen[local.ID] = true
+		base, inBase := h.base[local.ID]
+		onDisk, inDisk := diskEntries[local.ID]
+
+		switch {
+		case !inDisk && inBase && sameEntry(local, base):
+			continue
+		case !inDisk:
+			merged = append(merged, local)
+		case inBase && sameEntry(local, base):
+			merged = append(merged, onDisk)
+		case inBase:
+			merged = append(merged, mergeEntry(local, &base, onDisk))
+		default:
+			merged = append(merged, mergeEntry(local, nil, onDisk))
+		}
+	}
+
+	for _, entry := range disk.Entries {
+		if _, inBase := h.base[entry.ID]; seen[entry.ID] || inBase {
+			continue
+		}
+		merged = append(merged, entry)
+	}
+
+	if len(merged) > h.MaxEntries {
+		sort.Slice(merged, func(i, j int) bool {
+			return merged[i].Timestamp.After(merged[j].Timestamp)
+		})
+		merged = merged[:h.MaxEntries]
+	}
+
+	h.Entries = merged
+}
+
+func mergeEntry(local HistoryEntry, base *HistoryEntry, disk HistoryEntry) HistoryEntry {
+	merged := local
+	if disk.Timestamp.After(local.Timestamp) {
+		merged = disk
+	}
+
+	if base != nil {
+		merged.AccessCount = local.AccessCount + disk.AccessCount - base.AccessCount
+	} else {
+		merged.AccessCount = max(local.AccessCount, disk.AccessCount)
+	}
+
+	keys := make(map[string]bool)
+	for episode := range local.Episodes {
+		keys[episode] = true
+	}
+	for episode := range disk.Episodes {
+		keys[episode] = true
+	}
+
+	merged.Episodes = make(map[string]*EpisodeRecord, len(keys))
+	for episode := range keys {
+		var baseRecord *EpisodeRecord
+		if base != nil {
+			baseRecord = base.Episodes[episode]
+		}
+		if record := mergeEpisode(local.Episodes[episode], baseRecord, disk.Episodes[episode]); record != nil {
+			merged.Episodes[episode] = record
+		}
+	}
+
+	if _, exists := merged.Episodes[merged.LastEpisode]; !exists {
+		if _, exists := merged.Episodes[local.LastEpisode]; exists {
+			merged.LastEpisode = local.LastEpisode
+		} else if _, exists := merged.Episodes[disk.LastEpisode]; exists {
+			merged.LastEpisode = disk.LastEpisode
+		}
+	}
+
+	return merged
+}
+
+func mergeEpisode(local, base, disk *EpisodeRecord) *EpisodeRecord {
+	switch {
+	case sameEpisode(local, base):
+		return disk
+	case sameEpisode(disk, base):
+		return local
+	case local == nil:
+		return disk
+	case disk == nil:
+		return local
+	}
+
+	merged := *local
+	if disk.LastWatched.After(local.LastWatched) {
+		merged = *disk
+	}
+	if disk.FirstWatched.Before(local.FirstWatched) {
+		merged.FirstWatched = disk.FirstWatched
+	}
+	merged.Completed = local.Completed || disk.Completed
+	merged.WatchCount = max(local.WatchCount, disk.WatchCount)
+	return &merged
+}
+
+func sameEntry(a, b HistoryEntry) bool {
+	if a.Title != b.Title || a.Query != b.Query || a.URL != b.URL || a.TotalEps != b.TotalEps ||
+		a.LastEpisode != b.LastEpisode || a.AccessCount != b.AccessCount ||
+		!a.Timestamp.Equal(b.Timestamp) || len(a.Episodes) != len(b.Episodes) {
+		return false
+	}
+
+	for episode, record := range a.Episodes {
+		if !sameEpisode(record, b.Episodes[episode]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameEpisode(a, b *EpisodeRecord) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.FirstWatched.Equal(b.FirstWatched) && a.LastWatched.Equal(b.LastWatched) &&
+		a.Position == b.Position && a.Duration == b.Duration &&
+		a.Completed == b.Completed && a.WatchCount == b.WatchCount
+}
+
+func copyEntry(entry HistoryEntry) HistoryEntry {
+	episodes := make(map[string]*EpisodeRecord, len(entry.Episodes))
+	for episode, record := range entry.Episodes {
+		if record != nil {
+			copied := *record
+			episodes[episode] = &copied
+		}
+	}
+	entry.Episodes = episodes
+	return entry
+}
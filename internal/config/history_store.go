@@ -0,0 +1,172 @@
+package config
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+type historyMeta struct {
+	Version    int `json:"version"`
+	MaxEntries int `json:"max_entries"`
+}
+
+type historySummary struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Query       string    `json:"query"`
+	Timestamp   time.Time `json:"timestamp"`
+	AccessCount int       `json:"access_count"`
+}
+
+func recentKey(entry HistoryEntry) string {
+	return entry.Timestamp.UTC().Format("20060102T150405.000000000") + "|" + entry.ID
+}
+
+func readHistory(tx Tx, history *History) error {
+	var meta historyMeta
+	if _, err := tx.Get(BucketMeta, BucketHistory, &meta); err != nil {
+		return err
+	}
+	if meta.MaxEntries > 0 {
+		history.MaxEntries = meta.MaxEntries
+	}
+
+	history.Entries = []HistoryEntry{}
+	var decodeErr error
+	err := tx.ForEach(BucketHistory, false, func(key string, data []byte) bool {
+		var entry HistoryEntry
+		if decodeErr = json.Unmarshal(data, &entry); decodeErr != nil {
+			return false
+		}
+		history.Entries = append(history.Entries, entry)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return decodeErr
+}
+
+func writeHistory(tx Tx, history *History) error {
+	var disk History
+	if err := readHistory(tx, &disk); err != nil {
+		return err
+	}
+	history.merge(&disk)
+
+	current := make(map[string]HistoryEntry, len(disk.Entries))
+	for _, entry := range disk.Entries {
+		current[entry.ID] = entry
+	}
+
+	for _, entry := range history.Entries {
+		old, exists := current[entry.ID]
+		delete(current, entry.ID)
+		if exists && sameEntry(old, entry) {
+			continue
+		}
+
+		if exists {
+			if err := tx.Delete(BucketHistoryRecent, recentKey(old)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Put(BucketHistory, entry.ID, entry); err != nil {
+			return err
+		}
+		if err := tx.Put(BucketHistoryRecent, recentKey(entry), historySummary{
+			ID:          entry.ID,
+			Title:       entry.Title,
+			Query:       entry.Query,
+			Timestamp:   entry.Timestamp,
+			AccessCount: entry.AccessCount,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, old := range current {
+		if err := tx.Delete(BucketHistory, old.ID); err != nil {
+			return err
+		}
+		if err := tx.Delete(BucketHistoryRecent, recentKey(old)); err != nil {
+			return err
+		}
+	}
+
+	if err := writeWatchEvents(tx, history.events); err != nil {
+		return err
+	}
+
+	return tx.Put(BucketMeta, BucketHistory, historyMeta{
+		Version:    HistoryVersion,
+		MaxEntries: history.MaxEntries,
+	})
+}
+
+func queryHistory(limit int, match func(historySummary) bool, less func(a, b historySummary) bool) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	err := viewRepository(func(tx Tx) error {
+		var summaries []historySummary
+		var decodeErr error
+		err := tx.ForEach(BucketHistoryRecent, true, func(key string, data []byte) bool {
+			var summary historySummary
+			if decodeErr = json.Unmarshal(data, &summary); decodeErr != nil {
+				return false
+			}
+			if match == nil || match(summary) {
+				summaries = append(summaries, summary)
+			}
+			return less != nil || limit <= 0 || len(summaries) < limit
+		})
+		if err != nil {
+			return err
+		}
+		if decodeErr != nil {
+			return decodeErr
+		}
+
+		if less != nil {
+			sort.SliceStable(summaries, func(i, j int) bool {
+				return less(summaries[i], summaries[j])
+			})
+		}
+		if limit > 0 && limit < len(summaries) {
+			summaries = summaries[:limit]
+		}
+
+		entries = make([]HistoryEntry, 0, len(summaries))
+		for _, summary := range summaries {
+			var entry HistoryEntry
+			found, err := tx.Get(BucketHistory, summary.ID, &entry)
+			if err != nil {
+				return err
+			}
+			if found {
+				entries = append(entries, entry)
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func queryRecent(limit int) ([]HistoryEntry, error) {
+	return queryHistory(limit, nil, nil)
+}
+
+func queryMostWatched(limit int) ([]HistoryEntry, error) {
+	return queryHistory(limit, nil, func(a, b historySummary) bool {
+		return a.AccessCount > b.AccessCount
+	})
+}
+
+func querySearch(query string) ([]HistoryEntry, error) {
+	queryLower := strings.ToLower(query)
+	return queryHistory(0, func(summary historySummary) bool {
+		return strings.Contains(strings.ToLower(summary.Title), queryLower) ||
+			strings.Contains(strings.ToLower(summary.Query), queryLower)
+	}, nil)
+}
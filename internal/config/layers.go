@@ -0,0 +1,442 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/keircn/karu/pkg/errors"
+	"github.com/keircn/karu/pkg/validation"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceProfile = "profile"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+
+	EnvPrefix  = "KARU_"
+	EnvProfile = "KARU_PROFILE"
+
+	profilesKey = "profiles"
+	keysKey     = "keys"
+)
+
+var configFileNames = []string{"config.json", "config.toml", "config.yaml", "config.yml"}
+
+type Origin struct {
+	Source string
+	Name   string
+}
+
+func (o Origin) String() string {
+	if o.Name == "" {
+		return o.Source
+	}
+	return o.Source + " " + o.Name
+}
+
+func (o Origin) IsOverride() bool {
+	return o.Source == SourceProfile || o.Source == SourceEnv || o.Source == SourceFlag
+}
+
+var (
+	flagOverrides = make(map[string]string)
+	activeProfile string
+)
+
+func SetFlagOverride(key, value string) error {
+	if _, exists := lookupField(key); !exists {
+		return errors.New(errors.ValidationError, "unknown config key: "+key)
+	}
+	flagOverrides[key] = value
+	return nil
+}
+
+func SetProfile(name string) {
+	activeProfile = name
+}
+
+func ActiveProfile() string {
+	if activeProfile != "" {
+		return activeProfile
+	}
+	return os.Getenv(EnvProfile)
+}
+
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+func GetConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.Wrap(err, errors.ConfigError, "failed to get user config directory")
+	}
+
+	karuConfigDir := filepath.Join(configDir, "karu")
+	if err := validation.EnsureDirectoryExists(karuConfigDir); err != nil {
+		return "", err
+	}
+
+	for _, name := range configFileNames {
+		path := filepath.Join(karuConfigDir, name)
+		if fileExists(path) {
+			return path, nil
+		}
+	}
+
+	return filepath.Join(karuConfigDir, configFileNames[0]), nil
+}
+
+func Load() (*Config, error) {
+	config, _, err := load(false)
+	return config, err
+}
+
+// LoadForRepair loads the config like Load, but keeps defaults in place of
+// invalid values instead of failing, so that the config commands can still
+// fix them. It returns the problems with the values it skipped.
+func LoadForRepair() (*Config, []error, error) {
+	return load(true)
+}
+
+func load(lenient bool) (*Config, []error, error) {
+	var problems []error
+	failed := func(errs ...error) bool {
+		if lenient {
+			problems = append(problems, errs...)
+			return false
+		}
+		return len(errs) > 0
+	}
+
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return &DefaultConfig, nil, nil
+	}
+
+	config := DefaultConfig
+	config.path = configPath
+	config.origins = make(map[string]Origin)
+	for _, key := range Keys() {
+		config.origins[key] = Origin{Source: SourceDefault}
+	}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		if err := Save(&config); err != nil {
+			return &DefaultConfig, nil, nil
+		}
+	} else {
+		var values map[string]any
+		err = ReadFile(configPath, func(data []byte) error {
+			decoded, err := decodeConfigFile(configPath, data)
+			values = decoded
+			return err
+		})
+		if err != nil {
+			return &DefaultConfig, nil, errors.Wrap(err, errors.ConfigError, "failed to load config file")
+		}
+
+		fileOrigin := Origin{Source: SourceFile, Name: configPath}
+		if errs := config.applyValues(values, fileOrigin); failed(errs...) {
+			return &DefaultConfig, nil, errs[0]
+		}
+		config.profiles = profileValues(values[profilesKey])
+		config.keys = keyValues(values[keysKey])
+	}
+
+	config.base = make(map[string]string)
+	for _, key := range Keys() {
+		config.base[key] = config.Get(key)
+	}
+
+	if profile := ActiveProfile(); profile != "" {
+		values, exists := config.profiles[profile]
+		if !exists {
+			err := errors.New(errors.ConfigError, fmt.Sprintf("unknown profile '%s'", profile))
+			if failed(err) {
+				return &DefaultConfig, nil, err
+			}
+		}
+		if errs := config.applyValues(values, Origin{Source: SourceProfile, Name: profile}); failed(errs...) {
+			return &DefaultConfig, nil, errs[0]
+		}
+	}
+
+	for _, key := range Keys() {
+		if value, exists := os.LookupEnv(EnvName(key)); exists {
+			if err := config.apply(key, value, Origin{Source: SourceEnv, Name: EnvName(key)}); err != nil && failed(err) {
+				return &DefaultConfig, nil, err
+			}
+		}
+	}
+
+	for key, value := range flagOverrides {
+		flag := "--" + strings.ReplaceAll(key, "_", "-")
+		if err := config.apply(key, value, Origin{Source: SourceFlag, Name: flag}); err != nil && failed(err) {
+			return &DefaultConfig, nil, err
+		}
+	}
+
+	if err := config.validate(); err != nil && failed(err) {
+		return &DefaultConfig, nil, err
+	}
+
+	config.applyDefaults()
+	return &config, problems, nil
+}
+
+func (c *Config) Origin(key string) Origin {
+	if origin, exists := c.origins[key]; exists {
+		return origin
+	}
+	return Origin{Source: SourceDefault}
+}
+
+func (c *Config) Profiles() []string {
+	names := make([]string, 0, len(c.profiles))
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Config) KeyBindings() map[string]map[string][]string {
+	return c.keys
+}
+
+func (c *Config) applyValues(values map[string]any, origin Origin) []error {
+	var problems []error
+	for _, key := range Keys() {
+		value, exists := values[key]
+		if !exists {
+			continue
+		}
+		if err := c.apply(key, formatValue(value), origin); err != nil {
+			problems = append(problems, err)
+			if origin.Source == SourceFile {
+				if c.invalid == nil {
+					c.invalid = make(map[string]any)
+				}
+				c.invalid[key] = value
+			}
+		}
+	}
+	return problems
+}
+
+func Save(config *Config) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	configPath := config.path
+	if configPath == "" {
+		var err error
+		if configPath, err = GetConfigPath(); err != nil {
+			return err
+		}
+	}
+
+	values := make(map[string]any)
+	for _, field := range Fields() {
+		value := config.Get(field.Key)
+		if base, exists := config.base[field.Key]; exists && config.Origin(field.Key).IsOverride() {
+			value = base
+		}
+		values[field.Key] = typedValue(field.Kind, value)
+		if raw, exists := config.invalid[field.Key]; exists {
+			values[field.Key] = raw
+		}
+	}
+	if len(config.profiles) > 0 {
+		values[profilesKey] = config.profiles
+	}
+	if len(config.keys) > 0 {
+		values[keysKey] = config.keys
+	}
+
+	data, err := encodeConfigFile(configPath, values)
+	if err != nil {
+		return errors.Wrap(err, errors.ConfigError, "failed to marshal config")
+	}
+
+	if err := WriteFile(configPath, data, 0644); err != nil {
+		return errors.Wrap(err, errors.ConfigError, "failed to write config file")
+	}
+
+	return nil
+}
+
+func CheckFile(path string, data, previous []byte) []error {
+	values, err := decodeConfigFile(path, data)
+	if err != nil {
+		return []error{err}
+	}
+	before, _ := decodeConfigFile(path, previous)
+
+	var problems []error
+	check := func(prefix string, values, before map[string]any) {
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			if (key == profilesKey || key == keysKey) && prefix == "" {
+				continue
+			}
+			if _, exists := lookupField(key); !exists {
+				problems = append(problems, fmt.Errorf("%sunknown key '%s'", prefix, key))
+				continue
+			}
+			value := formatValue(values[key])
+			if old, exists := before[key]; exists && formatValue(old) == value {
+				continue
+			}
+			if err := CheckValue(key, value); err != nil {
+				problems = append(problems, fmt.Errorf("%s%s: %v", prefix, key, err))
+			}
+		}
+	}
+
+	check("", values, before)
+	profiles := profileValues(values[profilesKey])
+	previousProfiles := profileValues(before[profilesKey])
+	for _, name := range slices.Sorted(maps.Keys(profiles)) {
+		check("profile "+name+": ", profiles[name], previousProfiles[name])
+	}
+
+	if checkKeyBindings != nil {
+		problems = append(problems, checkKeyBindings(keyValues(values[keysKey]))...)
+	}
+	return problems
+}
+
+func configFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return "toml"
+	case ".yaml", ".yml":
+		return "yaml"
+	default:
+		return "json"
+	}
+}
+
+func decodeConfigFile(path string, data []byte) (map[string]any, error) {
+	values := make(map[string]any)
+
+	var err error
+	switch configFormat(path) {
+	case "toml":
+		err = toml.Unmarshal(data, &values)
+	case "yaml":
+		err = yaml.Unmarshal(data, &values)
+	default:
+		err = json.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if values == nil {
+		values = make(map[string]any)
+	}
+	return values, nil
+}
+
+func encodeConfigFile(path string, values map[string]any) ([]byte, error) {
+	switch configFormat(path) {
+	case "toml":
+		var buf bytes.Buffer
+		err := toml.NewEncoder(&buf).Encode(values)
+		return buf.Bytes(), err
+	case "yaml":
+		return yaml.Marshal(values)
+	default:
+		return json.MarshalIndent(values, "", "  ")
+	}
+}
+
+func profileValues(raw any) map[string]map[string]any {
+	profiles := make(map[string]map[string]any)
+
+	entries, ok := raw.(map[string]any)
+	if !ok {
+		return profiles
+	}
+	for name, entry := range entries {
+		if values, ok := entry.(map[string]any); ok {
+			profiles[name] = values
+		}
+	}
+	return profiles
+}
+
+func keyValues(raw any) map[string]map[string][]string {
+	keys := make(map[string]map[string][]string)
+
+	screens, ok := raw.(map[string]any)
+	if !ok {
+		return keys
+	}
+	for screen, entry := range screens {
+		actions, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		keys[screen] = make(map[string][]string)
+		for action, value := range actions {
+			switch v := value.(type) {
+			case []any:
+				for _, item := range v {
+					keys[screen][action] = append(keys[screen][action], formatValue(item))
+				}
+			default:
+				keys[screen][action] = strings.Fields(formatValue(v))
+			}
+		}
+	}
+	return keys
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func typedValue(kind reflect.Kind, value string) any {
+	switch kind {
+	case reflect.Bool:
+		parsed, _ := strconv.ParseBool(value)
+		return parsed
+	case reflect.Int:
+		parsed, _ := strconv.Atoi(value)
+		return parsed
+	default:
+		return value
+	}
+}
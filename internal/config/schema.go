@@ -0,0 +1,368 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/keircn/karu/pkg/errors"
+	"github.com/keircn/karu/pkg/validation"
+)
+
+type Field struct {
+	Key         string
+	Kind        reflect.Kind
+	Description string
+	Allowed     []string
+	index       int
+}
+
+var KnownUpdateIntervals = []string{"daily", "weekly", "never"}
+
+var KnownImageModes = []string{"auto", "kitty", "iterm2", "sixel", "blocks", "off"}
+
+var KnownQualities = []string{"auto", "2160p", "1440p", "1080p", "720p", "480p", "360p"}
+
+var schema = buildSchema()
+
+// Themes and key bindings are defined by the UI, which registers them here so
+// that this package does not have to import it.
+var (
+	themeNames       []string
+	checkKeyBindings func(map[string]map[string][]string) []error
+)
+
+func RegisterThemes(names []string) {
+	themeNames = names
+	for i := range schema {
+		if schema[i].Key == "theme" {
+			schema[i].Allowed = names
+		}
+	}
+}
+
+func RegisterKeyBindingCheck(check func(map[string]map[string][]string) []error) {
+	checkKeyBindings = check
+}
+
+var descriptions = map[string]string{
+	"player":                  "Video player command or full path to its executable",
+	"player_args":             "Extra arguments passed to the video player",
+	"quality":                 "Preferred video quality",
+	"download_dir":            "Directory that downloaded episodes are saved to",
+	"auto_play_next":          "Start the next episode automatically when one finishes",
+	"show_subtitles":          "Show subtitles when they are available",
+	"theme":                   "Color theme for menus and the player UI",
+	"cache_ttl_minutes":       "How long search results are cached, in minutes",
+	"request_timeout_seconds": "Timeout for requests to the anime provider, in seconds",
+	"concurrent_workers":      "Number of parallel requests when loading many shows",
+	"preload_episodes":        "Number of upcoming episodes to resolve ahead of time",
+	"auto_skip":               "Skip openings and endings using AniSkip timestamps",
+	"aniskip_url":             "AniSkip API endpoint",
+	"use_proxy":               "Route streams through the local proxy",
+	"proxy_port":              "Port for the local proxy (0 picks a free port)",
+	"on_play_start":           "Command run when playback starts",
+	"on_episode_finished":     "Command run when an episode finishes",
+	"on_download_complete":    "Command run when a download completes",
+	"on_error":                "Command run when playback or a download fails",
+	"hook_timeout_seconds":    "Maximum run time for hook commands, in seconds",
+	"anilist_endpoint":        "AniList GraphQL API endpoint",
+	"anilist_client_id":       "AniList API client ID used for logging in",
+	"anilist_sync":            "Push watch progress to AniList after each episode",
+	"check_updates":           "How often to check for new karu releases",
+	"update_endpoint":         "GitHub releases API endpoint used for update checks",
+	"show_images":             "How cover art is drawn in the terminal, or off to hide it",
+}
+
+var validators = map[string]func(key, value string) error{
+	"player":                  validateNonEmpty,
+	"quality":                 validateQuality,
+	"download_dir":            validateNonEmpty,
+	"theme":                   validateTheme,
+	"cache_ttl_minutes":       validatePositive,
+	"request_timeout_seconds": validatePositive,
+	"concurrent_workers":      validatePositive,
+	"hook_timeout_seconds":    validatePositive,
+	"preload_episodes":        validateNonNegative,
+	"aniskip_url":             validateURL,
+	"anilist_endpoint":        validateURL,
+	"proxy_port":              validatePort,
+	"check_updates":           validateUpdateInterval,
+	"update_endpoint":         validateURL,
+	"show_images":             validateImageMode,
+}
+
+var normalizers = map[string]func(value string) string{
+	"quality": normalizeQuality,
+}
+
+// Qualities used to be matched as substrings of the provider's labels, so
+// configs written back then may hold values like "1080" or "best".
+var legacyQualities = map[string]string{
+	"best": "auto",
+	"4k":   "2160p",
+}
+
+func normalizeQuality(value string) string {
+	quality := strings.ToLower(strings.TrimSpace(value))
+	if alias, exists := legacyQualities[quality]; exists {
+		return alias
+	}
+	if _, err := strconv.Atoi(quality); err == nil && slices.Contains(KnownQualities, quality+"p") {
+		return quality + "p"
+	}
+	return quality
+}
+
+func buildSchema() []Field {
+	configType := reflect.TypeOf(Config{})
+
+	var fields []Field
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || key == "" || key == "-" {
+			continue
+		}
+		entry := Field{Key: key, Kind: field.Type.Kind(), Description: descriptions[key], index: i}
+		switch {
+		case entry.Kind == reflect.Bool:
+			entry.Allowed = []string{"true", "false"}
+		case key == "quality":
+			entry.Allowed = KnownQualities
+		case key == "check_updates":
+			entry.Allowed = KnownUpdateIntervals
+		case key == "show_images":
+			entry.Allowed = KnownImageModes
+		}
+		fields = append(fields, entry)
+	}
+	return fields
+}
+
+func Fields() []Field {
+	return schema
+}
+
+func Keys() []string {
+	keys := make([]string, len(schema))
+	for i, field := range schema {
+		keys[i] = field.Key
+	}
+	return keys
+}
+
+func (f Field) Type() string {
+	switch {
+	case f.Kind == reflect.Bool:
+		return "bool"
+	case f.Kind == reflect.Int:
+		return "int"
+	case len(f.Allowed) > 0:
+		return "choice"
+	default:
+		return "string"
+	}
+}
+
+func (f Field) Default() string {
+	return DefaultConfig.Get(f.Key)
+}
+
+func lookupField(key string) (Field, bool) {
+	for _, field := range schema {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+func (c *Config) Get(key string) string {
+	field, exists := lookupField(key)
+	if !exists {
+		return ""
+	}
+
+	value := reflect.ValueOf(c).Elem().Field(field.index)
+	switch field.Kind {
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int:
+		return strconv.FormatInt(value.Int(), 10)
+	default:
+		return value.String()
+	}
+}
+
+func (c *Config) Set(key, value string) error {
+	return c.Update(map[string]string{key: value})
+}
+
+func (c *Config) Update(values map[string]string) error {
+	for key, value := range values {
+		if err := CheckValue(key, value); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range values {
+		if err := c.apply(key, value, Origin{Source: SourceFile, Name: c.path}); err != nil {
+			return err
+		}
+		if c.base != nil {
+			c.base[key] = c.Get(key)
+		}
+		delete(c.invalid, key)
+	}
+	return Save(c)
+}
+
+func CheckValue(key, value string) error {
+	var probe Config
+	if err := probe.apply(key, value, Origin{}); err != nil {
+		return err
+	}
+
+	switch key {
+	case "player":
+		if !isPlayerAvailable(value) && !fileExists(value) {
+			return errors.New(errors.ValidationError, "player '"+value+"' was not found")
+		}
+	case "download_dir":
+		return checkWritableDir(value)
+	}
+	return nil
+}
+
+func checkWritableDir(path string) error {
+	dir := filepath.Clean(path)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return errors.New(errors.ValidationError, dir+" is not a directory")
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return errors.New(errors.ValidationError, "no existing parent directory for "+path)
+		}
+		dir = parent
+	}
+
+	probe, err := os.CreateTemp(dir, ".karu-write-test-*")
+	if err != nil {
+		return errors.New(errors.ValidationError, "directory "+dir+" is not writable")
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
+func (c *Config) apply(key, value string, origin Origin) error {
+	field, exists := lookupField(key)
+	if !exists {
+		return errors.New(errors.ValidationError, "unknown config key: "+key)
+	}
+
+	if normalize, exists := normalizers[key]; exists {
+		value = normalize(value)
+	}
+	if validate, exists := validators[key]; exists {
+		if err := validate(key, value); err != nil {
+			return err
+		}
+	}
+
+	target := reflect.ValueOf(c).Elem().Field(field.index)
+	switch field.Kind {
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return errors.Wrapf(err, errors.ValidationError, "invalid %s: must be true or false", key)
+		}
+		target.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return errors.Wrapf(err, errors.ValidationError, "invalid %s: must be a number", key)
+		}
+		target.SetInt(int64(parsed))
+	default:
+		target.SetString(value)
+	}
+
+	if c.origins == nil {
+		c.origins = make(map[string]Origin)
+	}
+	c.origins[key] = origin
+	return nil
+}
+
+func validateNonEmpty(key, value string) error {
+	return validation.ValidateNonEmptyString(value, key)
+}
+
+func validatePositive(key, value string) error {
+	_, err := validation.ValidatePositiveInt(strings.TrimSpace(value), key)
+	return err
+}
+
+func validateNonNegative(key, value string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return errors.Wrapf(err, errors.ValidationError, "invalid %s: must be a number", key)
+	}
+	if n < 0 {
+		return errors.New(errors.ValidationError, key+" must be non-negative")
+	}
+	return nil
+}
+
+func validateTheme(key, value string) error {
+	if themeNames != nil && !slices.Contains(themeNames, value) {
+		return errors.New(errors.ValidationError, "unknown theme '"+value+"' (use one of "+strings.Join(themeNames, ", ")+")")
+	}
+	return nil
+}
+
+func validateQuality(key, value string) error {
+	if !slices.Contains(KnownQualities, strings.ToLower(strings.TrimSpace(value))) {
+		return errors.New(errors.ValidationError, "unknown quality '"+value+"' (use one of "+strings.Join(KnownQualities, ", ")+")")
+	}
+	return nil
+}
+
+func validateUpdateInterval(key, value string) error {
+	if !slices.Contains(KnownUpdateIntervals, value) {
+		return errors.New(errors.ValidationError, "invalid "+key+" (use one of "+strings.Join(KnownUpdateIntervals, ", ")+")")
+	}
+	return nil
+}
+
+func validateImageMode(key, value string) error {
+	if !slices.Contains(KnownImageModes, value) {
+		return errors.New(errors.ValidationError, "invalid "+key+" (use one of "+strings.Join(KnownImageModes, ", ")+")")
+	}
+	return nil
+}
+
+func validateURL(key, value string) error {
+	return validation.ValidateURL(value)
+}
+
+func validatePort(key, value string) error {
+	port, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return errors.Wrapf(err, errors.ValidationError, "invalid %s: must be a number", key)
+	}
+	if port < 0 || port > 65535 {
+		return errors.New(errors.ValidationError, key+" must be between 0 and 65535")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const searchCachePrefix = "search:"
+
+type CachedShow struct {
+	ShowID    string   `json:"show_id"`
+	Title     string   `json:"title"`
+	Episodes  string   `json:"episodes"`
+	AltTitles []string `json:"alt_titles,omitempty"`
+	Thumbnail string   `json:"thumbnail,omitempty"`
+}
+
+type SearchCacheEntry struct {
+	Query    string       `json:"query"`
+	Results  []CachedShow `json:"results"`
+	StoredAt time.Time    `json:"stored_at"`
+}
+
+func searchCacheKey(query string) string {
+	return searchCachePrefix + strings.ToLower(strings.TrimSpace(query))
+}
+
+func PutSearchCache(query string, results []CachedShow) error {
+	return updateRepository(func(tx Tx) error {
+		return tx.Put(BucketCache, searchCacheKey(query), SearchCacheEntry{
+			Query:    query,
+			Results:  results,
+			StoredAt: time.Now(),
+		})
+	})
+}
+
+func GetSearchCache(query string, maxAge time.Duration) (SearchCacheEntry, bool) {
+	var entry SearchCacheEntry
+	var found bool
+	err := viewRepository(func(tx Tx) error {
+		var err error
+		found, err = tx.Get(BucketCache, searchCacheKey(query), &entry)
+		return err
+	})
+	if err != nil || !found || time.Since(entry.StoredAt) > maxAge {
+		return SearchCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func SearchCacheByPrefix(prefix string, limit int) ([]SearchCacheEntry, error) {
+	var entries []SearchCacheEntry
+	err := viewRepository(func(tx Tx) error {
+		return tx.ForEachPrefix(BucketCache, searchCacheKey(prefix), func(key string, data []byte) bool {
+			var entry SearchCacheEntry
+			if json.Unmarshal(data, &entry) == nil {
+				entries = append(entries, entry)
+			}
+			return len(entries) < limit
+		})
+	})
+	return entries, err
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"time"
+)
+
+const showMetadataPrefix = "show:"
+
+type ShowMetadata struct {
+	ShowID    string    `json:"show_id"`
+	Genres    []string  `json:"genres,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func PutShowMetadata(metadata ShowMetadata) error {
+	metadata.UpdatedAt = time.Now()
+	return updateRepository(func(tx Tx) error {
+		return tx.Put(BucketCache, showMetadataPrefix+metadata.ShowID, metadata)
+	})
+}
+
+func GetShowMetadata(showIDs []string) (map[string]ShowMetadata, error) {
+	metadata := make(map[string]ShowMetadata)
+	err := viewRepository(func(tx Tx) error {
+		for _, showID := range showIDs {
+			var entry ShowMetadata
+			found, err := tx.Get(BucketCache, showMetadataPrefix+showID, &entry)
+			if err != nil {
+				return err
+			}
+			if found {
+				metadata[showID] = entry
+			}
+		}
+		return nil
+	})
+	return metadata, err
+}
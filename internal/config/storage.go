@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/keircn/karu/pkg/errors"
+)
+
+func LockFile(path string) (func(), error) {
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ConfigError, "failed to open lock file")
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, errors.ConfigError, "failed to lock %s", filepath.Base(path))
+	}
+
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	unlock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return writeFileLocked(path, data, perm)
+}
+
+func writeFileLocked(path string, data []byte, perm os.FileMode) error {
+	if current, err := os.ReadFile(path); err == nil && len(bytes.TrimSpace(current)) > 0 {
+		if err := writeFileAtomic(backupPath(path), current, perm); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(path, data, perm)
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, errors.ConfigError, "failed to create temporary file")
+	}
+	tempPath := temp.Name()
+
+	_, err = temp.Write(data)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, perm)
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return errors.Wrapf(err, errors.ConfigError, "failed to write %s", filepath.Base(path))
+	}
+
+	return nil
+}
+
+func ReadFile(path string, decode func([]byte) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	parseErr := decode(data)
+	if parseErr == nil {
+		return nil
+	}
+
+	unlock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if data, err := os.ReadFile(path); err == nil && decode(data) == nil {
+		return nil
+	}
+
+	damagedPath := fmt.Sprintf("%s.damaged-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, damagedPath); err != nil {
+		return errors.Wrapf(parseErr, errors.ConfigError, "%s is damaged", filepath.Base(path))
+	}
+
+	backup, err := os.ReadFile(backupPath(path))
+	if err != nil || decode(backup) != nil {
+		return errors.Wrapf(parseErr, errors.ConfigError,
+			"%s is damaged and no usable backup was found, it was moved to %s", filepath.Base(path), damagedPath)
+	}
+
+	info, err := os.Stat(damagedPath)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, backup, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: %s was damaged and has been restored from its backup (damaged copy: %s)\n",
+		filepath.Base(path), damagedPath)
+	return nil
+}
+
+func backupPath(path string) string {
+	return path + ".bak"
+}
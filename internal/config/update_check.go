@@ -0,0 +1,31 @@
+package config
+
+import "time"
+
+const updateCheckKey = "update:latest"
+
+type UpdateCheck struct {
+	Latest    string    `json:"latest"`
+	URL       string    `json:"url"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+func PutUpdateCheck(check UpdateCheck) error {
+	return updateRepository(func(tx Tx) error {
+		return tx.Put(BucketCache, updateCheckKey, check)
+	})
+}
+
+func GetUpdateCheck() (UpdateCheck, bool) {
+	var check UpdateCheck
+	var found bool
+	err := viewRepository(func(tx Tx) error {
+		var err error
+		found, err = tx.Get(BucketCache, updateCheckKey, &check)
+		return err
+	})
+	if err != nil || !found {
+		return UpdateCheck{}, false
+	}
+	return check, true
+}
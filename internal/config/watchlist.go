@@ -0,0 +1,324 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const WatchlistVersion = 1
+
+type WatchStatus string
+
+const (
+	StatusWatching  WatchStatus = "watching"
+	StatusPlanned   WatchStatus = "planned"
+	StatusCompleted WatchStatus = "completed"
+	StatusOnHold    WatchStatus = "on-hold"
+	StatusDropped   WatchStatus = "dropped"
+)
+
+var WatchStatuses = []WatchStatus{
+	StatusWatching,
+	StatusPlanned,
+	StatusCompleted,
+	StatusOnHold,
+	StatusDropped,
+}
+
+func ParseWatchStatus(value string) (WatchStatus, error) {
+	normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value)), "_", "-")
+	switch normalized {
+	case "onhold", "hold":
+		normalized = string(StatusOnHold)
+	case "plan", "plan-to-watch", "ptw":
+		normalized = string(StatusPlanned)
+	}
+
+	for _, status := range WatchStatuses {
+		if string(status) == normalized {
+			return status, nil
+		}
+	}
+
+	names := make([]string, len(WatchStatuses))
+	for i, status := range WatchStatuses {
+		names[i] = string(status)
+	}
+	return "", fmt.Errorf("invalid status '%s' (must be one of: %s)", value, strings.Join(names, ", "))
+}
+
+type WatchlistEntry struct {
+	ID          string      `json:"id"`
+	Provider    string      `json:"provider"`
+	ShowID      string      `json:"show_id"`
+	Title       string      `json:"title"`
+	URL         string      `json:"url"`
+	Status      WatchStatus `json:"status"`
+	Score       int         `json:"score,omitempty"`
+	Notes       string      `json:"notes,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	KnownEps    int         `json:"known_episodes,omitempty"`
+	AddedAt     time.Time   `json:"added_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+}
+
+func (e *WatchlistEntry) SetStatus(status WatchStatus) {
+	e.Status = status
+	if status == StatusCompleted {
+		if e.CompletedAt == nil {
+			now := time.Now()
+			e.CompletedAt = &now
+		}
+	} else {
+		e.CompletedAt = nil
+	}
+}
+
+func (e *WatchlistEntry) SetScore(score int) error {
+	if score < 0 || score > 10 {
+		return fmt.Errorf("score must be between 0 and 10")
+	}
+	e.Score = score
+	return nil
+}
+
+func (e *WatchlistEntry) AddTags(tags ...string) {
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || e.HasTag(tag) {
+			continue
+		}
+		e.Tags = append(e.Tags, tag)
+	}
+}
+
+func (e *WatchlistEntry) RemoveTags(tags ...string) {
+	kept := e.Tags[:0]
+	for _, existing := range e.Tags {
+		remove := false
+		for _, tag := range tags {
+			if strings.EqualFold(existing, strings.TrimSpace(tag)) {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			kept = append(kept, existing)
+		}
+	}
+	e.Tags = kept
+}
+
+func (e WatchlistEntry) HasTag(tag string) bool {
+	for _, existing := range e.Tags {
+		if strings.EqualFold(existing, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+type Watchlist struct {
+	Version int              `json:"version"`
+	Entries []WatchlistEntry `json:"entries"`
+
+	base map[string]WatchlistEntry
+}
+
+func GetWatchlistPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	karuConfigDir := filepath.Join(configDir, "karu")
+	if err := os.MkdirAll(karuConfigDir, 0755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(karuConfigDir, "watchlist.json"), nil
+}
+
+func LoadWatchlist() (*Watchlist, error) {
+	watchlist := &Watchlist{Version: WatchlistVersion, Entries: []WatchlistEntry{}}
+
+	err := viewRepository(func(tx Tx) error {
+		entries, err := readWatchlist(tx)
+		watchlist.Entries = append(watchlist.Entries, entries...)
+		return err
+	})
+	if err != nil {
+		return watchlist, err
+	}
+
+	watchlist.snapshot()
+	return watchlist, nil
+}
+
+func SaveWatchlist(watchlist *Watchlist) error {
+	watchlist.Version = WatchlistVersion
+
+	if err := updateRepository(func(tx Tx) error {
+		return writeWatchlist(tx, watchlist)
+	}); err != nil {
+		return err
+	}
+
+	watchlist.snapshot()
+	return nil
+}
+
+func readWatchlist(tx Tx) ([]WatchlistEntry, error) {
+	var entries []WatchlistEntry
+	var decodeErr error
+	err := tx.ForEach(BucketWatchlist, false, func(key string, data []byte) bool {
+		var entry WatchlistEntry
+		if decodeErr = json.Unmarshal(data, &entry); decodeErr != nil {
+			return false
+		}
+		entries = append(entries, entry)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, decodeErr
+}
+
+func writeWatchlist(tx Tx, watchlist *Watchlist) error {
+	disk, err := readWatchlist(tx)
+	if err != nil {
+		return err
+	}
+	watchlist.merge(disk)
+
+	current := make(map[string]WatchlistEntry, len(disk))
+	for _, entry := range disk {
+		current[entry.ID] = entry
+	}
+
+	for _, entry := range watchlist.Entries {
+		old, exists := current[entry.ID]
+		delete(current, entry.ID)
+		if exists && sameWatchlistEntry(old, entry) {
+			continue
+		}
+		if err := tx.Put(BucketWatchlist, entry.ID, entry); err != nil {
+			return err
+		}
+	}
+
+	for id := range current {
+		if err := tx.Delete(BucketWatchlist, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Watchlist) find(showID string) int {
+	id := MakeHistoryID(DefaultProvider, showID)
+	for i, entry := range w.Entries {
+		if entry.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (w *Watchlist) Find(showID string) (WatchlistEntry, bool) {
+	if i := w.find(showID); i != -1 {
+		return w.Entries[i], true
+	}
+	return WatchlistEntry{}, false
+}
+
+func (w *Watchlist) Add(showID, title, url string, status WatchStatus) (bool, error) {
+	if i := w.find(showID); i != -1 {
+		return false, nil
+	}
+
+	now := time.Now()
+	entry := WatchlistEntry{
+		ID:        MakeHistoryID(DefaultProvider, showID),
+		Provider:  DefaultProvider,
+		ShowID:    showID,
+		Title:     title,
+		URL:       url,
+		AddedAt:   now,
+		UpdatedAt: now,
+	}
+	entry.SetStatus(status)
+
+	w.Entries = append(w.Entries, entry)
+	return true, SaveWatchlist(w)
+}
+
+func (w *Watchlist) Put(entry WatchlistEntry) {
+	if entry.Provider == "" {
+		entry.Provider = DefaultProvider
+	}
+	entry.ID = MakeHistoryID(entry.Provider, entry.ShowID)
+
+	if i := w.find(entry.ShowID); i != -1 {
+		w.Entries[i] = entry
+		return
+	}
+	w.Entries = append(w.Entries, entry)
+}
+
+func (w *Watchlist) Update(showID string, update func(*WatchlistEntry) error) error {
+	i := w.find(showID)
+	if i == -1 {
+		return fmt.Errorf("show is not in your watchlist")
+	}
+
+	if err := update(&w.Entries[i]); err != nil {
+		return err
+	}
+	w.Entries[i].UpdatedAt = time.Now()
+
+	return SaveWatchlist(w)
+}
+
+func (w *Watchlist) Remove(showID string) (bool, error) {
+	if i := w.find(showID); i != -1 {
+		w.Entries = append(w.Entries[:i], w.Entries[i+1:]...)
+		return true, SaveWatchlist(w)
+	}
+	return false, nil
+}
+
+func (w *Watchlist) ByStatus(status WatchStatus) []WatchlistEntry {
+	var entries []WatchlistEntry
+	for _, entry := range w.Entries {
+		if status == "" || entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].UpdatedAt.After(entries[j].UpdatedAt)
+	})
+
+	return entries
+}
+
+func (w *Watchlist) Search(query string) []WatchlistEntry {
+	var matches []WatchlistEntry
+	queryLower := strings.ToLower(query)
+
+	for _, entry := range w.Entries {
+		if entry.ShowID == query || strings.Contains(strings.ToLower(entry.Title), queryLower) {
+			matches = append(matches, entry)
+		}
+	}
+
+	return matches
+}
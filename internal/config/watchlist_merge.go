@@ -0,0 +1,70 @@
+package config
+
+import (
+	"slices"
+)
+
+func (w *Watchlist) snapshot() {
+	w.base = make(map[string]WatchlistEntry, len(w.Entries))
+	for _, entry := range w.Entries {
+		w.base[entry.ID] = copyWatchlistEntry(entry)
+	}
+}
+
+func (w *Watchlist) merge(disk []WatchlistEntry) {
+	diskEntries := make(map[string]WatchlistEntry, len(disk))
+	for _, entry := range disk {
+		diskEntries[entry.ID] = entry
+	}
+
+	merged := make([]WatchlistEntry, 0, len(w.Entries)+len(disk))
+	seen := make(map[string]bool, len(w.Entries))
+	for _, local := range w.Entries {
+		seen[local.ID] = true
+		base, inBase := w.base[local.ID]
+		onDisk, inDisk := diskEntries[local.ID]
+
+		switch {
+		case !inDisk && inBase && sameWatchlistEntry(local, base):
+			continue
+		case !inDisk:
+			merged = append(merged, local)
+		case inBase && sameWatchlistEntry(local, base):
+			merged = append(merged, onDisk)
+		case onDisk.UpdatedAt.After(local.UpdatedAt):
+			merged = append(merged, onDisk)
+		default:
+			merged = append(merged, local)
+		}
+	}
+
+	for _, entry := range disk {
+		if _, inBase := w.base[entry.ID]; seen[entry.ID] || inBase {
+			continue
+		}
+		merged = append(merged, entry)
+	}
+
+	w.Entries = merged
+}
+
+func sameWatchlistEntry(a, b WatchlistEntry) bool {
+	if a.Provider != b.Provider || a.ShowID != b.ShowID || a.Title != b.Title || a.URL != b.URL ||
+		a.Status != b.Status || a.Score != b.Score || a.Notes != b.Notes || a.KnownEps != b.KnownEps ||
+		!a.AddedAt.Equal(b.AddedAt) || !a.UpdatedAt.Equal(b.UpdatedAt) || !slices.Equal(a.Tags, b.Tags) {
+		return false
+	}
+	if a.CompletedAt == nil || b.CompletedAt == nil {
+		return a.CompletedAt == b.CompletedAt
+	}
+	return a.CompletedAt.Equal(*b.CompletedAt)
+}
+
+func copyWatchlistEntry(entry WatchlistEntry) WatchlistEntry {
+	entry.Tags = slices.Clone(entry.Tags)
+	if entry.CompletedAt != nil {
+		completed := *entry.CompletedAt
+		entry.CompletedAt = &completed
+	}
+	return entry
+}
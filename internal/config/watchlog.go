@@ -0,0 +1,50 @@
+package config
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WatchEvent struct {
+	ID        string    `json:"id"`
+	ShowID    string    `json:"show_id"`
+	Title     string    `json:"title"`
+	Episode   string    `json:"episode"`
+	WatchedAt time.Time `json:"watched_at"`
+	Duration  float64   `json:"duration"`
+}
+
+func watchEventKey(event WatchEvent) string {
+	return event.WatchedAt.UTC().Format("20060102T150405.000000000") + "|" + event.ID + "|" + event.Episode
+}
+
+func writeWatchEvents(tx Tx, events []WatchEvent) error {
+	for _, event := range events {
+		if err := tx.Put(BucketWatchLog, watchEventKey(event), event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func GetWatchEvents(since time.Time) ([]WatchEvent, error) {
+	var events []WatchEvent
+	err := viewRepository(func(tx Tx) error {
+		var decodeErr error
+		err := tx.ForEach(BucketWatchLog, false, func(key string, data []byte) bool {
+			var event WatchEvent
+			if decodeErr = json.Unmarshal(data, &event); decodeErr != nil {
+				return false
+			}
+			if !event.WatchedAt.Before(since) {
+				events = append(events, event)
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		return decodeErr
+	})
+	return events, err
+}
@@ -0,0 +1,140 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/pkg/errors"
+)
+
+type Event string
+
+const (
+	PlayStart        Event = "play_start"
+	EpisodeFinished  Event = "episode_finished"
+	DownloadComplete Event = "download_complete"
+	Error            Event = "error"
+)
+
+var (
+	pending  sync.WaitGroup
+	mu       sync.Mutex
+	failures []error
+)
+
+type Payload struct {
+	Event     Event     `json:"event"`
+	ShowID    string    `json:"show_id,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	Episode   string    `json:"episode,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	FilePath  string    `json:"file_path,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func commandFor(cfg *config.Config, event Event) string {
+	switch event {
+	case PlayStart:
+		return cfg.OnPlayStart
+	case EpisodeFinished:
+		return cfg.OnEpisodeFinished
+	case DownloadComplete:
+		return cfg.OnDownloadComplete
+	case Error:
+		return cfg.OnError
+	default:
+		return ""
+	}
+}
+
+func Run(event Event, payload Payload) error {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.DefaultConfig
+	}
+
+	command := commandFor(cfg, event)
+	if command == "" {
+		return nil
+	}
+
+	payload.Event = event
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, errors.ConfigError, "failed to encode hook payload")
+	}
+
+	timeout := time.Duration(cfg.HookTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = time.Duration(config.DefaultConfig.HookTimeout) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	cmd.Env = append(os.Environ(),
+		"KARU_EVENT="+string(event),
+		"KARU_SHOW_ID="+payload.ShowID,
+		"KARU_TITLE="+payload.Title,
+		"KARU_EPISODE="+payload.Episode,
+		"KARU_URL="+payload.URL,
+		"KARU_FILE_PATH="+payload.FilePath,
+	)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.New(errors.ConfigError, "on_"+string(event)+" hook timed out after "+timeout.String())
+		}
+		return errors.Wrapf(err, errors.ConfigError, "on_%s hook failed", event)
+	}
+
+	return nil
+}
+
+// Fire runs the hook in the background, as it is called from inside
+// TUIs. Call Wait before exiting so the hook is not cut short, and to
+// report its failure once the terminal is free again.
+func Fire(event Event, payload Payload) {
+	pending.Add(1)
+	go func() {
+		defer pending.Done()
+		if err := Run(event, payload); err != nil {
+			mu.Lock()
+			failures = append(failures, err)
+			mu.Unlock()
+		}
+	}()
+}
+
+func Wait() []error {
+	pending.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	errs := failures
+	failures = nil
+	return errs
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
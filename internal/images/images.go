@@ -0,0 +1,170 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/pkg/errors"
+	karuhttp "github.com/keircn/karu/pkg/http"
+	"github.com/keircn/karu/pkg/termimg"
+)
+
+const maxImageSize = 10 << 20
+
+type renderKey struct {
+	url        string
+	cols, rows int
+}
+
+var (
+	mu       sync.Mutex
+	decoded  = make(map[string]image.Image)
+	pending  = make(map[string]bool)
+	failed   = make(map[string]bool)
+	rendered = make(map[renderKey]string)
+
+	client = karuhttp.NewClient(karuhttp.WithTimeout(15 * time.Second))
+)
+
+var protocol = sync.OnceValues(func() (termimg.Protocol, bool) {
+	cfg, err := config.Load()
+	if err != nil {
+		return termimg.Detect(), true
+	}
+	switch cfg.ShowImages {
+	case "off":
+		return termimg.HalfBlocks, false
+	case "", "auto":
+		return termimg.Detect(), true
+	}
+	return termimg.ParseProtocol(cfg.ShowImages)
+})
+
+func Enabled() bool {
+	_, enabled := protocol()
+	return enabled
+}
+
+func Protocol() termimg.Protocol {
+	p, _ := protocol()
+	return p
+}
+
+func Want(url string) bool {
+	if url == "" || !Enabled() {
+		return false
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if decoded[url] != nil || pending[url] || failed[url] {
+		return false
+	}
+	pending[url] = true
+	return true
+}
+
+func Fetch(ctx context.Context, url string) error {
+	img, err := load(ctx, url)
+
+	mu.Lock()
+	defer mu.Unlock()
+	delete(pending, url)
+	if err != nil {
+		failed[url] = true
+		return err
+	}
+	decoded[url] = img
+	return nil
+}
+
+func Render(url string, cols, rows int) string {
+	if url == "" || !Enabled() {
+		return ""
+	}
+	key := renderKey{url, cols, rows}
+
+	mu.Lock()
+	img := decoded[url]
+	out, done := rendered[key]
+	mu.Unlock()
+	if img == nil || done {
+		return out
+	}
+
+	out = termimg.Render(img, Protocol(), cols, rows)
+	mu.Lock()
+	rendered[key] = out
+	mu.Unlock()
+	return out
+}
+
+func load(ctx context.Context, url string) (image.Image, error) {
+	path, err := cachePath(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if img, err := termimg.Decode(bytes.NewReader(data)); err == nil {
+			return img, nil
+		}
+	}
+
+	data, err := download(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	img, err := termimg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// The image is still usable when it cannot be cached.
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err == nil {
+			os.Rename(tmp, path)
+		}
+	}
+	return img, nil
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	resp, err := client.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(errors.NetworkError, fmt.Sprintf("failed to fetch image: status %d", resp.StatusCode))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to read image")
+	}
+	if len(data) > maxImageSize {
+		return nil, errors.New(errors.NetworkError, "image is too large")
+	}
+	return data, nil
+}
+
+func cachePath(url string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, errors.ConfigError, "failed to get cache directory")
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(cacheDir, "karu", "images", hex.EncodeToString(sum[:16])), nil
+}
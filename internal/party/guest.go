@@ -0,0 +1,85 @@
+package party
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/pkg/errors"
+)
+
+type Guest struct {
+	name     string
+	conn     *conn
+	messages chan Message
+}
+
+func Join(addr, name, code string) (*Guest, *Session, []string, error) {
+	if !strings.Contains(addr, ":") {
+		addr = net.JoinHostPort(addr, strconv.Itoa(DefaultPort))
+	}
+
+	raw, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, errors.NetworkError, "failed to connect to %s", addr)
+	}
+
+	c := newConn(raw)
+	if err := c.write(Message{Type: MsgHello, From: name, Code: code}); err != nil {
+		c.close()
+		return nil, nil, nil, errors.Wrap(err, errors.NetworkError, "failed to join party")
+	}
+
+	raw.SetReadDeadline(time.Now().Add(10 * time.Second))
+	welcome, err := c.read()
+	if err == nil && welcome.Type == MsgRejected {
+		c.close()
+		return nil, nil, nil, errors.New(errors.ValidationError, "party host rejected the session code")
+	}
+	if err != nil || welcome.Type != MsgWelcome || welcome.Session == nil {
+		c.close()
+		return nil, nil, nil, errors.New(errors.NetworkError, "party host did not accept the connection")
+	}
+	raw.SetReadDeadline(time.Time{})
+
+	g := &Guest{
+		name:     welcome.From,
+		conn:     c,
+		messages: make(chan Message, 64),
+	}
+
+	go g.readLoop()
+	return g, welcome.Session, welcome.Participants, nil
+}
+
+func (g *Guest) Name() string { return g.name }
+
+func (g *Guest) IsHost() bool { return false }
+
+func (g *Guest) Messages() <-chan Message { return g.messages }
+
+func (g *Guest) Send(msg Message) error {
+	msg.From = g.name
+	return g.conn.write(msg)
+}
+
+func (g *Guest) Close() error {
+	return g.conn.close()
+}
+
+func (g *Guest) readLoop() {
+	defer close(g.messages)
+
+	for {
+		msg, err := g.conn.read()
+		if err != nil {
+			g.messages <- Message{Type: MsgClosed}
+			return
+		}
+		g.messages <- msg
+		if msg.Type == MsgClosed {
+			return
+		}
+	}
+}
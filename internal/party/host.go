@@ -0,0 +1,252 @@
+package party
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keircn/karu/pkg/errors"
+)
+
+// Codes leave out characters that are easily mistaken for one another.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+type Host struct {
+	name     string
+	code     string
+	listener net.Listener
+	messages chan Message
+
+	mu      sync.Mutex
+	clients map[*conn]string
+	session Session
+	closed  bool
+}
+
+func NewHost(addr, name string, session Session) (*Host, error) {
+	code, err := newCode(8)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to generate session code")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, errors.NetworkError, "failed to listen on %s", addr)
+	}
+
+	h := &Host{
+		name:     name,
+		code:     code,
+		listener: listener,
+		messages: make(chan Message, 64),
+		clients:  make(map[*conn]string),
+		session:  session,
+	}
+
+	go h.acceptLoop()
+	return h, nil
+}
+
+func (h *Host) Addr() string {
+	return h.listener.Addr().String()
+}
+
+func (h *Host) Name() string { return h.name }
+
+// Code is the session code guests have to give to join.
+func (h *Host) Code() string { return h.code }
+
+func (h *Host) IsHost() bool { return true }
+
+func (h *Host) Messages() <-chan Message { return h.messages }
+
+func (h *Host) Send(msg Message) error {
+	msg.From = h.name
+
+	h.mu.Lock()
+	switch msg.Type {
+	case MsgState:
+		h.session.Episode = msg.Episode
+		h.session.Position = msg.Position
+		h.session.Paused = msg.Paused
+	case MsgEpisode:
+		h.session.Episode = msg.Episode
+		h.session.Position = 0
+	}
+	h.mu.Unlock()
+
+	h.broadcast(msg, nil)
+	return nil
+}
+
+func (h *Host) Participants() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.participantsLocked()
+}
+
+func (h *Host) participantsLocked() []string {
+	names := make([]string, 0, len(h.clients)+1)
+	for _, name := range h.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{h.name + " (host)"}, names...)
+}
+
+func (h *Host) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	clients := make([]*conn, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range clients {
+		c.write(Message{Type: MsgClosed, From: h.name})
+		c.close()
+	}
+	return h.listener.Close()
+}
+
+func (h *Host) acceptLoop() {
+	for {
+		raw, err := h.listener.Accept()
+		if err != nil {
+			return
+		}
+		go h.handle(newConn(raw))
+	}
+}
+
+func (h *Host) handle(c *conn) {
+	c.raw.SetReadDeadline(time.Now().Add(10 * time.Second))
+	hello, err := c.read()
+	if err != nil || hello.Type != MsgHello || hello.From == "" {
+		c.close()
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(strings.ToUpper(hello.Code)), []byte(h.code)) != 1 {
+		c.write(Message{Type: MsgRejected, Text: "wrong session code"})
+		c.close()
+		return
+	}
+	c.raw.SetReadDeadline(time.Time{})
+
+	h.mu.Lock()
+	name := h.uniqueNameLocked(hello.From)
+	h.clients[c] = name
+	session := h.session
+	participants := h.participantsLocked()
+	h.mu.Unlock()
+
+	if err := c.write(Message{Type: MsgWelcome, From: name, Session: &session, Participants: participants}); err != nil {
+		h.drop(c)
+		return
+	}
+
+	h.notify(fmt.Sprintf("%s joined", name))
+	h.broadcastParticipants()
+
+	for {
+		msg, err := c.read()
+		if err != nil {
+			h.drop(c)
+			return
+		}
+
+		msg.From = name
+		switch msg.Type {
+		case MsgChat:
+			h.broadcast(msg, nil)
+			h.deliver(msg)
+		case MsgControl:
+			h.deliver(msg)
+		}
+	}
+}
+
+func (h *Host) uniqueNameLocked(name string) string {
+	taken := map[string]bool{h.name: true}
+	for _, n := range h.clients {
+		taken[n] = true
+	}
+
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	return candidate
+}
+
+func (h *Host) drop(c *conn) {
+	h.mu.Lock()
+	name, ok := h.clients[c]
+	delete(h.clients, c)
+	closed := h.closed
+	h.mu.Unlock()
+
+	c.close()
+	if ok && !closed {
+		h.notify(fmt.Sprintf("%s left", name))
+		h.broadcastParticipants()
+	}
+}
+
+func (h *Host) notify(text string) {
+	msg := Message{Type: MsgSystem, Text: text}
+	h.broadcast(msg, nil)
+	h.deliver(msg)
+}
+
+func (h *Host) broadcastParticipants() {
+	participants := h.Participants()
+	msg := Message{Type: MsgParticipants, Participants: participants}
+	h.broadcast(msg, nil)
+	h.deliver(msg)
+}
+
+func (h *Host) broadcast(msg Message, except *conn) {
+	h.mu.Lock()
+	clients := make([]*conn, 0, len(h.clients))
+	for c := range h.clients {
+		if c != except {
+			clients = append(clients, c)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, c := range clients {
+		if err := c.write(msg); err != nil {
+			go h.drop(c)
+		}
+	}
+}
+
+func (h *Host) deliver(msg Message) {
+	select {
+	case h.messages <- msg:
+	default:
+	}
+}
+
+func newCode(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	return string(b), nil
+}
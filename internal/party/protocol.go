@@ -0,0 +1,112 @@
+package party
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+)
+
+const DefaultPort = 7879
+
+// A client that cannot take a message within writeTimeout is dropped, so it
+// cannot hold up the others.
+const writeTimeout = 5 * time.Second
+
+type MessageType string
+
+const (
+	MsgHello        MessageType = "hello"
+	MsgWelcome      MessageType = "welcome"
+	MsgRejected     MessageType = "rejected"
+	MsgState        MessageType = "state"
+	MsgEpisode      MessageType = "episode"
+	MsgControl      MessageType = "control"
+	MsgChat         MessageType = "chat"
+	MsgParticipants MessageType = "participants"
+	MsgSystem       MessageType = "system"
+	MsgClosed       MessageType = "closed"
+)
+
+const (
+	ActionPause  = "pause"
+	ActionResume = "resume"
+	ActionSeek   = "seek"
+	ActionNext   = "next"
+	ActionPrev   = "prev"
+)
+
+type Session struct {
+	ShowID    string   `json:"show_id"`
+	ShowTitle string   `json:"show_title"`
+	MalID     string   `json:"mal_id,omitempty"`
+	Episodes  []string `json:"episodes"`
+	Episode   string   `json:"episode"`
+	Position  float64  `json:"position"`
+	Paused    bool     `json:"paused"`
+}
+
+type Message struct {
+	Type         MessageType `json:"type"`
+	From         string      `json:"from,omitempty"`
+	Code         string      `json:"code,omitempty"`
+	Text         string      `json:"text,omitempty"`
+	Action       string      `json:"action,omitempty"`
+	Episode      string      `json:"episode,omitempty"`
+	Position     float64     `json:"position,omitempty"`
+	Paused       bool        `json:"paused,omitempty"`
+	Participants []string    `json:"participants,omitempty"`
+	Session      *Session    `json:"session,omitempty"`
+}
+
+type Peer interface {
+	Name() string
+	IsHost() bool
+	Send(msg Message) error
+	Messages() <-chan Message
+	Close() error
+}
+
+type conn struct {
+	raw     net.Conn
+	encMu   sync.Mutex
+	encoder *json.Encoder
+	scanner *bufio.Scanner
+}
+
+func newConn(raw net.Conn) *conn {
+	scanner := bufio.NewScanner(raw)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &conn{
+		raw:     raw,
+		encoder: json.NewEncoder(raw),
+		scanner: scanner,
+	}
+}
+
+func (c *conn) write(msg Message) error {
+	c.encMu.Lock()
+	defer c.encMu.Unlock()
+	c.raw.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.encoder.Encode(msg)
+}
+
+func (c *conn) read() (Message, error) {
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return Message{}, err
+		}
+		return Message{}, net.ErrClosed
+	}
+
+	var msg Message
+	if err := json.Unmarshal(c.scanner.Bytes(), &msg); err != nil {
+		return Message{}, err
+	}
+	return msg, nil
+}
+
+func (c *conn) close() error {
+	return c.raw.Close()
+}
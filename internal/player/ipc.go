@@ -0,0 +1,162 @@
+package player
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type ipcRequest struct {
+	Command   []any `json:"command"`
+	RequestID int   `json:"request_id"`
+}
+
+type ipcResponse struct {
+	Data      json.RawMessage `json:"data"`
+	Error     string          `json:"error"`
+	RequestID int             `json:"request_id"`
+	Event     string          `json:"event"`
+}
+
+type mpvIPC struct {
+	path      string
+	mu        sync.Mutex
+	conn      net.Conn
+	reader    *bufio.Reader
+	requestID int
+}
+
+func newIPCPath() string {
+	return ipcSocketPath(fmt.Sprintf("karu-mpv-%d-%d", os.Getpid(), time.Now().UnixNano()))
+}
+
+func newMpvIPC(path string) *mpvIPC {
+	return &mpvIPC{path: path}
+}
+
+func (c *mpvIPC) connect() error {
+	if c.conn != nil {
+		return nil
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 20; attempt++ {
+		conn, err := dialIPC(c.path)
+		if err == nil {
+			c.conn = conn
+			c.reader = bufio.NewReader(conn)
+			return nil
+		}
+		lastErr = err
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return fmt.Errorf("failed to connect to mpv IPC socket: %w", lastErr)
+}
+
+func (c *mpvIPC) Command(args ...any) (json.RawMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+
+	c.requestID++
+	payload, err := json.Marshal(ipcRequest{Command: args, RequestID: c.requestID})
+	if err != nil {
+		return nil, err
+	}
+
+	c.conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := c.conn.Write(append(payload, '\n')); err != nil {
+		c.closeLocked()
+		return nil, err
+	}
+
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		if err != nil {
+			c.closeLocked()
+			return nil, err
+		}
+
+		var resp ipcResponse
+		if err := json.Unmarshal(line, &resp); err != nil {
+			continue
+		}
+		if resp.Event != "" || resp.RequestID != c.requestID {
+			continue
+		}
+		if resp.Error != "" && resp.Error != "success" {
+			return nil, fmt.Errorf("mpv: %s", resp.Error)
+		}
+		return resp.Data, nil
+	}
+}
+
+func (c *mpvIPC) GetFloat(property string) (float64, error) {
+	data, err := c.Command("get_property", property)
+	if err != nil {
+		return 0, err
+	}
+
+	var value float64
+	if err := json.Unmarshal(data, &value); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+func (c *mpvIPC) GetBool(property string) (bool, error) {
+	data, err := c.Command("get_property", property)
+	if err != nil {
+		return false, err
+	}
+
+	var value bool
+	if err := json.Unmarshal(data, &value); err != nil {
+		return false, err
+	}
+	return value, nil
+}
+
+func (c *mpvIPC) SetProperty(property string, value any) error {
+	_, err := c.Command("set_property", property, value)
+	return err
+}
+
+func (c *mpvIPC) Seek(position float64) error {
+	_, err := c.Command("seek", position, "absolute")
+	return err
+}
+
+func (c *mpvIPC) ShowText(text string) error {
+	_, err := c.Command("show-text", text)
+	return err
+}
+
+func (c *mpvIPC) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeLocked()
+}
+
+func (c *mpvIPC) closeLocked() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+		c.reader = nil
+	}
+}
+
+func removeIPCPath(path string) {
+	if filepath.IsAbs(path) {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package player
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+func ipcSocketPath(name string) string {
+	return filepath.Join(os.TempDir(), name+".sock")
+}
+
+func dialIPC(path string) (net.Conn, error) {
+	return net.Dial("unix", path)
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package player
+
+import (
+	"fmt"
+	"net"
+)
+
+func ipcSocketPath(name string) string {
+	return `\\.\pipe\` + name
+}
+
+func dialIPC(path string) (net.Conn, error) {
+	return nil, fmt.Errorf("mpv IPC over named pipes is not supported on Windows")
+}
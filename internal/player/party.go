@@ -0,0 +1,619 @@
+package player
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/keircn/karu/internal/aniskip"
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/hooks"
+	"github.com/keircn/karu/internal/party"
+	"github.com/keircn/karu/internal/proxy"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+const DefaultSyncTolerance = 2.0
+
+type PartyOptions struct {
+	Peer         party.Peer
+	Session      party.Session
+	Participants []string
+	Tolerance    float64
+	GetVideoURL  func(showID, episode string) (string, error)
+}
+
+type partyModel struct {
+	opts         PartyOptions
+	session      party.Session
+	participants []string
+	chat         []string
+	input        textinput.Model
+	typing       bool
+	status       string
+	loading      bool
+	quitting     bool
+
+	process   *exec.Cmd
+	ipc       *mpvIPC
+	cleanup   func()
+	skipTimes []aniskip.Interval
+	proxy     *proxy.Server
+	videoURL  string
+}
+
+type partyMessageMsg struct{ msg party.Message }
+type partyTickMsg struct{}
+type partyStatusMsg string
+
+type partyStartedMsg struct {
+	episode   string
+	videoURL  string
+	process   *exec.Cmd
+	ipc       *mpvIPC
+	cleanup   func()
+	skipTimes []aniskip.Interval
+	seekTo    float64
+}
+
+type partyEndedMsg struct {
+	process *exec.Cmd
+}
+
+type partyPositionMsg struct {
+	position float64
+	paused   bool
+}
+
+type partyErrorMsg struct{ err error }
+
+func partyPanelStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.CurrentTheme().Subtle).
+		Padding(0, 1)
+}
+
+func chatNameStyle() lipgloss.Style {
+	return ui.AccentStyle.Bold(true)
+}
+
+func systemStyle() lipgloss.Style {
+	return ui.MutedStyle.Italic(true)
+}
+
+func PlayParty(opts PartyOptions) error {
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = DefaultSyncTolerance
+	}
+
+	input := textinput.New()
+	input.Placeholder = "Say something..."
+	input.CharLimit = 280
+	input.Width = 50
+
+	m := &partyModel{
+		opts:         opts,
+		session:      opts.Session,
+		participants: opts.Participants,
+		input:        input,
+		status:       "Connecting...",
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.DefaultConfig
+	}
+	srv, err := startProxy(cfg)
+	if err != nil {
+		return err
+	}
+	if srv != nil {
+		defer srv.Close()
+		m.proxy = srv
+	}
+
+	_, err = tea.NewProgram(m).Run()
+	m.stopPlayback()
+	opts.Peer.Close()
+	return err
+}
+
+func (m *partyModel) Init() tea.Cmd {
+	return tea.Batch(
+		m.listen(),
+		partyTick(),
+		m.load(m.session.Episode, m.session.Position),
+	)
+}
+
+func (m *partyModel) listen() tea.Cmd {
+	messages := m.opts.Peer.Messages()
+	return func() tea.Msg {
+		msg, ok := <-messages
+		if !ok {
+			return partyMessageMsg{msg: party.Message{Type: party.MsgClosed}}
+		}
+		return partyMessageMsg{msg: msg}
+	}
+}
+
+func partyTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return partyTickMsg{} })
+}
+
+func (m *partyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.typing {
+			return m.updateTyping(msg)
+		}
+		return m.updateControls(msg)
+
+	case partyMessageMsg:
+		cmd := m.handlePeerMessage(msg.msg)
+		if m.quitting {
+			return m, tea.Quit
+		}
+		return m, tea.Batch(cmd, m.listen())
+
+	case partyTickMsg:
+		return m, tea.Batch(m.broadcastState(), partyTick())
+
+	case partyStartedMsg:
+		if msg.episode != m.session.Episode || m.quitting {
+			if msg.process != nil && msg.process.Process != nil {
+				msg.process.Process.Kill()
+			}
+			if msg.cleanup != nil {
+				msg.cleanup()
+			}
+			return m, nil
+		}
+		m.loading = false
+		m.process = msg.process
+		m.ipc = msg.ipc
+		m.cleanup = msg.cleanup
+		m.skipTimes = msg.skipTimes
+		m.videoURL = msg.videoURL
+		m.status = fmt.Sprintf("Playing episode %s", msg.episode)
+		hooks.Fire(hooks.PlayStart, m.hookPayload())
+		return m, tea.Batch(waitForExit(msg.process), m.seekAfterStart(msg.seekTo))
+
+	case partyEndedMsg:
+		if msg.process != m.process || m.quitting {
+			return m, nil
+		}
+		m.process = nil
+		m.recordProgress()
+		hooks.Fire(hooks.EpisodeFinished, m.hookPayload())
+		if m.opts.Peer.IsHost() {
+			if next, ok := m.adjacentEpisode(1); ok {
+				return m, m.changeEpisode(next)
+			}
+			m.status = "Reached the end of the queue"
+			return m, nil
+		}
+		m.status = "Episode finished, waiting for host..."
+		return m, nil
+
+	case partyPositionMsg:
+		m.session.Position = msg.position
+		m.session.Paused = msg.paused
+
+	case partyStatusMsg:
+		m.status = string(msg)
+
+	case partyErrorMsg:
+		m.loading = false
+		m.status = fmt.Sprintf("Error: %v", msg.err)
+		payload := m.hookPayload()
+		payload.Error = msg.err.Error()
+		hooks.Fire(hooks.Error, payload)
+	}
+
+	return m, nil
+}
+
+func (m *partyModel) updateTyping(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		text := strings.TrimSpace(m.input.Value())
+		m.input.Reset()
+		m.input.Blur()
+		m.typing = false
+		if text == "" {
+			return m, nil
+		}
+		chat := party.Message{Type: party.MsgChat, Text: text}
+		if m.opts.Peer.IsHost() {
+			m.appendChat(m.opts.Peer.Name(), text)
+		}
+		return m, m.send(chat)
+	case tea.KeyEsc:
+		m.input.Reset()
+		m.input.Blur()
+		m.typing = false
+		return m, nil
+	case tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *partyModel) updateControls(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := ui.Keys.Party
+	switch {
+	case msg.String() == ui.ForceQuitKey || key.Matches(msg, keys.Quit):
+		m.quitting = true
+		return m, tea.Quit
+	case key.Matches(msg, keys.Chat):
+		m.typing = true
+		return m, m.input.Focus()
+	case key.Matches(msg, keys.Pause):
+		if m.session.Paused {
+			return m, m.control(party.ActionResume, 0)
+		}
+		return m, m.control(party.ActionPause, 0)
+	case key.Matches(msg, keys.SeekBack):
+		return m, m.control(party.ActionSeek, math.Max(0, m.session.Position-10))
+	case key.Matches(msg, keys.SeekForward):
+		return m, m.control(party.ActionSeek, m.session.Position+10)
+	case key.Matches(msg, keys.Next):
+		return m, m.control(party.ActionNext, 0)
+	case key.Matches(msg, keys.Prev):
+		return m, m.control(party.ActionPrev, 0)
+	case key.Matches(msg, keys.Skip):
+		return m, m.skip()
+	}
+	return m, nil
+}
+
+func (m *partyModel) control(action string, position float64) tea.Cmd {
+	if m.opts.Peer.IsHost() {
+		return m.applyControl(party.Message{Type: party.MsgControl, Action: action, Position: position})
+	}
+	return m.send(party.Message{Type: party.MsgControl, Action: action, Position: position})
+}
+
+func (m *partyModel) applyControl(msg party.Message) tea.Cmd {
+	switch msg.Action {
+	case party.ActionPause, party.ActionResume:
+		paused := msg.Action == party.ActionPause
+		m.session.Paused = paused
+		ipc := m.ipc
+		return func() tea.Msg {
+			if ipc != nil {
+				ipc.SetProperty("pause", paused)
+			}
+			return nil
+		}
+	case party.ActionSeek:
+		m.session.Position = msg.Position
+		ipc := m.ipc
+		position := msg.Position
+		return func() tea.Msg {
+			if ipc != nil {
+				ipc.Seek(position)
+			}
+			return nil
+		}
+	case party.ActionNext, party.ActionPrev:
+		offset := 1
+		if msg.Action == party.ActionPrev {
+			offset = -1
+		}
+		if episode, ok := m.adjacentEpisode(offset); ok {
+			return m.changeEpisode(episode)
+		}
+		m.status = "No more episodes in that direction"
+	}
+	return nil
+}
+
+func (m *partyModel) handlePeerMessage(msg party.Message) tea.Cmd {
+	switch msg.Type {
+	case party.MsgChat:
+		m.appendChat(msg.From, msg.Text)
+	case party.MsgSystem:
+		m.chat = append(m.chat, systemStyle().Render(msg.Text))
+	case party.MsgParticipants:
+		m.participants = msg.Participants
+	case party.MsgClosed:
+		if !m.opts.Peer.IsHost() {
+			m.status = "The host ended the party"
+			m.quitting = true
+		}
+	case party.MsgControl:
+		if m.opts.Peer.IsHost() {
+			m.chat = append(m.chat, systemStyle().Render(fmt.Sprintf("%s: %s", msg.From, msg.Action)))
+			return m.applyControl(msg)
+		}
+	case party.MsgEpisode:
+		if !m.opts.Peer.IsHost() && msg.Episode != m.session.Episode {
+			m.session.Episode = msg.Episode
+			m.session.Position = 0
+			return m.load(msg.Episode, 0)
+		}
+	case party.MsgState:
+		if !m.opts.Peer.IsHost() {
+			return m.applyState(msg)
+		}
+	}
+	return nil
+}
+
+func (m *partyModel) applyState(state party.Message) tea.Cmd {
+	if state.Episode != "" && state.Episode != m.session.Episode {
+		m.session.Episode = state.Episode
+		m.session.Position = state.Position
+		m.session.Paused = state.Paused
+		return m.load(state.Episode, state.Position)
+	}
+
+	m.session.Position = state.Position
+	m.session.Paused = state.Paused
+
+	if m.loading || m.ipc == nil {
+		return nil
+	}
+
+	ipc := m.ipc
+	tolerance := m.opts.Tolerance
+	return func() tea.Msg {
+		if paused, err := ipc.GetBool("pause"); err == nil && paused != state.Paused {
+			ipc.SetProperty("pause", state.Paused)
+		}
+		if position, err := ipc.GetFloat("time-pos"); err == nil && math.Abs(position-state.Position) > tolerance {
+			ipc.Seek(state.Position)
+			return partyStatusMsg(fmt.Sprintf("Resynced to %s", formatPosition(state.Position)))
+		}
+		return nil
+	}
+}
+
+func (m *partyModel) broadcastState() tea.Cmd {
+	if !m.opts.Peer.IsHost() || m.ipc == nil || m.loading {
+		return nil
+	}
+
+	ipc := m.ipc
+	peer := m.opts.Peer
+	episode := m.session.Episode
+	return func() tea.Msg {
+		position, err := ipc.GetFloat("time-pos")
+		if err != nil {
+			return nil
+		}
+		paused, _ := ipc.GetBool("pause")
+		peer.Send(party.Message{Type: party.MsgState, Episode: episode, Position: position, Paused: paused})
+		return partyPositionMsg{position: position, paused: paused}
+	}
+}
+
+func (m *partyModel) changeEpisode(episode string) tea.Cmd {
+	m.session.Episode = episode
+	m.session.Position = 0
+	m.session.Paused = false
+	m.opts.Peer.Send(party.Message{Type: party.MsgEpisode, Episode: episode})
+	return m.load(episode, 0)
+}
+
+func (m *partyModel) load(episode string, seekTo float64) tea.Cmd {
+	m.stopPlayback()
+	m.loading = true
+	m.status = fmt.Sprintf("Loading episode %s...", episode)
+
+	showID := m.session.ShowID
+	malID := m.session.MalID
+	getVideoURL := m.opts.GetVideoURL
+	srv := m.proxy
+	return func() tea.Msg {
+		videoURL, err := getVideoURL(showID, episode)
+		if err != nil {
+			return partyErrorMsg{err: fmt.Errorf("loading episode %s: %w", episode, err)}
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			cfg = &config.DefaultConfig
+		}
+		if !isMPV(cfg.Player) {
+			return partyErrorMsg{err: fmt.Errorf("watch parties require mpv, configured player is %s", cfg.Player)}
+		}
+
+		skipTimes := fetchSkipTimes(cfg, malID, episode)
+		args, cleanup := skipArgs(cfg, skipTimes)
+
+		ipcPath := newIPCPath()
+		args = append(args, "--input-ipc-server="+ipcPath)
+		process, err := startVideoProcess(proxiedURL(srv, videoURL), cfg, args...)
+		if err != nil {
+			cleanup()
+			return partyErrorMsg{err: err}
+		}
+
+		return partyStartedMsg{
+			episode:  episode,
+			videoURL: videoURL,
+			process:  process,
+			ipc:      newMpvIPC(ipcPath),
+			cleanup: func() {
+				cleanup()
+				removeIPCPath(ipcPath)
+			},
+			skipTimes: skipTimes,
+			seekTo:    seekTo,
+		}
+	}
+}
+
+func (m *partyModel) seekAfterStart(position float64) tea.Cmd {
+	if position <= 0 {
+		return nil
+	}
+	ipc := m.ipc
+	return func() tea.Msg {
+		if err := ipc.Seek(position); err != nil {
+			return partyStatusMsg(fmt.Sprintf("Could not seek to %s: %v", formatPosition(position), err))
+		}
+		return nil
+	}
+}
+
+func waitForExit(process *exec.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		process.Wait()
+		return partyEndedMsg{process: process}
+	}
+}
+
+func (m *partyModel) skip() tea.Cmd {
+	if m.ipc == nil {
+		return nil
+	}
+	interval, ok := aniskip.Find(m.skipTimes, m.session.Position)
+	if !ok {
+		m.status = "Nothing to skip here"
+		return nil
+	}
+	return m.control(party.ActionSeek, interval.End)
+}
+
+func (m *partyModel) send(msg party.Message) tea.Cmd {
+	peer := m.opts.Peer
+	return func() tea.Msg {
+		if err := peer.Send(msg); err != nil {
+			return partyErrorMsg{err: err}
+		}
+		return nil
+	}
+}
+
+func (m *partyModel) stopPlayback() {
+	if m.process != nil && m.process.Process != nil {
+		m.process.Process.Kill()
+	}
+	m.process = nil
+	if m.ipc != nil {
+		m.ipc.Close()
+		m.ipc = nil
+	}
+	if m.cleanup != nil {
+		m.cleanup()
+		m.cleanup = nil
+	}
+}
+
+func (m *partyModel) adjacentEpisode(offset int) (string, bool) {
+	index := findEpisodeIndex(m.session.Episodes, m.session.Episode)
+	target := index + offset
+	if index == -1 || target < 0 || target >= len(m.session.Episodes) {
+		return "", false
+	}
+	return m.session.Episodes[target], true
+}
+
+func (m *partyModel) recordProgress() {
+	if m.session.ShowID == "" || m.session.Episode == "" {
+		return
+	}
+	RecordProgress(m.session.ShowID, m.session.Episode, 0, 0, true)
+}
+
+func (m *partyModel) hookPayload() hooks.Payload {
+	return hooks.Payload{
+		ShowID:  m.session.ShowID,
+		Title:   m.session.ShowTitle,
+		Episode: m.session.Episode,
+		URL:     m.videoURL,
+	}
+}
+
+func (m *partyModel) appendChat(from, text string) {
+	m.chat = append(m.chat, fmt.Sprintf("%s %s", chatNameStyle().Render(from+":"), text))
+	if len(m.chat) > 100 {
+		m.chat = m.chat[len(m.chat)-100:]
+	}
+}
+
+func (m *partyModel) View() string {
+	if m.quitting {
+		return titleStyle().Render("Left the watch party")
+	}
+
+	role := "Guest"
+	if m.opts.Peer.IsHost() {
+		role = "Host"
+	}
+
+	title := titleStyle().Render(fmt.Sprintf("Karu Watch Party (%s)", role))
+
+	index := findEpisodeIndex(m.session.Episodes, m.session.Episode)
+	state := "playing"
+	if m.session.Paused {
+		state = "paused"
+	}
+	episode := fmt.Sprintf("%s - Episode: %s (%d/%d) • %s %s",
+		m.session.ShowTitle,
+		episodeStyle().Render(m.session.Episode),
+		index+1,
+		len(m.session.Episodes),
+		state,
+		formatPosition(m.session.Position))
+
+	participants := "Participants\n"
+	for _, name := range m.participants {
+		participants += "  " + name + "\n"
+	}
+
+	queue := "Up next\n"
+	if index >= 0 && index+1 < len(m.session.Episodes) {
+		upcoming := m.session.Episodes[index+1:]
+		if len(upcoming) > 5 {
+			upcoming = upcoming[:5]
+		}
+		for _, ep := range upcoming {
+			queue += "  Episode " + ep + "\n"
+		}
+	} else {
+		queue += "  (end of queue)\n"
+	}
+
+	panels := lipgloss.JoinHorizontal(lipgloss.Top,
+		partyPanelStyle().Render(strings.TrimRight(participants, "\n")),
+		partyPanelStyle().Render(strings.TrimRight(queue, "\n")))
+
+	chatLines := m.chat
+	if len(chatLines) > 8 {
+		chatLines = chatLines[len(chatLines)-8:]
+	}
+	chat := strings.Join(chatLines, "\n")
+	if chat == "" {
+		chat = systemStyle().Render("No messages yet")
+	}
+
+	keys := ui.Keys.Party
+	inputLine := helpStyle().Render(ui.HelpLine(keys.Chat, keys.Pause, keys.SeekBack, keys.SeekForward, keys.Next, keys.Prev, keys.Skip, keys.Quit))
+	if m.typing {
+		inputLine = m.input.View()
+	}
+
+	return fmt.Sprintf("%s\n\n%s\n%s\n\n%s\n\n%s\n\n%s\n",
+		title, episode, statusStyle().Render(m.status), panels, chat, inputLine)
+}
+
+func formatPosition(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%02d:%02d", total/60, total%60)
+}
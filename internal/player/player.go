@@ -8,9 +8,15 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/keircn/karu/internal/anilist"
+	"github.com/keircn/karu/internal/aniskip"
 	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/hooks"
+	"github.com/keircn/karu/internal/proxy"
+	"github.com/keircn/karu/pkg/ui"
 	"github.com/keircn/karu/pkg/validation"
 )
 
@@ -20,9 +26,13 @@ type PlaybackInfo struct {
 	Episodes  []string
 	Current   string
 	VideoURL  string
+	MalID     string
+	// Playlist plays every episode in Episodes in turn, whatever the
+	// auto_play_next setting.
+	Playlist bool
 }
 
-type model struct {
+type Session struct {
 	currentEpisode  int
 	episodes        []string
 	showID          string
@@ -34,31 +44,36 @@ type model struct {
 	showHelp        bool
 	quitting        bool
 	currentProcess  *exec.Cmd
-	program         *tea.Program
+	send            func(tea.Msg)
+	malID           string
+	skipTimes       []aniskip.Interval
+	ipc             *mpvIPC
+	tracker         *progressTracker
+	cleanup         func()
+	proxy           *proxy.Server
+	currentURL      string
 }
 
+type FinishedMsg struct{}
+
 type playNextMsg struct{}
 type playPrevMsg struct{}
 
-var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205")).
-			MarginBottom(1)
+func titleStyle() lipgloss.Style {
+	return ui.AccentStyle.Bold(true).MarginBottom(1)
+}
 
-	statusStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("86")).
-			MarginBottom(1)
+func statusStyle() lipgloss.Style {
+	return ui.StatusStyle.MarginBottom(1)
+}
 
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("244")).
-			MarginTop(1).
-			PaddingLeft(2)
+func helpStyle() lipgloss.Style {
+	return ui.MutedStyle.MarginTop(1).PaddingLeft(2)
+}
 
-	episodeStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("86"))
-)
+func episodeStyle() lipgloss.Style {
+	return ui.StatusStyle.Bold(true)
+}
 
 func Play(videoURL string) error {
 	if err := validation.ValidateURL(videoURL); err != nil {
@@ -74,6 +89,19 @@ func Play(videoURL string) error {
 		return err
 	}
 
+	srv, err := startProxy(cfg)
+	if err != nil {
+		return err
+	}
+	originalURL := videoURL
+	if srv != nil {
+		defer srv.Close()
+		videoURL = srv.URL(videoURL)
+	}
+
+	payload := hooks.Payload{URL: originalURL}
+	hooks.Fire(hooks.PlayStart, payload)
+
 	args := []string{videoURL}
 	if cfg.PlayerArgs != "" {
 		playerArgs := strings.Fields(cfg.PlayerArgs)
@@ -92,18 +120,36 @@ func Play(videoURL string) error {
 				fallbackCmd.Stdout = nil
 				fallbackCmd.Stderr = nil
 				if fallbackErr := fallbackCmd.Run(); fallbackErr == nil {
-					cfg.Player = fallbackPlayer
-					config.Save(cfg)
+					cfg.Set("player", fallbackPlayer)
+					hooks.Run(hooks.EpisodeFinished, payload)
 					return nil
 				}
 			}
 		}
-		return formatPlayerError(err, cfg.Player)
+		playerErr := formatPlayerError(err, cfg.Player)
+		payload.Error = playerErr.Error()
+		hooks.Run(hooks.Error, payload)
+		return playerErr
 	}
+
+	hooks.Run(hooks.EpisodeFinished, payload)
 	return nil
 }
 
 func PlayWithAutoNext(info *PlaybackInfo, getVideoURLFunc func(showID, episode string) (string, error)) error {
+	session, err := NewSession(info, getVideoURLFunc)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	p := tea.NewProgram(standaloneSession{session})
+	session.Start(p.Send)
+	_, err = p.Run()
+	return err
+}
+
+func NewSession(info *PlaybackInfo, getVideoURLFunc func(showID, episode string) (string, error)) (*Session, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		cfg = &config.DefaultConfig
@@ -111,175 +157,272 @@ func PlayWithAutoNext(info *PlaybackInfo, getVideoURLFunc func(showID, episode s
 
 	currentIndex := findEpisodeIndex(info.Episodes, info.Current)
 	if currentIndex == -1 {
-		return fmt.Errorf("current episode not found in episode list")
+		return nil, fmt.Errorf("current episode not found in episode list")
+	}
+
+	srv, err := startProxy(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	m := model{
+	return &Session{
 		currentEpisode:  currentIndex,
 		episodes:        info.Episodes,
 		showID:          info.ShowID,
 		showTitle:       info.ShowTitle,
 		getVideoURLFunc: getVideoURLFunc,
 		initialVideoURL: info.VideoURL,
-		status:          fmt.Sprintf("Playing episode %s", info.Episodes[currentIndex]),
-		autoPlay:        cfg.AutoPlayNext,
-		showHelp:        false,
-		quitting:        false,
+		status:          fmt.Sprintf("Loading episode %s...", info.Episodes[currentIndex]),
+		autoPlay:        cfg.AutoPlayNext || info.Playlist,
+		malID:           info.MalID,
+		proxy:           srv,
+	}, nil
+}
+
+func (m *Session) Start(send func(tea.Msg)) {
+	m.send = send
+	go m.play(m.currentEpisode, m.initialVideoURL)
+}
+
+func (m *Session) Close() {
+	m.quitting = true
+	m.killCurrentProcess()
+	if m.proxy != nil {
+		m.proxy.Close()
+		m.proxy = nil
 	}
+}
 
-	p := tea.NewProgram(&m)
-	m.program = p
+func (m *Session) Quitting() bool {
+	return m.quitting
+}
 
-	go func() {
-		cmd, err := startVideoProcess(info.VideoURL, cfg)
-		if err != nil {
-			m.status = fmt.Sprintf("Player error: %v", err)
-			return
-		}
-		m.currentProcess = cmd
+func (m *Session) Episode() string {
+	return m.episodes[m.currentEpisode]
+}
 
-		err = cmd.Wait()
+func (m *Session) play(index int, videoURL string) {
+	episode := m.episodes[index]
+	if videoURL == "" {
+		var err error
+		videoURL, err = m.getVideoURLFunc(m.showID, episode)
 		if err != nil {
+			m.fail("Error loading episode", err)
 			return
 		}
+	}
 
-		m.updateWatchHistory()
+	cfg, _ := config.Load()
+	cmd, err := m.startEpisode(videoURL, episode, cfg)
+	if err != nil {
+		m.fail("Player error", err)
+		return
+	}
+	m.currentProcess = cmd
+	m.status = fmt.Sprintf("Playing episode %s", episode)
 
-		if m.autoPlay && m.currentEpisode < len(m.episodes)-1 && !m.quitting {
-			m.program.Send(playNextMsg{})
-		} else if !m.quitting {
-			m.program.Quit()
+	if err := cmd.Wait(); err != nil {
+		if m.currentProcess == cmd && !m.quitting {
+			m.status = fmt.Sprintf("Player error: %v", err)
 		}
-	}()
-	_, err = p.Run()
-	return err
+		return
+	}
+
+	m.updateWatchHistory()
+
+	switch {
+	case m.quitting:
+	case m.autoPlay && index < len(m.episodes)-1:
+		m.send(playNextMsg{})
+	default:
+		m.status = fmt.Sprintf("Finished episode %s", episode)
+		m.send(FinishedMsg{})
+	}
+}
+
+type standaloneSession struct {
+	*Session
+}
+
+func (s standaloneSession) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(FinishedMsg); ok {
+		return s, tea.Quit
+	}
+	_, cmd := s.Session.Update(msg)
+	return s, cmd
 }
 
-func (m *model) Init() tea.Cmd {
+func (m *Session) Init() tea.Cmd {
 	return nil
 }
 
-func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m *Session) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		switch {
+		case msg.String() == ui.ForceQuitKey || key.Matches(msg, ui.Keys.Player.Quit):
 			m.killCurrentProcess()
 			m.quitting = true
-			return m, tea.Quit
-		case "n":
+			return m, func() tea.Msg { return FinishedMsg{} }
+		case key.Matches(msg, ui.Keys.Player.Next):
 			if m.currentEpisode < len(m.episodes)-1 {
 				return m, func() tea.Msg { return playNextMsg{} }
 			} else {
 				m.status = "Already at last episode"
 			}
-		case "p":
+		case key.Matches(msg, ui.Keys.Player.Prev):
 			if m.currentEpisode > 0 {
 				return m, func() tea.Msg { return playPrevMsg{} }
 			} else {
 				m.status = "Already at first episode"
 			}
-		case "h", "?":
+		case key.Matches(msg, ui.Keys.Player.Skip):
+			m.skipCurrentSegment()
+		case key.Matches(msg, ui.Keys.Player.Help):
 			m.showHelp = !m.showHelp
 		}
 
 	case playNextMsg:
 		if m.currentEpisode < len(m.episodes)-1 {
-			m.killCurrentProcess()
-
-			m.currentEpisode++
-			episode := m.episodes[m.currentEpisode]
-			m.status = fmt.Sprintf("Loading episode %s...", episode)
+			m.switchEpisode(m.currentEpisode + 1)
+		}
 
-			go func() {
-				videoURL, err := m.getVideoURLFunc(m.showID, episode)
-				if err != nil {
-					m.status = fmt.Sprintf("Error loading episode: %v", err)
-					return
-				}
+	case playPrevMsg:
+		if m.currentEpisode > 0 {
+			m.switchEpisode(m.currentEpisode - 1)
+		}
+	}
 
-				cfg, _ := config.Load()
-				cmd, err := startVideoProcess(videoURL, cfg)
-				if err != nil {
-					m.status = fmt.Sprintf("Player error: %v", err)
-					return
-				}
-				m.currentProcess = cmd
-				m.status = fmt.Sprintf("Playing episode %s", episode)
+	return m, nil
+}
 
-				err = cmd.Wait()
-				if err != nil && !m.quitting {
-					m.status = fmt.Sprintf("Player error: %v", err)
-					return
-				}
+func (m *Session) switchEpisode(index int) {
+	m.killCurrentProcess()
 
-				m.updateWatchHistory()
+	m.currentEpisode = index
+	m.status = fmt.Sprintf("Loading episode %s...", m.episodes[index])
+	go m.play(index, "")
+}
 
-				if m.autoPlay && m.currentEpisode < len(m.episodes)-1 && !m.quitting {
-					m.program.Send(playNextMsg{})
-				} else if !m.quitting {
-					m.program.Quit()
-				}
-			}()
+func (m *Session) startEpisode(videoURL, episode string, cfg *config.Config) (*exec.Cmd, error) {
+	m.closeSession()
+
+	m.skipTimes = fetchSkipTimes(cfg, m.malID, episode)
+	args, cleanup := skipArgs(cfg, m.skipTimes)
+	m.cleanup = cleanup
+	args = append(args, resumeArgs(cfg, m.showID, episode)...)
+
+	if isMPV(cfg.Player) {
+		ipcPath := newIPCPath()
+		args = append(args, "--input-ipc-server="+ipcPath)
+		m.ipc = newMpvIPC(ipcPath)
+		m.tracker = trackProgress(m.ipc)
+		previous := m.cleanup
+		m.cleanup = func() {
+			previous()
+			removeIPCPath(ipcPath)
 		}
+	}
 
-	case playPrevMsg:
-		if m.currentEpisode > 0 {
-			m.killCurrentProcess()
+	m.currentURL = videoURL
+	hooks.Fire(hooks.PlayStart, m.hookPayload())
 
-			m.currentEpisode--
-			episode := m.episodes[m.currentEpisode]
-			m.status = fmt.Sprintf("Loading episode %s...", episode)
+	return startVideoProcess(proxiedURL(m.proxy, videoURL), cfg, args...)
+}
 
-			go func() {
-				videoURL, err := m.getVideoURLFunc(m.showID, episode)
-				if err != nil {
-					m.status = fmt.Sprintf("Error loading episode: %v", err)
-					return
-				}
+func (m *Session) hookPayload() hooks.Payload {
+	payload := hooks.Payload{
+		ShowID: m.showID,
+		Title:  m.showTitle,
+		URL:    m.currentURL,
+	}
+	if m.currentEpisode < len(m.episodes) {
+		payload.Episode = m.episodes[m.currentEpisode]
+	}
+	return payload
+}
 
-				cfg, _ := config.Load()
-				cmd, err := startVideoProcess(videoURL, cfg)
-				if err != nil {
-					m.status = fmt.Sprintf("Player error: %v", err)
-					return
-				}
-				m.currentProcess = cmd
-				m.status = fmt.Sprintf("Playing episode %s", episode)
+func (m *Session) fail(context string, err error) {
+	m.status = fmt.Sprintf("%s: %v", context, err)
 
-				err = cmd.Wait()
-				if err != nil && !m.quitting {
-					m.status = fmt.Sprintf("Player error: %v", err)
-				}
+	payload := m.hookPayload()
+	payload.Error = err.Error()
+	hooks.Fire(hooks.Error, payload)
+}
 
-				m.updateWatchHistory()
-			}()
-		}
+func (m *Session) closeSession() {
+	if m.tracker != nil {
+		m.tracker.Stop()
+		m.tracker = nil
+	}
+	if m.ipc != nil {
+		m.ipc.Close()
+		m.ipc = nil
+	}
+	if m.cleanup != nil {
+		m.cleanup()
+		m.cleanup = nil
 	}
-
-	return m, nil
 }
 
-func (m *model) updateWatchHistory() {
-	if m.showTitle == "" {
+func (m *Session) skipCurrentSegment() {
+	if m.ipc == nil {
+		m.status = "Skipping requires mpv"
 		return
 	}
 
-	history, err := config.LoadHistory()
+	position, err := m.ipc.GetFloat("time-pos")
 	if err != nil {
+		m.status = fmt.Sprintf("Skip failed: %v", err)
+		return
+	}
+
+	interval, ok := aniskip.Find(m.skipTimes, position)
+	if !ok {
+		if len(m.skipTimes) == 0 {
+			m.status = "No skip data for this episode"
+		} else {
+			m.status = "Nothing to skip here"
+		}
+		return
+	}
+
+	if err := m.ipc.Seek(interval.End); err != nil {
+		m.status = fmt.Sprintf("Skip failed: %v", err)
 		return
 	}
+	m.ipc.ShowText("Skipped " + strings.ToLower(interval.Label()))
+	m.status = fmt.Sprintf("Skipped %s", strings.ToLower(interval.Label()))
+}
 
-	episodeNum := m.currentEpisode + 1
-	history.UpdateProgress(m.showTitle, episodeNum)
+func (m *Session) updateWatchHistory() {
+	hooks.Fire(hooks.EpisodeFinished, m.hookPayload())
+	m.saveProgress(true)
+
+	if err := anilist.SyncShow(m.showID, m.showTitle); err != nil {
+		m.status = fmt.Sprintf("AniList sync failed: %v", err)
+	}
 }
 
-func (m *model) View() string {
+func (m *Session) saveProgress(finished bool) {
+	if m.showID == "" || m.currentEpisode >= len(m.episodes) {
+		return
+	}
+
+	episode := m.episodes[m.currentEpisode]
+	if position, duration, ok := m.tracker.Snapshot(); ok {
+		RecordProgress(m.showID, episode, position, duration, false)
+	} else if finished {
+		RecordProgress(m.showID, episode, 0, 0, true)
+	}
+}
+
+func (m *Session) View() string {
 	if m.quitting {
-		return titleStyle.Render("Goodbye!")
+		return titleStyle().Render("Goodbye!")
 	}
 
-	title := titleStyle.Render("Karu Video Player")
+	title := titleStyle().Render("Karu Video Player")
 
 	currentEp := "None"
 	if m.currentEpisode < len(m.episodes) {
@@ -287,11 +430,11 @@ func (m *model) View() string {
 	}
 
 	episode := fmt.Sprintf("Episode: %s (%d/%d)",
-		episodeStyle.Render(currentEp),
+		episodeStyle().Render(currentEp),
 		m.currentEpisode+1,
 		len(m.episodes))
 
-	status := statusStyle.Render(m.status)
+	status := statusStyle().Render(m.status)
 
 	autoPlayStatus := "disabled"
 	if m.autoPlay {
@@ -299,27 +442,31 @@ func (m *model) View() string {
 	}
 
 	progress := ""
-	if m.showTitle != "" {
+	if m.showID != "" {
 		history, err := config.LoadHistory()
 		if err == nil {
-			if lastWatched, exists := history.GetProgress(m.showTitle); exists {
-				completion := history.GetCompletionPercentage(m.showTitle)
+			if watched, exists := history.GetWatchedCount(m.showID); exists {
+				completion := history.GetCompletionPercentage(m.showID)
 				progress = fmt.Sprintf("Progress: %d/%d episodes (%.1f%% complete)",
-					lastWatched, len(m.episodes), completion)
+					watched, len(m.episodes), completion)
 			}
 		}
 	}
 
 	controls := fmt.Sprintf("Auto-play: %s", autoPlayStatus)
+	if len(m.skipTimes) > 0 {
+		segments := make([]string, len(m.skipTimes))
+		for i, interval := range m.skipTimes {
+			segments[i] = strings.ToLower(interval.Label())
+		}
+		controls += fmt.Sprintf(" • Skip data: %s", strings.Join(segments, ", "))
+	}
 
-	help := helpStyle.Render("Press 'h' for help")
+	keys := ui.Keys.Player
+	help := helpStyle().Render(fmt.Sprintf("Press '%s' for help", keys.Help.Keys()[0]))
 	if m.showHelp {
-		help = helpStyle.Render(`Controls:
-  q       - Quit Karu
-  n       - Next episode  
-  p       - Previous episode
-  h/?     - Toggle this help
-  ctrl+c  - Force quit`)
+		forceQuit := key.NewBinding(key.WithHelp(ui.ForceQuitKey, "force quit"))
+		help = helpStyle().Render("Controls:\n" + ui.HelpTable(keys.Quit, keys.Next, keys.Prev, keys.Skip, keys.Help, forceQuit))
 	}
 
 	if progress != "" {
@@ -331,15 +478,17 @@ func (m *model) View() string {
 		title, episode, status, controls, help)
 }
 
-func (m *model) killCurrentProcess() {
+func (m *Session) killCurrentProcess() {
 	if m.currentProcess != nil && m.currentProcess.Process != nil {
+		m.saveProgress(false)
 		m.currentProcess.Process.Kill()
 		m.currentProcess = nil
 	}
+	m.closeSession()
 }
 
-func startVideoProcess(videoURL string, cfg *config.Config) (*exec.Cmd, error) {
-	args := []string{videoURL}
+func startVideoProcess(videoURL string, cfg *config.Config, extraArgs ...string) (*exec.Cmd, error) {
+	args := append(extraArgs, videoURL)
 	if cfg.PlayerArgs != "" {
 		playerArgs := strings.Fields(cfg.PlayerArgs)
 		args = append(playerArgs, args...)
@@ -0,0 +1,91 @@
+package player
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+)
+
+type progressTracker struct {
+	mu       sync.Mutex
+	position float64
+	duration float64
+	sampled  bool
+	stop     chan struct{}
+	once     sync.Once
+}
+
+func trackProgress(ipc *mpvIPC) *progressTracker {
+	t := &progressTracker{stop: make(chan struct{})}
+
+	go func() {
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+				position, err := ipc.GetFloat("time-pos")
+				if err != nil {
+					continue
+				}
+				duration, _ := ipc.GetFloat("duration")
+
+				t.mu.Lock()
+				t.position = position
+				t.duration = duration
+				t.sampled = true
+				t.mu.Unlock()
+			}
+		}
+	}()
+
+	return t
+}
+
+func (t *progressTracker) Snapshot() (position, duration float64, ok bool) {
+	if t == nil {
+		return 0, 0, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.position, t.duration, t.sampled
+}
+
+func (t *progressTracker) Stop() {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() { close(t.stop) })
+}
+
+func resumeArgs(cfg *config.Config, showID, episode string) []string {
+	if showID == "" || !isMPV(cfg.Player) {
+		return nil
+	}
+
+	history, err := config.LoadHistory()
+	if err != nil {
+		return nil
+	}
+
+	record, exists := history.GetEpisode(showID, episode)
+	if !exists || !record.InProgress() {
+		return nil
+	}
+
+	return []string{"--start=" + strconv.FormatFloat(record.Position, 'f', 0, 64)}
+}
+
+func RecordProgress(showID, episode string, position, duration float64, completed bool) {
+	history, err := config.LoadHistory()
+	if err != nil {
+		return
+	}
+
+	history.RecordWatch(showID, episode, position, duration, completed)
+}
@@ -0,0 +1,23 @@
+package player
+
+import (
+	"fmt"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/proxy"
+	"github.com/keircn/karu/internal/scraper"
+)
+
+func startProxy(cfg *config.Config) (*proxy.Server, error) {
+	if !cfg.UseProxy {
+		return nil, nil
+	}
+	return proxy.New(fmt.Sprintf("127.0.0.1:%d", cfg.ProxyPort), scraper.StreamHeaders())
+}
+
+func proxiedURL(srv *proxy.Server, videoURL string) string {
+	if srv == nil {
+		return videoURL
+	}
+	return srv.URL(videoURL)
+}
@@ -0,0 +1,138 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/internal/aniskip"
+	"github.com/keircn/karu/internal/config"
+)
+
+const autoSkipScript = `local ranges = {}
+for s, e in string.gmatch(mp.get_opt("karu-skip") or "", "([%d%.]+)-([%d%.]+)") do
+	table.insert(ranges, { start = tonumber(s), finish = tonumber(e), done = false })
+end
+
+mp.observe_property("time-pos", "number", function(_, pos)
+	if not pos then
+		return
+	end
+	for _, r in ipairs(ranges) do
+		if not r.done and pos >= r.start and pos < r.finish - 1 then
+			r.done = true
+			mp.set_property_number("time-pos", r.finish)
+			mp.osd_message("Skipped")
+			return
+		end
+	end
+end)
+`
+
+func isMPV(player string) bool {
+	return strings.Contains(strings.ToLower(filepath.Base(player)), "mpv")
+}
+
+func fetchSkipTimes(cfg *config.Config, malID, episode string) []aniskip.Interval {
+	if malID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	intervals, err := aniskip.NewClient(cfg.AniSkipURL).GetSkipTimes(ctx, malID, episode)
+	if err != nil {
+		return nil
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start < intervals[j].Start
+	})
+	return intervals
+}
+
+func writeChaptersFile(intervals []aniskip.Interval) (string, error) {
+	var b strings.Builder
+	b.WriteString(";FFMETADATA1\n")
+
+	writeChapter := func(start, end float64, title string) {
+		fmt.Fprintf(&b, "[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n",
+			int64(start*1000), int64(end*1000), title)
+	}
+
+	position := 0.0
+	for _, interval := range intervals {
+		if interval.Start > position {
+			writeChapter(position, interval.Start, "Episode")
+		}
+		writeChapter(interval.Start, interval.End, interval.Label())
+		position = interval.End
+	}
+	writeChapter(position, position+24*60*60, "Episode")
+
+	file, err := os.CreateTemp("", "karu-chapters-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(b.String()); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+func writeAutoSkipScript() (string, error) {
+	file, err := os.CreateTemp("", "karu-autoskip-*.lua")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(autoSkipScript); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+func skipArgs(cfg *config.Config, intervals []aniskip.Interval) ([]string, func()) {
+	if len(intervals) == 0 || !isMPV(cfg.Player) {
+		return nil, func() {}
+	}
+
+	var args, files []string
+	cleanup := func() {
+		for _, file := range files {
+			os.Remove(file)
+		}
+	}
+
+	if chaptersPath, err := writeChaptersFile(intervals); err == nil {
+		args = append(args, "--chapters-file="+chaptersPath)
+		files = append(files, chaptersPath)
+	}
+
+	if cfg.AutoSkip {
+		if scriptPath, err := writeAutoSkipScript(); err == nil {
+			ranges := make([]string, len(intervals))
+			for i, interval := range intervals {
+				ranges[i] = fmt.Sprintf("%.3f-%.3f", interval.Start, interval.End)
+			}
+			files = append(files, scriptPath)
+			args = append(args,
+				"--script="+scriptPath,
+				"--script-opts=karu-skip="+strings.Join(ranges, ";"))
+		}
+	}
+
+	return args, cleanup
+}
@@ -0,0 +1,260 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/pkg/errors"
+)
+
+const maxPlaylistSize = 8 * 1024 * 1024
+
+var (
+	uriAttributePattern = regexp.MustCompile(`URI="([^"]*)"`)
+
+	forwardedRequestHeaders = []string{"Range", "If-Range", "If-Modified-Since", "If-None-Match"}
+	rangeHeaders            = map[string]bool{"Range": true, "If-Range": true}
+
+	forwardedResponseHeaders = []string{
+		"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges",
+		"Last-Modified", "ETag", "Cache-Control",
+	}
+)
+
+type Server struct {
+	listener   net.Listener
+	httpServer *http.Server
+	client     *http.Client
+	headers    map[string]string
+	baseURL    string
+	// key signs the targets handed out by URL, so the proxy only fetches
+	// those and cannot be used as an open relay.
+	key []byte
+}
+
+func New(addr string, headers map[string]string) (*Server, error) {
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Wrap(err, errors.NetworkError, "failed to generate stream proxy key")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, errors.NetworkError, "failed to start stream proxy on %s", addr)
+	}
+
+	s := &Server{
+		listener: listener,
+		client:   &http.Client{},
+		headers:  headers,
+		baseURL:  "http://" + listener.Addr().String(),
+		key:      key,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stream/", s.handleStream)
+	s.httpServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go s.httpServer.Serve(listener)
+	return s, nil
+}
+
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *Server) SetPublicHost(host string) {
+	_, port, err := net.SplitHostPort(s.listener.Addr().String())
+	if err != nil {
+		return
+	}
+	s.baseURL = "http://" + net.JoinHostPort(host, port)
+}
+
+func (s *Server) URL(target string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(target))
+
+	name := "stream"
+	if parsed, err := url.Parse(target); err == nil {
+		if base := path.Base(parsed.Path); base != "" && base != "/" && base != "." {
+			name = base
+		}
+	}
+
+	return fmt.Sprintf("%s/stream/%s/%s/%s", s.baseURL, s.sign(target), encoded, url.PathEscape(name))
+}
+
+func (s *Server) sign(target string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(target))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return s.httpServer.Shutdown(ctx)
+}
+
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/stream/"), "/", 3)
+	if len(parts) < 2 {
+		http.Error(w, "invalid stream target", http.StatusBadRequest)
+		return
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		http.Error(w, "invalid stream target", http.StatusBadRequest)
+		return
+	}
+	if !hmac.Equal([]byte(parts[0]), []byte(s.sign(string(decoded)))) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	target, err := url.Parse(string(decoded))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		http.Error(w, "invalid stream target", http.StatusBadRequest)
+		return
+	}
+
+	// A rewritten playlist has different offsets from the original, so a
+	// range of one cannot be served and the whole playlist is fetched instead.
+	resp, err := s.fetch(r, target, !isPlaylistPath(target))
+	if err == nil && resp.StatusCode == http.StatusPartialContent && isPlaylist(target, resp) {
+		resp.Body.Close()
+		resp, err = s.fetch(r, target, false)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK && isPlaylist(target, resp) {
+		s.servePlaylist(w, r, target, resp)
+		return
+	}
+
+	for _, header := range forwardedResponseHeaders {
+		if value := resp.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	if r.Method != http.MethodHead {
+		io.Copy(w, resp.Body)
+	}
+}
+
+func (s *Server) fetch(r *http.Request, target *url.URL, ranged bool) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+	for _, header := range forwardedRequestHeaders {
+		if value := r.Header.Get(header); value != "" && (ranged || !rangeHeaders[header]) {
+			req.Header.Set(header, value)
+		}
+	}
+	return s.client.Do(req)
+}
+
+func (s *Server) servePlaylist(w http.ResponseWriter, r *http.Request, playlistURL *url.URL, resp *http.Response) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPlaylistSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	rewritten := s.RewritePlaylist(playlistURL, body)
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(rewritten)))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method != http.MethodHead {
+		w.Write(rewritten)
+	}
+}
+
+func (s *Server) RewritePlaylist(playlistURL *url.URL, body []byte) []byte {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), maxPlaylistSize)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			out.WriteString(line)
+		case strings.HasPrefix(trimmed, "#"):
+			out.WriteString(uriAttributePattern.ReplaceAllStringFunc(line, func(match string) string {
+				uri := uriAttributePattern.FindStringSubmatch(match)[1]
+				return `URI="` + s.proxiedReference(playlistURL, uri) + `"`
+			}))
+		default:
+			out.WriteString(s.proxiedReference(playlistURL, trimmed))
+		}
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes()
+}
+
+func (s *Server) proxiedReference(base *url.URL, reference string) string {
+	if strings.HasPrefix(reference, "data:") || strings.HasPrefix(reference, "skd:") {
+		return reference
+	}
+
+	resolved, err := base.Parse(reference)
+	if err != nil {
+		return reference
+	}
+	return s.URL(resolved.String())
+}
+
+func isPlaylist(target *url.URL, resp *http.Response) bool {
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	if strings.Contains(contentType, "mpegurl") {
+		return true
+	}
+	return isPlaylistPath(target)
+}
+
+func isPlaylistPath(target *url.URL) bool {
+	return strings.HasSuffix(strings.ToLower(target.Path), ".m3u8")
+}
@@ -0,0 +1,209 @@
+package releases
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/scraper"
+)
+
+type ShowUpdate struct {
+	ShowID        string   `json:"show_id"`
+	Title         string   `json:"title"`
+	URL           string   `json:"url"`
+	Status        string   `json:"status,omitempty"`
+	KnownEpisodes int      `json:"known_episodes"`
+	TotalEpisodes int      `json:"total_episodes"`
+	NewEpisodes   []string `json:"new_episodes"`
+	Unwatched     int      `json:"unwatched"`
+	NextEpisode   string   `json:"next_episode,omitempty"`
+	Error         string   `json:"error,omitempty"`
+
+	Episodes []string `json:"-"`
+}
+
+func (u ShowUpdate) HasNew() bool {
+	return len(u.NewEpisodes) > 0
+}
+
+type followedShow struct {
+	showID string
+	title  string
+	url    string
+	status config.WatchStatus
+	known  int
+}
+
+func followedShows(history *config.History, watchlist *config.Watchlist) []followedShow {
+	var shows []followedShow
+	seen := make(map[string]bool)
+
+	for _, entry := range watchlist.ByStatus(config.StatusWatching) {
+		known := entry.KnownEps
+		if historyEntry, exists := history.Find(entry.ShowID); exists && historyEntry.TotalEps > known {
+			known = historyEntry.TotalEps
+		}
+		shows = append(shows, followedShow{
+			showID: entry.ShowID,
+			title:  entry.Title,
+			url:    entry.URL,
+			status: entry.Status,
+			known:  known,
+		})
+		seen[entry.ShowID] = true
+	}
+
+	for _, entry := range history.GetRecent(0) {
+		if seen[entry.ShowID] || entry.ShowID == "" {
+			continue
+		}
+		if _, listed := watchlist.Find(entry.ShowID); listed {
+			continue
+		}
+		watched := entry.WatchedCount()
+		if watched == 0 || (entry.TotalEps > 0 && watched >= entry.TotalEps) {
+			continue
+		}
+		shows = append(shows, followedShow{
+			showID: entry.ShowID,
+			title:  entry.Title,
+			url:    entry.URL,
+			known:  entry.TotalEps,
+		})
+		seen[entry.ShowID] = true
+	}
+
+	return shows
+}
+
+func Check() ([]ShowUpdate, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.DefaultConfig
+	}
+
+	history, err := config.LoadHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	watchlist, err := config.LoadWatchlist()
+	if err != nil {
+		return nil, err
+	}
+
+	shows := followedShows(history, watchlist)
+	showIDs := make([]string, len(shows))
+	for i, show := range shows {
+		showIDs[i] = show.showID
+	}
+
+	results := scraper.LoadEpisodeLists(showIDs, cfg.ConcurrentWorkers)
+
+	updates := make([]ShowUpdate, len(shows))
+	for i, show := range shows {
+		update := ShowUpdate{
+			ShowID:        show.showID,
+			Title:         show.title,
+			URL:           show.url,
+			Status:        string(show.status),
+			KnownEpisodes: show.known,
+			NewEpisodes:   []string{},
+		}
+
+		if results[i].Error != nil {
+			update.Error = results[i].Error.Error()
+			updates[i] = update
+			continue
+		}
+
+		episodes := SortEpisodes(results[i].Episodes)
+		update.Episodes = episodes
+		update.TotalEpisodes = len(episodes)
+
+		if show.known > 0 && len(episodes) > show.known {
+			update.NewEpisodes = episodes[show.known:]
+		}
+
+		for _, episode := range episodes {
+			if history.IsWatched(show.showID, episode) {
+				continue
+			}
+			update.Unwatched++
+		}
+		if next, ok := history.GetResumeEpisode(show.showID, episodes); ok {
+			update.NextEpisode = next
+		} else {
+			for _, episode := range episodes {
+				if !history.IsWatched(show.showID, episode) {
+					update.NextEpisode = episode
+					break
+				}
+			}
+		}
+
+		updates[i] = update
+	}
+
+	if err := saveKnownEpisodes(history, watchlist, updates); err != nil {
+		return updates, err
+	}
+
+	sort.SliceStable(updates, func(i, j int) bool {
+		return len(updates[i].NewEpisodes) > len(updates[j].NewEpisodes)
+	})
+
+	return updates, nil
+}
+
+func saveKnownEpisodes(history *config.History, watchlist *config.Watchlist, updates []ShowUpdate) error {
+	totals := make(map[string]int)
+	for _, update := range updates {
+		if update.Error == "" && update.TotalEpisodes > 0 {
+			totals[update.ShowID] = update.TotalEpisodes
+		}
+	}
+
+	watchlistChanged := false
+	for i := range watchlist.Entries {
+		if total, ok := totals[watchlist.Entries[i].ShowID]; ok && watchlist.Entries[i].KnownEps != total {
+			watchlist.Entries[i].KnownEps = total
+			watchlistChanged = true
+		}
+	}
+
+	historyChanged := false
+	for i := range history.Entries {
+		if total, ok := totals[history.Entries[i].ShowID]; ok && history.Entries[i].TotalEps != total {
+			history.Entries[i].TotalEps = total
+			historyChanged = true
+		}
+	}
+
+	if watchlistChanged {
+		if err := config.SaveWatchlist(watchlist); err != nil {
+			return err
+		}
+	}
+	if historyChanged {
+		return config.SaveHistory(history)
+	}
+	return nil
+}
+
+func SortEpisodes(episodes []string) []string {
+	sorted := make([]string, len(episodes))
+	copy(sorted, episodes)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		numI, errI := strconv.ParseFloat(sorted[i], 64)
+		numJ, errJ := strconv.ParseFloat(sorted[j], 64)
+		if errI != nil || errJ != nil {
+			return sorted[i] < sorted[j]
+		}
+		return numI < numJ
+	})
+
+	return sorted
+}
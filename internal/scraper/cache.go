@@ -89,14 +89,21 @@ var (
 	cacheOnce    sync.Once
 )
 
+func searchCacheTTL() time.Duration {
+	cfg, _ := config.Load()
+
+	searchTTL := time.Duration(cfg.CacheTTL) * time.Minute
+	if searchTTL <= 0 {
+		searchTTL = 15 * time.Minute
+	}
+	return searchTTL
+}
+
 func initCaches() {
 	cacheOnce.Do(func() {
 		cfg, _ := config.Load()
 
-		searchTTL := time.Duration(cfg.CacheTTL) * time.Minute
-		if searchTTL <= 0 {
-			searchTTL = 15 * time.Minute
-		}
+		searchTTL := searchCacheTTL()
 
 		episodeTTL := time.Duration(cfg.CacheTTL*2) * time.Minute
 		if episodeTTL <= 0 {
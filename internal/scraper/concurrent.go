@@ -232,3 +232,34 @@ func GetVideoURLConcurrent(showID, episode string, timeout time.Duration) (strin
 
 	return GetVideoURL(showID, episode)
 }
+
+type EpisodeListResult struct {
+	ShowID   string
+	Episodes []string
+	Error    error
+}
+
+func LoadEpisodeLists(showIDs []string, workers int) []EpisodeListResult {
+	if workers <= 0 {
+		workers = 4
+	}
+
+	results := make([]EpisodeListResult, len(showIDs))
+	semaphore := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for i, showID := range showIDs {
+		wg.Add(1)
+		go func(i int, showID string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			episodes, err := GetEpisodes(showID)
+			results[i] = EpisodeListResult{ShowID: showID, Episodes: episodes, Error: err}
+		}(i, showID)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,265 @@
+package scraper
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/keircn/karu/internal/config"
+)
+
+const (
+	historyBoost   = 0.15
+	watchlistBoost = 0.1
+	// boostFloor keeps the history and watchlist boosts from lifting shows
+	// that barely match the query.
+	boostFloor = 0.4
+
+	suggestionThreshold = 0.6
+	maxSuggestions      = 3
+	maxRetries          = 3
+)
+
+type SearchResults struct {
+	Animes []Anime
+	// Query is the query that produced Animes. It differs from the one
+	// searched for when nothing matched and a looser query was used instead.
+	Query       string
+	Suggestions []string
+}
+
+func (c *Client) SearchWithSuggestions(ctx context.Context, query string) (*SearchResults, error) {
+	animes, err := c.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(animes) > 0 {
+		return &SearchResults{Animes: animes, Query: query}, nil
+	}
+
+	for _, retry := range alternativeQueries(query) {
+		animes, err := c.search(ctx, retry)
+		if err != nil {
+			return nil, err
+		}
+		if len(animes) > 0 {
+			animes = Rank(query, animes)
+			return &SearchResults{Animes: animes, Query: retry, Suggestions: suggest(query, animes)}, nil
+		}
+	}
+
+	return &SearchResults{Query: query, Suggestions: suggest(query, knownShows())}, nil
+}
+
+func Rank(query string, animes []Anime) []Anime {
+	boosts := showBoosts()
+	scores := make(map[string]float64, len(animes))
+	for _, anime := range animes {
+		score := titleScore(query, anime)
+		if score >= boostFloor {
+			score += boosts[config.ShowIDFromURL(anime.URL)]
+		}
+		scores[anime.URL] = score
+	}
+
+	ranked := append([]Anime(nil), animes...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].URL] > scores[ranked[j].URL]
+	})
+	return ranked
+}
+
+func titleScore(query string, anime Anime) float64 {
+	var best float64
+	for _, title := range anime.Titles() {
+		best = max(best, Similarity(query, title))
+	}
+	return best
+}
+
+func Similarity(query, title string) float64 {
+	q, t := normalizeTitle(query), normalizeTitle(title)
+	if q == "" || t == "" {
+		return 0
+	}
+	if q == t {
+		return 1
+	}
+
+	queryWords, titleWords := strings.Fields(q), strings.Fields(t)
+	var total float64
+	for _, qw := range queryWords {
+		var best float64
+		for _, tw := range titleWords {
+			best = max(best, wordSimilarity(qw, tw))
+		}
+		total += best
+	}
+	words := total / float64(len(queryWords))
+	// Titles with many words the query does not mention rank a little lower.
+	coverage := float64(len(queryWords)) / float64(max(len(titleWords), len(queryWords)))
+	words *= 0.85 + 0.15*coverage
+
+	whole := ratio(q, t)
+	if strings.HasPrefix(t, q) {
+		whole = max(whole, 0.9)
+	}
+	return max(words, whole)
+}
+
+func wordSimilarity(a, b string) float64 {
+	switch {
+	case a == b:
+		return 1
+	case utf8.RuneCountInString(a) >= 3 && strings.HasPrefix(b, a):
+		return 0.9
+	}
+	return ratio(a, b)
+}
+
+func ratio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func normalizeTitle(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+func alternativeQueries(query string) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(query)): true}
+	var queries []string
+	add := func(q string) {
+		if q != "" && !seen[q] && len(queries) < maxRetries {
+			seen[q] = true
+			queries = append(queries, q)
+		}
+	}
+
+	normalized := normalizeTitle(query)
+	add(normalized)
+
+	words := strings.Fields(normalized)
+	for n := len(words) - 1; n >= 1; n-- {
+		add(strings.Join(words[:n], " "))
+	}
+
+	var longest []rune
+	for _, word := range words {
+		if r := []rune(word); len(r) > len(longest) {
+			longest = r
+		}
+	}
+	// Half of the longest word gets past a typo near the end of the query.
+	if len(longest) >= 5 {
+		add(string(longest[:(len(longest)+1)/2]))
+	}
+	return queries
+}
+
+func suggest(query string, animes []Anime) []string {
+	type candidate struct {
+		title string
+		score float64
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+	for _, anime := range animes {
+		if seen[anime.Title] {
+			continue
+		}
+		seen[anime.Title] = true
+		if score := titleScore(query, anime); score >= suggestionThreshold {
+			candidates = append(candidates, candidate{anime.Title, score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	var suggestions []string
+	for _, c := range candidates {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, c.title)
+	}
+	return suggestions
+}
+
+func knownShows() []Anime {
+	var animes []Anime
+	if history, err := config.LoadHistory(); err == nil {
+		for _, entry := range history.Entries {
+			animes = append(animes, Anime{Title: entry.Title, URL: entry.URL})
+		}
+	}
+	if watchlist, err := config.LoadWatchlist(); err == nil {
+		for _, entry := range watchlist.Entries {
+			animes = append(animes, Anime{Title: entry.Title, URL: entry.URL})
+		}
+	}
+	return animes
+}
+
+func showBoosts() map[string]float64 {
+	boosts := make(map[string]float64)
+	if history, err := config.LoadHistory(); err == nil {
+		for _, entry := range history.Entries {
+			showID := entry.ShowID
+			if showID == "" {
+				showID = config.ShowIDFromURL(entry.URL)
+			}
+			boosts[showID] += historyBoost
+		}
+	}
+	if watchlist, err := config.LoadWatchlist(); err == nil {
+		for _, entry := range watchlist.Entries {
+			boosts[entry.ShowID] += watchlistBoost
+		}
+	}
+	return boosts
+}
+
+func altTitles(title string, names []string) []string {
+	seen := map[string]bool{strings.ToLower(title): true}
+	var titles []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		titles = append(titles, name)
+	}
+	return titles
+}
@@ -5,19 +5,28 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/keircn/karu/internal/config"
 	"github.com/keircn/karu/pkg/errors"
 	"github.com/keircn/karu/pkg/graphql"
 	"github.com/keircn/karu/pkg/http"
 )
 
-const apiURL = "https://api.allanime.day/api"
+const (
+	apiURL    = "https://api.allanime.day/api"
+	userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:109.0) Gecko/20100101 Firefox/121.0"
+	referer   = "https://allanime.to"
+)
 
 type SearchResult struct {
 	Data struct {
 		Shows struct {
 			Edges []struct {
-				ID                string `json:"_id"`
-				Name              string `json:"name"`
+				ID                string   `json:"_id"`
+				Name              string   `json:"name"`
+				EnglishName       string   `json:"englishName"`
+				NativeName        string   `json:"nativeName"`
+				AltNames          []string `json:"altNames"`
+				Thumbnail         string   `json:"thumbnail"`
 				AvailableEpisodes struct {
 					Sub int `json:"sub"`
 					Dub int `json:"dub"`
@@ -35,8 +44,8 @@ type Client struct {
 func NewClient() *Client {
 	httpClient := http.NewClient(
 		http.WithTimeout(10*time.Second),
-		http.WithUserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:109.0) Gecko/20100101 Firefox/121.0"),
-		http.WithReferer("https://allanime.to"),
+		http.WithUserAgent(userAgent),
+		http.WithReferer(referer),
 	)
 
 	queryBuilder := graphql.NewQueryBuilder(apiURL, httpClient)
@@ -58,7 +67,7 @@ func (c *Client) executeShowsQuery(ctx context.Context, qb *graphql.QueryBuilder
 	}
 
 	var result SearchResult
-	err := executeWithFallback(func(baseURL string) error {
+	err := executeWithFallbackContext(ctx, func(baseURL string) error {
 		qb := graphql.NewQueryBuilder(baseURL, c.httpClient).
 			SetQuery(query.Query)
 
@@ -76,9 +85,11 @@ func (c *Client) executeShowsQuery(ctx context.Context, qb *graphql.QueryBuilder
 	animes := make([]Anime, 0, len(result.Data.Shows.Edges))
 	for _, edge := range result.Data.Shows.Edges {
 		animes = append(animes, Anime{
-			Title:    edge.Name,
-			URL:      fmt.Sprintf("https://allanime.to/anime/%s", edge.ID),
-			Episodes: fmt.Sprintf("%d", edge.AvailableEpisodes.Sub),
+			Title:     edge.Name,
+			URL:       ShowURL(edge.ID),
+			Episodes:  fmt.Sprintf("%d", edge.AvailableEpisodes.Sub),
+			AltTitles: altTitles(edge.Name, append([]string{edge.EnglishName, edge.NativeName}, edge.AltNames...)),
+			Thumbnail: thumbnailURL(edge.Thumbnail),
 		})
 	}
 
@@ -87,6 +98,28 @@ func (c *Client) executeShowsQuery(ctx context.Context, qb *graphql.QueryBuilder
 }
 
 func (c *Client) Search(ctx context.Context, query string) ([]Anime, error) {
+	animes, err := c.search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return Rank(query, animes), nil
+}
+
+func (c *Client) search(ctx context.Context, query string) ([]Anime, error) {
+	if cached, found := config.GetSearchCache(query, searchCacheTTL()); found {
+		animes := make([]Anime, 0, len(cached.Results))
+		for _, show := range cached.Results {
+			animes = append(animes, Anime{
+				Title:     show.Title,
+				URL:       ShowURL(show.ShowID),
+				Episodes:  show.Episodes,
+				AltTitles: show.AltTitles,
+				Thumbnail: show.Thumbnail,
+			})
+		}
+		return animes, nil
+	}
+
 	qb := graphql.NewQueryBuilder(apiURL, c.httpClient).
 		SetQuery(graphql.ShowsQuery).
 		AddSearchInput(query, false, false).
@@ -94,7 +127,24 @@ func (c *Client) Search(ctx context.Context, query string) ([]Anime, error) {
 		AddTranslationType("sub").
 		AddCountryOrigin("ALL")
 
-	return c.executeShowsQuery(ctx, qb)
+	animes, err := c.executeShowsQuery(ctx, qb)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]config.CachedShow, 0, len(animes))
+	for _, anime := range animes {
+		results = append(results, config.CachedShow{
+			ShowID:    config.ShowIDFromURL(anime.URL),
+			Title:     anime.Title,
+			Episodes:  anime.Episodes,
+			AltTitles: anime.AltTitles,
+			Thumbnail: anime.Thumbnail,
+		})
+	}
+	config.PutSearchCache(query, results)
+
+	return animes, nil
 }
 
 func (c *Client) GetTrending(ctx context.Context) ([]Anime, error) {
@@ -132,10 +182,25 @@ func (c *Client) DownloadEpisode(ctx context.Context, showID, episode, outputPat
 
 var defaultClient = NewClient()
 
+func StreamHeaders() map[string]string {
+	return map[string]string{
+		"User-Agent": userAgent,
+		"Referer":    referer,
+	}
+}
+
 func Search(query string) ([]Anime, error) {
 	return defaultClient.Search(context.Background(), query)
 }
 
+func SearchContext(ctx context.Context, query string) ([]Anime, error) {
+	return defaultClient.Search(ctx, query)
+}
+
+func SearchWithSuggestions(query string) (*SearchResults, error) {
+	return defaultClient.SearchWithSuggestions(context.Background(), query)
+}
+
 func GetTrending() ([]Anime, error) {
 	return defaultClient.GetTrending(context.Background())
 }
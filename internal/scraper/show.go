@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"context"
+
+	"github.com/keircn/karu/pkg/errors"
+	"github.com/keircn/karu/pkg/graphql"
+)
+
+type ShowInfo struct {
+	ID        string `json:"_id"`
+	Name      string `json:"name"`
+	MalID     string `json:"malId"`
+	AniListID string `json:"aniListId"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+type ShowInfoData struct {
+	Data struct {
+		Show ShowInfo `json:"show"`
+	} `json:"data"`
+}
+
+const ShowInfoQuery = `query ($showId: String!) {
+	show(_id: $showId) {
+		_id
+		name
+		malId
+		aniListId
+		thumbnail
+	}
+}`
+
+func (c *Client) GetShowInfo(ctx context.Context, showID string) (*ShowInfo, error) {
+	initCaches()
+
+	cacheKey := generateCacheKey("showInfo", map[string]interface{}{"showId": showID})
+
+	if cached, found := episodeCache.Get(cacheKey); found {
+		return cached.(*ShowInfo), nil
+	}
+
+	var info ShowInfo
+
+	err := executeWithFallback(func(baseURL string) error {
+		qb := graphql.NewQueryBuilder(baseURL, c.httpClient).
+			SetQuery(ShowInfoQuery).
+			AddVariable("showId", showID)
+
+		var showData ShowInfoData
+		if err := qb.Execute(ctx, &showData); err != nil {
+			return err
+		}
+
+		info = showData.Data.Show
+		info.Thumbnail = thumbnailURL(info.Thumbnail)
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ScrapingError, "failed to get show info")
+	}
+
+	episodeCache.Set(cacheKey, &info)
+	return &info, nil
+}
+
+func GetShowInfo(showID string) (*ShowInfo, error) {
+	return defaultClient.GetShowInfo(context.Background(), showID)
+}
+
+func GetMalID(showID string) string {
+	info, err := GetShowInfo(showID)
+	if err != nil {
+		return ""
+	}
+	return info.MalID
+}
@@ -145,16 +145,23 @@ func indexOf(s, substr string) int {
 }
 
 func executeWithFallback(fn func(string) error) error {
+	return executeWithFallbackContext(context.Background(), fn)
+}
+
+func executeWithFallbackContext(ctx context.Context, fn func(string) error) error {
 	cfg, _ := config.Load()
 	timeout := time.Duration(cfg.RequestTimeout) * time.Second
 	if timeout <= 0 {
 		timeout = DefaultTimeout
 	}
 
-	ctx := context.Background()
 	var lastErr error
 
 	for _, source := range sources {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
 		err := executeWithRetry(ctx, timeout, func() error {
 			return fn(source.URL)
 		})
@@ -1,7 +1,34 @@
 package scraper
 
+import "strings"
+
+const thumbnailHost = "https://wp.youtube-anime.com/aln.youtube-anime.com/"
+
 type Anime struct {
-	Title    string
-	URL      string
-	Episodes string
+	Title     string
+	URL       string
+	Episodes  string
+	AltTitles []string
+	Thumbnail string
+}
+
+func (a Anime) Titles() []string {
+	return append([]string{a.Title}, a.AltTitles...)
+}
+
+func ShowURL(showID string) string {
+	return "https://allanime.to/anime/" + showID
+}
+
+// The provider's thumbnail paths are often relative to its image host.
+func thumbnailURL(path string) string {
+	switch {
+	case path == "":
+		return ""
+	case strings.HasPrefix(path, "http://"), strings.HasPrefix(path, "https://"):
+		return path
+	case strings.HasPrefix(path, "//"):
+		return "https:" + path
+	}
+	return thumbnailHost + strings.TrimPrefix(path, "/")
 }
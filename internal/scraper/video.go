@@ -16,10 +16,10 @@ import (
 )
 
 type QualityOption struct {
-	Quality string
-	URL     string
-	Source  string
-	IsHLS   bool
+	Quality string `json:"quality"`
+	URL     string `json:"url"`
+	Source  string `json:"source"`
+	IsHLS   bool   `json:"hls"`
 }
 
 type QualityChoice struct {
@@ -50,8 +50,8 @@ func fetchClockURL(clockURL string) (string, error) {
 		return "", err
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:109.0) Gecko/20100101 Firefox/121.0")
-	req.Header.Set("Referer", "https://allanime.to")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Referer", referer)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -100,8 +100,8 @@ func fetchIframeAndExtractStreams(iframeURL string) ([]Stream, error) {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:109.0) Gecko/20100101 Firefox/121.0")
-	req.Header.Set("Referer", "https://allanime.to")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Referer", referer)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -325,24 +325,34 @@ func GetAvailableQualities(showID, episode string) (*QualityChoice, error) {
 }
 
 func GetVideoURLWithQuality(showID, episode, preferredQuality string) (string, error) {
-	qualities, err := GetAvailableQualities(showID, episode)
+	option, err := GetPreferredQuality(showID, episode, preferredQuality)
 	if err != nil {
 		return "", err
 	}
+	return option.URL, nil
+}
+
+// GetPreferredQuality returns the source matching preferredQuality, falling
+// back to the provider's default source.
+func GetPreferredQuality(showID, episode, preferredQuality string) (*QualityOption, error) {
+	qualities, err := GetAvailableQualities(showID, episode)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(qualities.Options) == 0 {
-		return "", fmt.Errorf("no video sources available")
+		return nil, fmt.Errorf("no video sources available")
 	}
 
 	if preferredQuality == "" {
-		return qualities.Options[qualities.Default].URL, nil
+		return &qualities.Options[qualities.Default], nil
 	}
 
-	for _, option := range qualities.Options {
+	for i, option := range qualities.Options {
 		if strings.Contains(strings.ToLower(option.Quality), strings.ToLower(preferredQuality)) {
-			return option.URL, nil
+			return &qualities.Options[i], nil
 		}
 	}
 
-	return qualities.Options[qualities.Default].URL, nil
+	return &qualities.Options[qualities.Default], nil
 }
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"strings"
+	"time"
+)
+
+var heatmapLevels = []string{"·", "░", "▒", "▓", "█"}
+
+func Heatmap(days map[string]int, now time.Time, weeks int) string {
+	if weeks <= 0 {
+		weeks = 52
+	}
+
+	peak := 0
+	for _, count := range days {
+		peak = max(peak, count)
+	}
+
+	end := startOfWeek(now)
+	start := end.AddDate(0, 0, -7*(weeks-1))
+
+	var b strings.Builder
+	b.WriteString("    ")
+	lastMonth := start.Month()
+	for week := 0; week < weeks; week++ {
+		month := start.AddDate(0, 0, 7*week).Month()
+		if month != lastMonth && week < weeks-3 {
+			label := month.String()[:3] + " "
+			b.WriteString(label)
+			week += len(label) - 1
+			lastMonth = month
+			continue
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString("\n")
+
+	for weekday := 0; weekday < 7; weekday++ {
+		switch weekday {
+		case 0:
+			b.WriteString("Mon ")
+		case 2:
+			b.WriteString("Wed ")
+		case 4:
+			b.WriteString("Fri ")
+		default:
+			b.WriteString("    ")
+		}
+
+		for week := 0; week < weeks; week++ {
+			day := start.AddDate(0, 0, 7*week+weekday)
+			if day.After(now) {
+				b.WriteString(" ")
+				continue
+			}
+			b.WriteString(heatmapLevels[level(days[day.Format(dayFormat)], peak)])
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n    Less ")
+	b.WriteString(strings.Join(heatmapLevels, ""))
+	b.WriteString(" More\n")
+	return b.String()
+}
+
+func level(count, peak int) int {
+	if count == 0 || peak == 0 {
+		return 0
+	}
+	return 1 + (count-1)*(len(heatmapLevels)-2)/max(peak-1, 1)
+}
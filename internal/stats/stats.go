@@ -0,0 +1,248 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+)
+
+const (
+	dayFormat = "2006-01-02"
+
+	defaultEpisodeMinutes = 24
+)
+
+var relativeSince = regexp.MustCompile(`^(\d+)([dwmy])$`)
+
+type Period struct {
+	Label    string    `json:"label"`
+	Start    time.Time `json:"start"`
+	Episodes int       `json:"episodes"`
+	Hours    float64   `json:"hours"`
+}
+
+type Count struct {
+	Name     string `json:"name"`
+	Episodes int    `json:"episodes"`
+}
+
+type Stats struct {
+	Since          *time.Time     `json:"since,omitempty"`
+	Episodes       int            `json:"episodes"`
+	Hours          float64        `json:"hours"`
+	Shows          int            `json:"shows"`
+	ShowsCompleted int            `json:"shows_completed"`
+	CurrentStreak  int            `json:"current_streak"`
+	LongestStreak  int            `json:"longest_streak"`
+	Days           map[string]int `json:"days"`
+	Weeks          []Period       `json:"weeks"`
+	Months         []Period       `json:"months"`
+	TopShows       []Count        `json:"top_shows"`
+	TopGenres      []Count        `json:"top_genres,omitempty"`
+}
+
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+
+	if match := relativeSince.FindStringSubmatch(value); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		switch match[2] {
+		case "d":
+			return now.AddDate(0, 0, -n), nil
+		case "w":
+			return now.AddDate(0, 0, -7*n), nil
+		case "m":
+			return now.AddDate(0, -n, 0), nil
+		default:
+			return now.AddDate(-n, 0, 0), nil
+		}
+	}
+
+	for _, layout := range []string{dayFormat, "2006-01", "2006"} {
+		if since, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return since, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since value '%s' (use a date like 2025-01-01 or a span like 30d, 12w, 6m, 1y)", value)
+}
+
+func Compute(events []config.WatchEvent, history *config.History, watchlist *config.Watchlist, genres map[string][]string, since, now time.Time) *Stats {
+	stats := &Stats{Days: make(map[string]int)}
+	if !since.IsZero() {
+		stats.Since = &since
+	}
+
+	shows := make(map[string]int)
+	titles := make(map[string]string)
+	lastWatched := make(map[string]time.Time)
+	weeks := make(map[string]*Period)
+	months := make(map[string]*Period)
+
+	for _, event := range events {
+		if event.WatchedAt.Before(since) {
+			continue
+		}
+
+		hours := event.Duration / 3600
+		if hours <= 0 {
+			hours = defaultEpisodeMinutes / 60.0
+		}
+
+		local := event.WatchedAt.In(now.Location())
+		stats.Episodes++
+		stats.Hours += hours
+		stats.Days[local.Format(dayFormat)]++
+
+		shows[event.ShowID]++
+		titles[event.ShowID] = event.Title
+		if event.WatchedAt.After(lastWatched[event.ShowID]) {
+			lastWatched[event.ShowID] = event.WatchedAt
+		}
+
+		weekStart := startOfWeek(local)
+		addToPeriod(weeks, weekStart.Format(dayFormat), weekStart, hours)
+		monthStart := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, local.Location())
+		addToPeriod(months, monthStart.Format("2006-01"), monthStart, hours)
+	}
+
+	stats.Hours = roundHours(stats.Hours)
+	for _, periods := range []map[string]*Period{weeks, months} {
+		for _, period := range periods {
+			period.Hours = roundHours(period.Hours)
+		}
+	}
+
+	stats.Shows = len(shows)
+	stats.ShowsCompleted = completedShows(history, watchlist, lastWatched, since)
+	stats.CurrentStreak, stats.LongestStreak = streaks(stats.Days, now)
+	stats.Weeks = sortedPeriods(weeks)
+	stats.Months = sortedPeriods(months)
+
+	for showID, episodes := range shows {
+		stats.TopShows = append(stats.TopShows, Count{Name: titles[showID], Episodes: episodes})
+	}
+	stats.TopShows = topCounts(stats.TopShows, 5)
+
+	genreCounts := make(map[string]int)
+	for showID, episodes := range shows {
+		for _, genre := range genres[showID] {
+			genreCounts[genre] += episodes
+		}
+	}
+	for genre, episodes := range genreCounts {
+		stats.TopGenres = append(stats.TopGenres, Count{Name: genre, Episodes: episodes})
+	}
+	stats.TopGenres = topCounts(stats.TopGenres, 5)
+
+	return stats
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*10) / 10
+}
+
+func addToPeriod(periods map[string]*Period, label string, start time.Time, hours float64) {
+	period, exists := periods[label]
+	if !exists {
+		period = &Period{Label: label, Start: start}
+		periods[label] = period
+	}
+	period.Episodes++
+	period.Hours += hours
+}
+
+func sortedPeriods(periods map[string]*Period) []Period {
+	result := make([]Period, 0, len(periods))
+	for _, period := range periods {
+		result = append(result, *period)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+func topCounts(counts []Count, limit int) []Count {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Episodes == counts[j].Episodes {
+			return counts[i].Name < counts[j].Name
+		}
+		return counts[i].Episodes > counts[j].Episodes
+	})
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts
+}
+
+func completedShows(history *config.History, watchlist *config.Watchlist, lastWatched map[string]time.Time, since time.Time) int {
+	completed := make(map[string]bool)
+
+	for _, entry := range history.Entries {
+		if entry.TotalEps <= 0 || entry.WatchedCount() < entry.TotalEps {
+			continue
+		}
+		if _, watched := lastWatched[entry.ShowID]; watched || since.IsZero() {
+			completed[entry.ShowID] = true
+		}
+	}
+
+	for _, entry := range watchlist.Entries {
+		if entry.Status == config.StatusCompleted && entry.CompletedAt != nil && !entry.CompletedAt.Before(since) {
+			completed[entry.ShowID] = true
+		}
+	}
+
+	return len(completed)
+}
+
+func streaks(days map[string]int, now time.Time) (int, int) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	dates := make([]time.Time, 0, len(days))
+	for day := range days {
+		if date, err := time.ParseInLocation(dayFormat, day, now.Location()); err == nil {
+			dates = append(dates, date)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	longest, run := 0, 0
+	for i, date := range dates {
+		if i > 0 && date.Equal(dates[i-1].AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	current := 0
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if days[day.Format(dayFormat)] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day.Format(dayFormat)] > 0 {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return current, longest
+}
+
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	day := t.AddDate(0, 0, -offset)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, t.Location())
+}
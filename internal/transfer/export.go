@@ -0,0 +1,114 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+)
+
+var csvHeader = []string{
+	"provider", "show_id", "title", "url", "status", "score", "notes", "tags",
+	"total_episodes", "watched_episodes", "updated_at",
+}
+
+func Export(w io.Writer, format string, history *config.History, watchlist *config.Watchlist) error {
+	switch format {
+	case FormatJSON:
+		return exportJSON(w, history, watchlist)
+	case FormatCSV:
+		return exportCSV(w, history, watchlist)
+	default:
+		return fmt.Errorf("unsupported export format '%s' (must be %s or %s)", format, FormatJSON, FormatCSV)
+	}
+}
+
+func exportJSON(w io.Writer, history *config.History, watchlist *config.Watchlist) error {
+	export := Document{
+		Version:    exportVersion,
+		ExportedAt: time.Now(),
+		History:    history.Entries,
+		Watchlist:  watchlist.Entries,
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+func exportCSV(w io.Writer, history *config.History, watchlist *config.Watchlist) error {
+	type row struct {
+		history   *config.HistoryEntry
+		watchlist *config.WatchlistEntry
+	}
+
+	rows := make(map[string]*row)
+	var order []string
+	get := func(showID string) *row {
+		if r, exists := rows[showID]; exists {
+			return r
+		}
+		rows[showID] = &row{}
+		order = append(order, showID)
+		return rows[showID]
+	}
+
+	for i := range history.Entries {
+		get(history.Entries[i].ShowID).history = &history.Entries[i]
+	}
+	for i := range watchlist.Entries {
+		get(watchlist.Entries[i].ShowID).watchlist = &watchlist.Entries[i]
+	}
+	sort.Strings(order)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, showID := range order {
+		r := rows[showID]
+		record := make([]string, len(csvHeader))
+		record[1] = showID
+
+		var updated time.Time
+		if h := r.history; h != nil {
+			record[0] = h.Provider
+			record[2] = h.Title
+			record[3] = h.URL
+			record[8] = strconv.Itoa(h.TotalEps)
+			record[9] = episodeList(*h)
+			updated = h.Timestamp
+		}
+		if l := r.watchlist; l != nil {
+			record[0] = l.Provider
+			if record[2] == "" {
+				record[2] = l.Title
+				record[3] = l.URL
+			}
+			record[4] = string(l.Status)
+			if l.Score > 0 {
+				record[5] = strconv.Itoa(l.Score)
+			}
+			record[6] = l.Notes
+			record[7] = strings.Join(l.Tags, ";")
+			if l.UpdatedAt.After(updated) {
+				updated = l.UpdatedAt
+			}
+		}
+		record[10] = updated.Format(time.RFC3339)
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,379 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/scraper"
+)
+
+var aniCLIEpisodeCount = regexp.MustCompile(`\s*\((\d+) episodes?\)\s*$`)
+
+func Decompress(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+func DetectFormat(path string, data []byte) string {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ".gz")
+	switch filepath.Ext(name) {
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	case ".xml":
+		return FormatMAL
+	}
+	if strings.Contains(name, "ani-hsts") {
+		return FormatAniCLI
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatJSON
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatMAL
+	}
+
+	firstLine, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	if bytes.Contains(firstLine, []byte("\t")) {
+		return FormatAniCLI
+	}
+	return FormatCSV
+}
+
+func Parse(data []byte, format string, modTime time.Time) ([]Record, error) {
+	switch format {
+	case FormatJSON:
+		return parseJSON(data)
+	case FormatCSV:
+		return parseCSV(data, modTime)
+	case FormatMAL:
+		return parseMAL(data, modTime)
+	case FormatAniCLI:
+		return parseAniCLI(data, modTime)
+	default:
+		return nil, fmt.Errorf("unsupported import format '%s'", format)
+	}
+}
+
+func parseJSON(data []byte) ([]Record, error) {
+	var probe struct {
+		Version *int              `json:"karu_export"`
+		Entries []json.RawMessage `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var document Document
+	switch {
+	case probe.Version != nil:
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("invalid karu export: %w", err)
+		}
+
+	case len(probe.Entries) > 0 && bytes.Contains(probe.Entries[0], []byte(`"status"`)):
+		var watchlist config.Watchlist
+		if err := json.Unmarshal(data, &watchlist); err != nil {
+			return nil, fmt.Errorf("invalid karu watchlist: %w", err)
+		}
+		document.Watchlist = watchlist.Entries
+
+	default:
+		history, _, err := config.ParseHistory(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid karu history: %w", err)
+		}
+		document.History = history.Entries
+	}
+
+	records := make(map[string]*Record)
+	var order []string
+	get := func(showID, title, url string) *Record {
+		if record, exists := records[showID]; exists {
+			return record
+		}
+		records[showID] = &Record{ShowID: showID, Title: title, URL: url}
+		order = append(order, showID)
+		return records[showID]
+	}
+
+	for i := range document.History {
+		entry := document.History[i]
+		if entry.ShowID == "" {
+			continue
+		}
+		record := get(entry.ShowID, entry.Title, entry.URL)
+		record.History = &entry
+		record.TotalEps = entry.TotalEps
+		if entry.Timestamp.After(record.UpdatedAt) {
+			record.UpdatedAt = entry.Timestamp
+		}
+	}
+	for i := range document.Watchlist {
+		entry := document.Watchlist[i]
+		if entry.ShowID == "" {
+			continue
+		}
+		record := get(entry.ShowID, entry.Title, entry.URL)
+		record.Watchlist = &entry
+		if entry.UpdatedAt.After(record.UpdatedAt) {
+			record.UpdatedAt = entry.UpdatedAt
+		}
+	}
+
+	result := make([]Record, len(order))
+	for i, showID := range order {
+		result[i] = *records[showID]
+	}
+	return result, nil
+}
+
+func parseCSV(data []byte, modTime time.Time) ([]Record, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["show_id"]; !ok {
+		if _, ok := columns["url"]; !ok {
+			return nil, fmt.Errorf("CSV needs a show_id or url column")
+		}
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var records []Record
+	for line, row := range rows[1:] {
+		record := Record{
+			ShowID:    field(row, "show_id"),
+			Title:     field(row, "title"),
+			URL:       field(row, "url"),
+			Notes:     field(row, "notes"),
+			UpdatedAt: modTime,
+		}
+		if record.ShowID == "" {
+			record.ShowID = config.ShowIDFromURL(record.URL)
+		}
+		if record.URL == "" && record.ShowID != "" {
+			record.URL = scraper.ShowURL(record.ShowID)
+		}
+
+		if value := field(row, "status"); value != "" {
+			status, err := config.ParseWatchStatus(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line+2, err)
+			}
+			record.Status = status
+		}
+		if value := field(row, "score"); value != "" {
+			record.Score, _ = strconv.Atoi(value)
+		}
+		if value := field(row, "tags"); value != "" {
+			record.Tags = strings.Split(value, ";")
+		}
+		if value := field(row, "total_episodes"); value != "" {
+			record.TotalEps, _ = strconv.Atoi(value)
+		}
+		if value := field(row, "updated_at"); value != "" {
+			if updated, err := time.Parse(time.RFC3339, value); err == nil {
+				record.UpdatedAt = updated
+			}
+		}
+		if value := field(row, "watched_episodes"); value != "" {
+			record.Episodes = make(map[string]*config.EpisodeRecord)
+			for _, episode := range strings.Split(value, ";") {
+				if episode = strings.TrimSpace(episode); episode != "" {
+					record.Episodes[episode] = &config.EpisodeRecord{
+						FirstWatched: record.UpdatedAt,
+						LastWatched:  record.UpdatedAt,
+						Completed:    true,
+						WatchCount:   1,
+					}
+				}
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+type malExport struct {
+	Anime []struct {
+		ID         string `xml:"series_animedb_id"`
+		Title      string `xml:"series_title"`
+		Episodes   int    `xml:"series_episodes"`
+		Watched    int    `xml:"my_watched_episodes"`
+		Score      int    `xml:"my_score"`
+		Status     string `xml:"my_status"`
+		Comments   string `xml:"my_comments"`
+		Tags       string `xml:"my_tags"`
+		StartDate  string `xml:"my_start_date"`
+		FinishDate string `xml:"my_finish_date"`
+	} `xml:"anime"`
+}
+
+func parseMAL(data []byte, modTime time.Time) ([]Record, error) {
+	var export malExport
+	if err := xml.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid MyAnimeList export: %w", err)
+	}
+
+	records := make([]Record, 0, len(export.Anime))
+	for _, anime := range export.Anime {
+		record := Record{
+			Title:     strings.TrimSpace(anime.Title),
+			MalID:     strings.TrimSpace(anime.ID),
+			TotalEps:  anime.Episodes,
+			Progress:  anime.Watched,
+			Score:     anime.Score,
+			Notes:     strings.TrimSpace(anime.Comments),
+			UpdatedAt: modTime,
+		}
+
+		for _, date := range []string{anime.FinishDate, anime.StartDate} {
+			if parsed, err := time.Parse("2006-01-02", date); err == nil {
+				record.UpdatedAt = parsed
+				break
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(anime.Status)) {
+		case "watching", "1":
+			record.Status = config.StatusWatching
+		case "completed", "2":
+			record.Status = config.StatusCompleted
+		case "on-hold", "3":
+			record.Status = config.StatusOnHold
+		case "dropped", "4":
+			record.Status = config.StatusDropped
+		case "plan to watch", "6":
+			record.Status = config.StatusPlanned
+		}
+
+		for _, tag := range strings.Split(anime.Tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				record.Tags = append(record.Tags, tag)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func parseAniCLI(data []byte, modTime time.Time) ([]Record, error) {
+	var records []Record
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		parts := strings.SplitN(text, "\t", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("line %d: expected episode, id and title separated by tabs", line)
+		}
+
+		record := Record{
+			ShowID:    strings.TrimSpace(parts[1]),
+			Title:     strings.TrimSpace(parts[2]),
+			UpdatedAt: modTime,
+		}
+		record.URL = scraper.ShowURL(record.ShowID)
+
+		if match := aniCLIEpisodeCount.FindStringSubmatch(record.Title); match != nil {
+			record.TotalEps, _ = strconv.Atoi(match[1])
+			record.Title = strings.TrimSpace(aniCLIEpisodeCount.ReplaceAllString(record.Title, ""))
+		}
+
+		if episode, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err == nil {
+			record.Progress = int(episode)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+func Resolve(records []Record, progress func(title string)) {
+	for i := range records {
+		record := &records[i]
+		if record.ShowID != "" || record.Title == "" {
+			continue
+		}
+		if progress != nil {
+			progress(record.Title)
+		}
+
+		animes, err := scraper.Search(record.Title)
+		if err != nil || len(animes) == 0 {
+			continue
+		}
+
+		var fallback string
+		for j, anime := range animes {
+			showID := config.ShowIDFromURL(anime.URL)
+			if record.MalID != "" && j < 5 {
+				if info, err := scraper.GetShowInfo(showID); err == nil && info.MalID == record.MalID {
+					record.ShowID = showID
+					break
+				}
+			}
+			if fallback == "" && strings.EqualFold(anime.Title, record.Title) {
+				fallback = showID
+			}
+		}
+
+		if record.ShowID == "" {
+			record.ShowID = fallback
+		}
+		if record.ShowID != "" {
+			record.URL = scraper.ShowURL(record.ShowID)
+		}
+	}
+}
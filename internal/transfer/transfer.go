@@ -0,0 +1,295 @@
+package transfer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+	FormatMAL    = "mal"
+	FormatAniCLI = "ani-cli"
+
+	ConflictNewest   = "newest"
+	ConflictProgress = "progress"
+)
+
+const exportVersion = 1
+
+type Document struct {
+	Version    int                     `json:"karu_export"`
+	ExportedAt time.Time               `json:"exported_at"`
+	History    []config.HistoryEntry   `json:"history"`
+	Watchlist  []config.WatchlistEntry `json:"watchlist"`
+}
+
+type Record struct {
+	ShowID    string
+	Title     string
+	URL       string
+	MalID     string
+	TotalEps  int
+	Progress  int
+	Episodes  map[string]*config.EpisodeRecord
+	UpdatedAt time.Time
+
+	History   *config.HistoryEntry
+	Watchlist *config.WatchlistEntry
+
+	Status config.WatchStatus
+	Score  int
+	Notes  string
+	Tags   []string
+}
+
+func (r Record) watchedCount() int {
+	if r.History != nil {
+		return r.History.WatchedCount()
+	}
+	if len(r.Episodes) > 0 {
+		count := 0
+		for _, record := range r.Episodes {
+			if record != nil && record.Completed {
+				count++
+			}
+		}
+		return count
+	}
+	return r.Progress
+}
+
+type Action string
+
+const (
+	ActionAdd        Action = "add"
+	ActionReplace    Action = "replace"
+	ActionKeep       Action = "keep"
+	ActionUnresolved Action = "unresolved"
+)
+
+type Change struct {
+	Action Action
+	Title  string
+	ShowID string
+	Reason string
+}
+
+type Plan struct {
+	Changes []Change
+
+	history   *config.History
+	watchlist *config.Watchlist
+}
+
+func (p *Plan) Count(action Action) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+func (p *Plan) Apply() error {
+	if err := config.SaveHistory(p.history); err != nil {
+		return err
+	}
+	return config.SaveWatchlist(p.watchlist)
+}
+
+func ValidConflictRule(rule string) error {
+	switch rule {
+	case ConflictNewest, ConflictProgress:
+		return nil
+	}
+	return fmt.Errorf("invalid conflict rule '%s' (must be %s or %s)", rule, ConflictNewest, ConflictProgress)
+}
+
+func BuildPlan(records []Record, rule string, history *config.History, watchlist *config.Watchlist) *Plan {
+	plan := &Plan{history: history, watchlist: watchlist}
+
+	for _, record := range records {
+		if record.ShowID == "" {
+			plan.Changes = append(plan.Changes, Change{
+				Action: ActionUnresolved,
+				Title:  record.Title,
+				Reason: "could not find the show",
+			})
+			continue
+		}
+
+		historyEntry, inHistory := history.Find(record.ShowID)
+		listEntry, inWatchlist := watchlist.Find(record.ShowID)
+
+		change := Change{Title: record.Title, ShowID: record.ShowID}
+		switch {
+		case !inHistory && !inWatchlist:
+			change.Action = ActionAdd
+		case importWins(record, historyEntry, inHistory, listEntry, inWatchlist, rule):
+			change.Action = ActionReplace
+			change.Reason = conflictReason(rule, true)
+		default:
+			change.Action = ActionKeep
+			change.Reason = conflictReason(rule, false)
+		}
+		plan.Changes = append(plan.Changes, change)
+
+		if change.Action == ActionKeep {
+			continue
+		}
+
+		if entry := record.historyEntry(); entry != nil {
+			if inHistory && entry.Query == "" {
+				entry.Query = historyEntry.Query
+				entry.AccessCount += historyEntry.AccessCount
+			}
+			history.Put(*entry)
+		}
+		if entry := record.watchlistEntry(); entry != nil {
+			if inWatchlist && entry.KnownEps == 0 {
+				entry.KnownEps = listEntry.KnownEps
+			}
+			watchlist.Put(*entry)
+		}
+	}
+
+	return plan
+}
+
+func importWins(record Record, historyEntry config.HistoryEntry, inHistory bool, listEntry config.WatchlistEntry, inWatchlist bool, rule string) bool {
+	if rule == ConflictProgress {
+		local := 0
+		if inHistory {
+			local = historyEntry.WatchedCount()
+		}
+		return record.watchedCount() > local
+	}
+
+	local := time.Time{}
+	if inHistory {
+		local = historyEntry.Timestamp
+	}
+	if inWatchlist && listEntry.UpdatedAt.After(local) {
+		local = listEntry.UpdatedAt
+	}
+	return record.UpdatedAt.After(local)
+}
+
+func conflictReason(rule string, wins bool) string {
+	switch {
+	case rule == ConflictProgress && wins:
+		return "imported progress is higher"
+	case rule == ConflictProgress:
+		return "local progress is the same or higher"
+	case wins:
+		return "imported entry is newer"
+	default:
+		return "local entry is newer"
+	}
+}
+
+func (r Record) historyEntry() *config.HistoryEntry {
+	if r.History != nil {
+		entry := *r.History
+		return &entry
+	}
+
+	episodes := r.Episodes
+	if episodes == nil && r.Progress > 0 {
+		episodes = make(map[string]*config.EpisodeRecord)
+		for n := 1; n <= r.Progress; n++ {
+			episodes[strconv.Itoa(n)] = &config.EpisodeRecord{
+				FirstWatched: r.UpdatedAt,
+				LastWatched:  r.UpdatedAt,
+				Completed:    true,
+				WatchCount:   1,
+			}
+		}
+	}
+	if len(episodes) == 0 {
+		return nil
+	}
+
+	entry := &config.HistoryEntry{
+		ShowID:      r.ShowID,
+		Title:       r.Title,
+		URL:         r.URL,
+		Episodes:    episodes,
+		TotalEps:    r.TotalEps,
+		Timestamp:   r.UpdatedAt,
+		AccessCount: 1,
+	}
+	entry.LastEpisode = lastEpisode(episodes)
+	return entry
+}
+
+func (r Record) watchlistEntry() *config.WatchlistEntry {
+	if r.Watchlist != nil {
+		entry := *r.Watchlist
+		return &entry
+	}
+	if r.Status == "" {
+		return nil
+	}
+
+	entry := &config.WatchlistEntry{
+		ShowID:    r.ShowID,
+		Title:     r.Title,
+		URL:       r.URL,
+		Score:     r.Score,
+		Notes:     r.Notes,
+		Tags:      r.Tags,
+		AddedAt:   r.UpdatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+	entry.SetStatus(r.Status)
+	if entry.CompletedAt != nil {
+		completed := r.UpdatedAt
+		entry.CompletedAt = &completed
+	}
+	return entry
+}
+
+func lastEpisode(episodes map[string]*config.EpisodeRecord) string {
+	var last string
+	var lastTime time.Time
+	keys := make([]string, 0, len(episodes))
+	for episode := range episodes {
+		keys = append(keys, episode)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		numI, _ := strconv.ParseFloat(keys[i], 64)
+		numJ, _ := strconv.ParseFloat(keys[j], 64)
+		return numI < numJ
+	})
+	for _, episode := range keys {
+		record := episodes[episode]
+		if record != nil && !record.LastWatched.Before(lastTime) {
+			last = episode
+			lastTime = record.LastWatched
+		}
+	}
+	return last
+}
+
+func episodeList(entry config.HistoryEntry) string {
+	keys := make([]string, 0, len(entry.Episodes))
+	for episode, record := range entry.Episodes {
+		if record != nil && record.Completed {
+			keys = append(keys, episode)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		numI, _ := strconv.ParseFloat(keys[i], 64)
+		numJ, _ := strconv.ParseFloat(keys[j], 64)
+		return numI < numJ
+	})
+	return strings.Join(keys, ";")
+}
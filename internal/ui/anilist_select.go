@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/keircn/karu/internal/anilist"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+func SelectAniListMedia(media []anilist.Media) (*anilist.Media, error) {
+	items := make([]list.Item, len(media))
+	for i, m := range media {
+		var details []string
+		if m.Format != "" {
+			details = append(details, m.Format)
+		}
+		if m.SeasonYear > 0 {
+			details = append(details, fmt.Sprintf("%d", m.SeasonYear))
+		}
+		if m.Episodes > 0 {
+			details = append(details, fmt.Sprintf("%d episodes", m.Episodes))
+		}
+		details = append(details, fmt.Sprintf("id %d", m.ID))
+		items[i] = ui.NewGenericItem(m.Title.Preferred(), strings.Join(details, " • "), m)
+	}
+
+	model := ui.NewListModel(items, "Select the AniList entry")
+	result, err := ui.RunSelection(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	m := result.(anilist.Media)
+	return &m, nil
+}
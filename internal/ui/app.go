@@ -0,0 +1,322 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+type screen interface {
+	Init() tea.Cmd
+	Update(msg tea.Msg) (screen, tea.Cmd)
+	View() string
+	Title() string
+	// Capturing reports whether the screen handles msg itself instead of
+	// the app's navigation keys, for example while typing.
+	Capturing(msg tea.KeyMsg) bool
+}
+
+// Closed screens are never kept for forward navigation.
+type closer interface {
+	Close()
+}
+
+type sender interface {
+	setSend(send func(tea.Msg))
+}
+
+type AppOptions struct {
+	Query       string
+	AutoQuality bool
+	// The app is suspended while Download runs so it can report progress on
+	// the terminal. Downloading is not offered when it is nil.
+	Download func(showID, title string, episodes []string) (int, error)
+}
+
+type appModel struct {
+	opts    AppOptions
+	stack   []screen
+	forward []screen
+	size    tea.WindowSizeMsg
+	send    func(tea.Msg)
+}
+
+type pushMsg struct {
+	screen screen
+}
+
+type backMsg struct{}
+
+type popToMsg struct {
+	match func(screen) bool
+}
+
+type loadedMsg struct {
+	id    int64
+	value interface{}
+	err   error
+}
+
+const appChromeHeight = 4
+
+var lastScreenID atomic.Int64
+
+func push(s screen) tea.Cmd {
+	return func() tea.Msg { return pushMsg{screen: s} }
+}
+
+func back() tea.Msg {
+	return backMsg{}
+}
+
+// popTo goes back to the nearest screen that match accepts, or a single
+// screen when none does.
+func popTo(match func(screen) bool) tea.Cmd {
+	return func() tea.Msg { return popToMsg{match: match} }
+}
+
+func RunApp(opts AppOptions) error {
+	app := &appModel{opts: opts}
+	app.stack = []screen{newSearchScreen(opts)}
+	if opts.Query != "" {
+		app.stack = append(app.stack, newResultsScreen(opts, opts.Query))
+	}
+
+	p := tea.NewProgram(app, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
+	app.send = p.Send
+	for _, s := range app.stack {
+		if s, ok := s.(sender); ok {
+			s.setSend(p.Send)
+		}
+	}
+
+	_, err := p.Run()
+	app.closeAll()
+	return err
+}
+
+func (m *appModel) Init() tea.Cmd {
+	cmds := make([]tea.Cmd, len(m.stack))
+	for i, s := range m.stack {
+		cmds[i] = s.Init()
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *appModel) top() screen {
+	return m.stack[len(m.stack)-1]
+}
+
+func (m *appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.size = msg
+		return m, m.broadcast(m.screenSize())
+
+	case tea.KeyMsg:
+		if msg.String() == ui.ForceQuitKey {
+			return m, tea.Quit
+		}
+
+		if !m.top().Capturing(msg) {
+			switch {
+			case key.Matches(msg, ui.Keys.App.Back):
+				return m, m.back()
+			case key.Matches(msg, ui.Keys.App.Forward):
+				return m, m.goForward()
+			case key.Matches(msg, ui.Keys.App.Quit):
+				return m, tea.Quit
+			}
+		}
+
+		updated, cmd := m.top().Update(msg)
+		m.stack[len(m.stack)-1] = updated
+		return m, cmd
+
+	case pushMsg:
+		return m, m.push(msg.screen)
+
+	case backMsg:
+		return m, m.back()
+
+	case popToMsg:
+		return m, m.popTo(msg.match)
+
+	case list.FilterMatchesMsg:
+		// Filter results do not say which list they are for, and only the
+		// screen on top can be filtering.
+		updated, cmd := m.top().Update(msg)
+		m.stack[len(m.stack)-1] = updated
+		return m, cmd
+	}
+
+	return m, m.broadcast(msg)
+}
+
+// broadcast delivers msg to every screen, including those reachable with
+// forward, so background results and window sizes are never lost.
+func (m *appModel) broadcast(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, screens := range [][]screen{m.stack, m.forward} {
+		for i, s := range screens {
+			updated, cmd := s.Update(msg)
+			screens[i] = updated
+			cmds = append(cmds, cmd)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *appModel) push(s screen) tea.Cmd {
+	for _, discarded := range m.forward {
+		closeScreen(discarded)
+	}
+	m.forward = nil
+
+	if s, ok := s.(sender); ok {
+		s.setSend(m.send)
+	}
+	s, sizeCmd := s.Update(m.screenSize())
+	m.stack = append(m.stack, s)
+	return tea.Batch(sizeCmd, s.Init(), clearImages())
+}
+
+func (m *appModel) back() tea.Cmd {
+	if len(m.stack) == 1 {
+		return tea.Quit
+	}
+
+	popped := m.top()
+	m.stack = m.stack[:len(m.stack)-1]
+	if _, ok := popped.(closer); ok {
+		closeScreen(popped)
+		return clearImages()
+	}
+	m.forward = append(m.forward, popped)
+	return clearImages()
+}
+
+// The screens popped on the way are finished with, so unlike back they are
+// not kept for forward.
+func (m *appModel) popTo(match func(screen) bool) tea.Cmd {
+	i := len(m.stack) - 2
+	for i >= 0 && !match(m.stack[i]) {
+		i--
+	}
+	if i < 0 {
+		return m.back()
+	}
+
+	for _, screens := range [][]screen{m.stack[i+1:], m.forward} {
+		for _, s := range screens {
+			closeScreen(s)
+		}
+	}
+	m.stack = m.stack[:i+1]
+	m.forward = nil
+	return clearImages()
+}
+
+func (m *appModel) goForward() tea.Cmd {
+	if len(m.forward) == 0 {
+		return nil
+	}
+
+	next := m.forward[len(m.forward)-1]
+	m.forward = m.forward[:len(m.forward)-1]
+	m.stack = append(m.stack, next)
+	return clearImages()
+}
+
+func (m *appModel) closeAll() {
+	for _, screens := range [][]screen{m.stack, m.forward} {
+		for _, s := range screens {
+			closeScreen(s)
+		}
+	}
+}
+
+func closeScreen(s screen) {
+	if s, ok := s.(closer); ok {
+		s.Close()
+	}
+}
+
+func (m *appModel) screenSize() tea.WindowSizeMsg {
+	return tea.WindowSizeMsg{Width: m.size.Width, Height: max(m.size.Height-appChromeHeight, 0)}
+}
+
+func (m *appModel) View() string {
+	titles := make([]string, len(m.stack))
+	for i, s := range m.stack {
+		titles[i] = s.Title()
+	}
+	crumbs := ui.SubtleStyle.Render(strings.Join(titles[:len(titles)-1], " › "))
+	if len(titles) > 1 {
+		crumbs += ui.SubtleStyle.Render(" › ")
+	}
+	crumbs += ui.AccentStyle.Render(titles[len(titles)-1])
+
+	keys := ui.Keys.App
+	forward := keys.Forward
+	forward.SetEnabled(len(m.forward) > 0)
+	footer := ui.MutedStyle.Render(ui.HelpLine(keys.Back, forward, keys.Quit))
+
+	return "\n  " + crumbs + "\n" + m.top().View() + "\n  " + footer + "\n"
+}
+
+type loader struct {
+	id      int64
+	spinner spinner.Model
+	loading bool
+	err     error
+}
+
+func newLoader() loader {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.AccentStyle
+	return loader{id: lastScreenID.Add(1), spinner: s}
+}
+
+func (l *loader) load(fn func() (interface{}, error)) tea.Cmd {
+	l.loading = true
+	l.err = nil
+	id := l.id
+	return tea.Batch(l.spinner.Tick, func() tea.Msg {
+		value, err := fn()
+		return loadedMsg{id: id, value: value, err: err}
+	})
+}
+
+func (l *loader) update(msg tea.Msg) (value interface{}, done bool, cmd tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if l.loading {
+			l.spinner, cmd = l.spinner.Update(msg)
+		}
+	case loadedMsg:
+		if msg.id == l.id {
+			l.loading = false
+			l.err = msg.err
+			return msg.value, msg.err == nil, nil
+		}
+	}
+	return nil, false, cmd
+}
+
+func (l loader) view(label string) string {
+	switch {
+	case l.loading:
+		return "\n  " + l.spinner.View() + " " + label + "\n"
+	case l.err != nil:
+		return "\n  " + ui.ErrorStyle.Render("✗ "+l.err.Error()) + "\n"
+	}
+	return ""
+}
@@ -0,0 +1,779 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/images"
+	"github.com/keircn/karu/internal/player"
+	"github.com/keircn/karu/internal/releases"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+type playSelection struct {
+	anime    scraper.Anime
+	showID   string
+	malID    string
+	episodes []string
+	playlist bool
+}
+
+type listScreen struct {
+	list      list.Model
+	covers    bool
+	showCover bool
+}
+
+func newListScreen(title string) listScreen {
+	l := ui.NewList(nil, title)
+	l.DisableQuitKeybindings()
+	l.StatusMessageLifetime = 3 * time.Second
+	return listScreen{list: l}
+}
+
+func (s *listScreen) resize(msg tea.WindowSizeMsg) {
+	h, v := ui.AppStyle.GetFrameSize()
+	width := msg.Width - h
+	s.showCover = s.covers && images.Enabled() && msg.Width >= minCoverWidth
+	if s.showCover {
+		width -= coverCols + coverGap
+	}
+	s.list.SetSize(width, msg.Height-v)
+}
+
+func (s *listScreen) render(coverURL string) string {
+	view := s.list.View()
+	if s.showCover {
+		rows := min(coverRows, s.list.Height())
+		view = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(s.list.Width()).Render(view),
+			strings.Repeat(" ", coverGap),
+			coverView(coverURL, coverCols, rows))
+	}
+	return ui.AppStyle.Render(view)
+}
+
+func (s *listScreen) pageItems() []list.Item {
+	items := s.list.VisibleItems()
+	start, end := s.list.Paginator.GetSliceBounds(len(items))
+	return items[start:end]
+}
+
+func (s *listScreen) Capturing(msg tea.KeyMsg) bool {
+	switch s.list.FilterState() {
+	case list.Filtering:
+		return true
+	case list.FilterApplied:
+		return key.Matches(msg, s.list.KeyMap.ClearFilter)
+	}
+	return false
+}
+
+func (s *listScreen) selected(msg tea.Msg) (interface{}, bool) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || s.list.FilterState() == list.Filtering || !key.Matches(keyMsg, ui.Keys.List.Select) {
+		return nil, false
+	}
+	if item, ok := s.list.SelectedItem().(ui.SelectableItem); ok {
+		return item.GetValue(), true
+	}
+	return nil, false
+}
+
+func (s *listScreen) updateList(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		s.resize(msg)
+		return nil
+	}
+
+	var cmd tea.Cmd
+	s.list, cmd = s.list.Update(msg)
+	return cmd
+}
+
+type searchScreen struct {
+	opts   AppOptions
+	search liveSearch
+}
+
+func newSearchScreen(opts AppOptions) *searchScreen {
+	return &searchScreen{opts: opts, search: newLiveSearch(opts.Query)}
+}
+
+func (s *searchScreen) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (s *searchScreen) Title() string {
+	return "Search"
+}
+
+func (s *searchScreen) Capturing(msg tea.KeyMsg) bool {
+	return !key.Matches(msg, ui.Keys.App.Back, ui.Keys.App.Forward)
+}
+
+func (s *searchScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	choice, cmd := s.search.update(msg)
+	if choice == nil {
+		return s, cmd
+	}
+
+	s.search.stop()
+	if choice.Anime != nil {
+		return s, push(newDetailsScreen(s.opts, choice.Query, *choice.Anime))
+	}
+	return s, push(newResultsScreen(s.opts, choice.Query))
+}
+
+func (s *searchScreen) View() string {
+	return fmt.Sprintf("\n  %s\n\n%s\n  %s\n",
+		ui.AccentStyle.Render("What anime would you like to search for?"),
+		s.search.view("  "),
+		ui.SubtleStyle.Render(searchHelp()))
+}
+
+func (s *searchScreen) Close() {
+	s.search.stop()
+}
+
+type resultsScreen struct {
+	listScreen
+	loader
+	opts   AppOptions
+	query  string
+	size   tea.WindowSizeMsg
+	notice string
+}
+
+func newResultsScreen(opts AppOptions, query string) *resultsScreen {
+	s := &resultsScreen{
+		listScreen: newListScreen(fmt.Sprintf("Results for '%s'", query)),
+		loader:     newLoader(),
+		opts:       opts,
+		query:      query,
+	}
+	s.covers = true
+	return s
+}
+
+func (s *resultsScreen) Init() tea.Cmd {
+	query := s.query
+	return s.load(func() (interface{}, error) {
+		results, err := scraper.SearchWithSuggestions(query)
+		if err != nil {
+			return nil, err
+		}
+		if len(results.Animes) == 0 {
+			if len(results.Suggestions) > 0 {
+				return nil, fmt.Errorf("no anime found for '%s', did you mean %s?", query, strings.Join(results.Suggestions, ", "))
+			}
+			return nil, fmt.Errorf("no anime found for '%s'", query)
+		}
+		return results, nil
+	})
+}
+
+func (s *resultsScreen) Title() string {
+	return "Results"
+}
+
+func (s *resultsScreen) layout() {
+	size := s.size
+	if s.notice != "" {
+		size.Height -= 2
+	}
+	s.resize(size)
+}
+
+func (s *resultsScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		s.size = msg
+		s.layout()
+		return s, s.fetchCovers()
+	}
+
+	value, done, cmd := s.loader.update(msg)
+	if done {
+		results := value.(*scraper.SearchResults)
+		items := make([]list.Item, len(results.Animes))
+		for i, anime := range results.Animes {
+			items[i] = ui.NewGenericItem(anime.Title, anime.URL, anime)
+		}
+		if results.Query != s.query {
+			s.list.Title = fmt.Sprintf("Results for '%s'", results.Query)
+			s.notice = fmt.Sprintf("No results for '%s'", s.query)
+			if len(results.Suggestions) > 0 {
+				s.notice += ", did you mean " + strings.Join(results.Suggestions, ", ") + "?"
+			}
+			s.layout()
+		}
+		return s, tea.Batch(s.list.SetItems(items), s.fetchCovers())
+	}
+	if cmd != nil || s.loading {
+		return s, cmd
+	}
+
+	if value, ok := s.selected(msg); ok {
+		return s, push(newDetailsScreen(s.opts, s.query, value.(scraper.Anime)))
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.Capturing(msg) && key.Matches(msg, ui.Keys.Anime.Watchlist) {
+		if item, ok := s.list.SelectedItem().(ui.SelectableItem); ok {
+			anime := item.GetValue().(scraper.Anime)
+			status := AddToWatchlist(config.ShowIDFromURL(anime.URL), anime.Title, anime.URL, config.StatusPlanned)
+			return s, s.list.NewStatusMessage(status)
+		}
+	}
+
+	cmd = s.updateList(msg)
+	return s, tea.Batch(cmd, s.fetchCovers())
+}
+
+// Only the current page is fetched, so results that are never seen cost
+// nothing.
+func (s *resultsScreen) fetchCovers() tea.Cmd {
+	if !s.showCover {
+		return nil
+	}
+	var urls []string
+	for _, item := range s.pageItems() {
+		if item, ok := item.(ui.SelectableItem); ok {
+			urls = append(urls, item.GetValue().(scraper.Anime).Thumbnail)
+		}
+	}
+	return fetchImages(urls...)
+}
+
+func (s *resultsScreen) View() string {
+	if view := s.loader.view("Searching for " + s.query + "..."); view != "" {
+		return view
+	}
+	var cover string
+	if item, ok := s.list.SelectedItem().(ui.SelectableItem); ok {
+		cover = item.GetValue().(scraper.Anime).Thumbnail
+	}
+	if s.notice != "" {
+		return "\n  " + ui.SubtleStyle.Render(s.notice) + "\n" + s.render(cover)
+	}
+	return s.render(cover)
+}
+
+type detailsScreen struct {
+	loader
+	opts      AppOptions
+	query     string
+	selection playSelection
+	status    string
+	width     int
+}
+
+func newDetailsScreen(opts AppOptions, query string, anime scraper.Anime) *detailsScreen {
+	return &detailsScreen{
+		loader: newLoader(),
+		opts:   opts,
+		query:  query,
+		selection: playSelection{
+			anime:  anime,
+			showID: config.ShowIDFromURL(anime.URL),
+		},
+	}
+}
+
+func (s *detailsScreen) Init() tea.Cmd {
+	query, selection := s.query, s.selection
+	return s.load(func() (interface{}, error) {
+		episodes, err := scraper.GetEpisodes(selection.showID)
+		if err != nil {
+			return nil, err
+		}
+		if len(episodes) == 0 {
+			return nil, fmt.Errorf("no episodes found for this anime")
+		}
+
+		if info, err := scraper.GetShowInfo(selection.showID); err == nil {
+			selection.malID = info.MalID
+			if selection.anime.Thumbnail == "" {
+				selection.anime.Thumbnail = info.Thumbnail
+			}
+		}
+		selection.episodes = releases.SortEpisodes(episodes)
+
+		if history, err := config.LoadHistory(); err == nil {
+			history.AddEntry(selection.showID, query, selection.anime.Title, selection.anime.URL, len(episodes))
+		}
+		return selection, nil
+	})
+}
+
+func (s *detailsScreen) Title() string {
+	title := []rune(s.selection.anime.Title)
+	if len(title) > 30 {
+		return string(title[:29]) + "…"
+	}
+	return string(title)
+}
+
+func (s *detailsScreen) Capturing(msg tea.KeyMsg) bool {
+	return false
+}
+
+func (s *detailsScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		s.width = msg.Width
+		return s, nil
+	}
+
+	value, done, cmd := s.loader.update(msg)
+	if done {
+		s.selection = value.(playSelection)
+		return s, fetchImages(s.selection.anime.Thumbnail)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || s.loading || s.err != nil {
+		return s, cmd
+	}
+
+	keys := ui.Keys
+	switch {
+	case key.Matches(keyMsg, keys.List.Select):
+		return s, push(newEpisodesScreen(s.opts, s.selection))
+	case key.Matches(keyMsg, keys.Episodes.Watchlist):
+		s.status = AddToWatchlist(s.selection.showID, s.selection.anime.Title, scraper.ShowURL(s.selection.showID), config.StatusWatching)
+	case key.Matches(keyMsg, keys.Episodes.Resume):
+		if episode, ok := resumeEpisode(s.selection); ok {
+			return s, playEpisode(s.opts, s.selection, episode)
+		}
+		s.status = "Nothing to resume yet"
+	}
+	return s, nil
+}
+
+func (s *detailsScreen) View() string {
+	if view := s.loader.view("Loading " + s.selection.anime.Title + "..."); view != "" {
+		return view
+	}
+
+	sel := s.selection
+	lines := []string{
+		ui.AccentStyle.Bold(true).Render(sel.anime.Title),
+		"",
+		fmt.Sprintf("Show ID:   %s", sel.showID),
+		fmt.Sprintf("Episodes:  %d available", len(sel.episodes)),
+	}
+	if sel.malID != "" {
+		lines = append(lines, fmt.Sprintf("MAL ID:    %s", sel.malID))
+	}
+
+	if history, err := config.LoadHistory(); err == nil {
+		if entry, exists := history.Find(sel.showID); exists && entry.LastEpisode != "" {
+			lines = append(lines, fmt.Sprintf("Watched:   %d of %d (last: episode %s)", entry.WatchedCount(), len(sel.episodes), entry.LastEpisode))
+		}
+	}
+	if watchlist, err := config.LoadWatchlist(); err == nil {
+		if entry, exists := watchlist.Find(sel.showID); exists {
+			lines = append(lines, "Watchlist: "+DescribeWatchlistEntry(entry))
+		}
+	}
+
+	keys := ui.Keys
+	help := []key.Binding{
+		key.NewBinding(key.WithKeys(keys.List.Select.Keys()...), key.WithHelp(keys.List.Select.Help().Key, "episodes")),
+		keys.Episodes.Watchlist,
+	}
+	if episode, ok := resumeEpisode(sel); ok {
+		resume := keys.Episodes.Resume
+		resume.SetHelp(resume.Help().Key, "resume episode "+episode)
+		help = append(help, resume)
+	}
+	lines = append(lines, "", ui.MutedStyle.Render(ui.HelpLine(help...)))
+	if s.status != "" {
+		lines = append(lines, ui.StatusStyle.Render(s.status))
+	}
+
+	view := strings.Join(lines, "\n")
+	if s.width >= minCoverWidth {
+		if cover := coverView(sel.anime.Thumbnail, coverCols, coverRows); cover != "" {
+			view = lipgloss.JoinHorizontal(lipgloss.Top, cover, strings.Repeat(" ", coverGap), view)
+		}
+	}
+	return "\n  " + strings.ReplaceAll(view, "\n", "\n  ") + "\n"
+}
+
+type episodesScreen struct {
+	listScreen
+	opts      AppOptions
+	selection playSelection
+	marks     episodeMarks
+}
+
+func newEpisodesScreen(opts AppOptions, selection playSelection) *episodesScreen {
+	s := &episodesScreen{listScreen: newListScreen("Select an episode"), opts: opts, selection: selection, marks: make(episodeMarks)}
+	s.covers = true
+	s.list.SetItems(s.items())
+	keys := func() []key.Binding {
+		return append([]key.Binding{ui.Keys.Episodes.Watchlist, ui.Keys.Episodes.Resume}, episodeSelectionKeys()...)
+	}
+	s.list.AdditionalShortHelpKeys = keys
+	s.list.AdditionalFullHelpKeys = keys
+	return s
+}
+
+func (s *episodesScreen) items() []list.Item {
+	history, _ := config.LoadHistory()
+
+	items := make([]list.Item, len(s.selection.episodes))
+	for i, episode := range s.selection.episodes {
+		record, _ := history.GetEpisode(s.selection.showID, episode)
+		items[i] = episodeItem{title: episode, record: record, marked: s.marks[episode]}
+	}
+	return items
+}
+
+func (s *episodesScreen) Init() tea.Cmd {
+	return fetchImages(s.selection.anime.Thumbnail)
+}
+
+func (s *episodesScreen) Title() string {
+	return "Episodes"
+}
+
+func (s *episodesScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	if msg, ok := msg.(batchDoneMsg); ok && msg.showID == s.selection.showID {
+		clear(s.marks)
+		return s, tea.Batch(s.list.SetItems(s.items()), s.list.NewStatusMessage(msg.status))
+	}
+	if msg, ok := msg.(playbackDoneMsg); ok && msg.showID == s.selection.showID {
+		return s, s.played(msg.episode)
+	}
+
+	if len(s.marks) > 0 {
+		if msg, ok := msg.(tea.KeyMsg); ok && !s.Capturing(msg) && key.Matches(msg, ui.Keys.List.Select) {
+			return s, push(newBatchScreen(s.opts, s.selection, s.marks.episodes(&s.list)))
+		}
+	}
+	if value, ok := s.selected(msg); ok {
+		return s, playEpisode(s.opts, s.selection, value.(string))
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.Capturing(msg) {
+		if handled, cmd := s.marks.handle(&s.list, msg); handled {
+			return s, cmd
+		}
+
+		switch {
+		case key.Matches(msg, ui.Keys.Episodes.Watchlist):
+			status := AddToWatchlist(s.selection.showID, s.selection.anime.Title, scraper.ShowURL(s.selection.showID), config.StatusWatching)
+			return s, s.list.NewStatusMessage(status)
+		case key.Matches(msg, ui.Keys.Episodes.Resume):
+			if episode, ok := resumeEpisode(s.selection); ok {
+				return s, playEpisode(s.opts, s.selection, episode)
+			}
+			return s, s.list.NewStatusMessage("Nothing to resume yet")
+		}
+	}
+
+	return s, s.updateList(msg)
+}
+
+// played refreshes the watched marks and moves the cursor past the episode
+// that just finished. Items are replaced one by one, as SetItems would drop
+// the filtered view until it is rebuilt.
+func (s *episodesScreen) played(episode string) tea.Cmd {
+	var cmd tea.Cmd
+	for i, item := range s.items() {
+		cmd = s.list.SetItem(i, item)
+	}
+
+	visible := s.list.VisibleItems()
+	for i, item := range visible {
+		if item.(episodeItem).title == episode && i+1 < len(visible) {
+			s.list.Select(i + 1)
+			break
+		}
+	}
+	return cmd
+}
+
+func (s *episodesScreen) View() string {
+	return s.render(s.selection.anime.Thumbnail)
+}
+
+type playbackDoneMsg struct {
+	showID  string
+	episode string
+}
+
+type batchDoneMsg struct {
+	showID string
+	status string
+}
+
+type batchScreen struct {
+	listScreen
+	opts      AppOptions
+	selection playSelection
+	episodes  []string
+}
+
+func newBatchScreen(opts AppOptions, selection playSelection, episodes []string) *batchScreen {
+	actions := batchActions
+	if opts.Download == nil {
+		actions = slices.DeleteFunc(slices.Clone(actions), func(a EpisodeAction) bool { return a == ActionDownload })
+	}
+
+	s := &batchScreen{
+		listScreen: newListScreen("What should happen to the selected episodes?"),
+		opts:       opts,
+		selection:  selection,
+		episodes:   episodes,
+	}
+	s.list.SetItems(batchActionItems(actions, len(episodes)))
+	return s
+}
+
+func (s *batchScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (s *batchScreen) Title() string {
+	return fmt.Sprintf("%d episodes", len(s.episodes))
+}
+
+func (s *batchScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	value, ok := s.selected(msg)
+	if !ok {
+		return s, s.updateList(msg)
+	}
+
+	showID, title, episodes := s.selection.showID, s.selection.anime.Title, s.episodes
+	done := func(status string) tea.Cmd {
+		return tea.Sequence(back, func() tea.Msg { return batchDoneMsg{showID: showID, status: status} })
+	}
+
+	switch action := value.(EpisodeAction); action {
+	case ActionPlaylist:
+		playlist := s.selection
+		playlist.episodes = episodes
+		playlist.playlist = true
+		return s, playEpisode(s.opts, playlist, episodes[0])
+
+	case ActionDownload:
+		download := s.opts.Download
+		var downloaded int
+		run := funcCommand(func() (err error) {
+			downloaded, err = download(showID, title, episodes)
+			return err
+		})
+		return s, tea.Batch(back, tea.Exec(run, func(err error) tea.Msg {
+			if err != nil {
+				return batchDoneMsg{showID: showID, status: ui.ErrorStyle.Render("✗ " + err.Error())}
+			}
+			return batchDoneMsg{showID: showID, status: fmt.Sprintf("Downloaded %d of %d episodes", downloaded, len(episodes))}
+		}))
+
+	case ActionQueue:
+		if err := config.QueueDownloads(showID, title, episodes); err != nil {
+			return s, s.list.NewStatusMessage(ui.ErrorStyle.Render("✗ " + err.Error()))
+		}
+		return s, done(fmt.Sprintf("Queued %d episodes for download", len(episodes)))
+
+	case ActionMarkWatched, ActionMarkUnwatched:
+		history, err := config.LoadHistory()
+		if err == nil {
+			err = history.SetWatched(showID, episodes, action == ActionMarkWatched)
+		}
+		if err != nil {
+			return s, s.list.NewStatusMessage(ui.ErrorStyle.Render("✗ " + err.Error()))
+		}
+		state := "watched"
+		if action == ActionMarkUnwatched {
+			state = "unwatched"
+		}
+		return s, done(fmt.Sprintf("Marked %d episodes as %s", len(episodes), state))
+	}
+	return s, nil
+}
+
+func (s *batchScreen) View() string {
+	return ui.AppStyle.Render(s.list.View())
+}
+
+// funcCommand runs a function as a tea.ExecCommand, so it can write to the
+// terminal while the app is suspended.
+type funcCommand func() error
+
+func (f funcCommand) Run() error          { return f() }
+func (f funcCommand) SetStdin(io.Reader)  {}
+func (f funcCommand) SetStdout(io.Writer) {}
+func (f funcCommand) SetStderr(io.Writer) {}
+
+type qualityScreen struct {
+	listScreen
+	loader
+	selection playSelection
+	episode   string
+}
+
+func newQualityScreen(selection playSelection, episode string) *qualityScreen {
+	return &qualityScreen{
+		listScreen: newListScreen("Select video quality"),
+		loader:     newLoader(),
+		selection:  selection,
+		episode:    episode,
+	}
+}
+
+func (s *qualityScreen) Init() tea.Cmd {
+	showID, episode := s.selection.showID, s.episode
+	return s.load(func() (interface{}, error) {
+		qualities, err := scraper.GetAvailableQualities(showID, episode)
+		if err == nil && len(qualities.Options) == 0 {
+			err = fmt.Errorf("no video sources found for episode %s", episode)
+		}
+		return qualities, err
+	})
+}
+
+func (s *qualityScreen) Title() string {
+	return "Episode " + s.episode
+}
+
+func (s *qualityScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		s.resize(msg)
+		return s, nil
+	}
+
+	value, done, cmd := s.loader.update(msg)
+	if done {
+		qualities := value.(*scraper.QualityChoice)
+		items := make([]list.Item, len(qualities.Options))
+		for i, option := range qualities.Options {
+			items[i] = qualityItem{option: option}
+		}
+		setCmd := s.list.SetItems(items)
+		s.list.Select(qualities.Default)
+		return s, setCmd
+	}
+	if cmd != nil || s.loading {
+		return s, cmd
+	}
+
+	if value, ok := s.selected(msg); ok {
+		option := value.(scraper.QualityOption)
+		return s, push(newPlayingScreen(s.selection, s.episode, option.URL, option.Quality))
+	}
+	return s, s.updateList(msg)
+}
+
+func (s *qualityScreen) View() string {
+	if view := s.loader.view("Loading sources for episode " + s.episode + "..."); view != "" {
+		return view
+	}
+	return ui.AppStyle.Render(s.list.View())
+}
+
+type playingScreen struct {
+	selection playSelection
+	episode   string
+	videoURL  string
+	quality   string
+	session   *player.Session
+	err       error
+}
+
+func newPlayingScreen(selection playSelection, episode, videoURL, quality string) *playingScreen {
+	return &playingScreen{selection: selection, episode: episode, videoURL: videoURL, quality: quality}
+}
+
+func (s *playingScreen) setSend(send func(tea.Msg)) {
+	quality := s.quality
+	info := &player.PlaybackInfo{
+		ShowID:    s.selection.showID,
+		ShowTitle: s.selection.anime.Title,
+		Episodes:  s.selection.episodes,
+		Current:   s.episode,
+		VideoURL:  s.videoURL,
+		MalID:     s.selection.malID,
+		Playlist:  s.selection.playlist,
+	}
+
+	s.session, s.err = player.NewSession(info, func(showID, episode string) (string, error) {
+		return scraper.GetVideoURLWithQuality(showID, episode, quality)
+	})
+	if s.err == nil {
+		s.session.Start(send)
+	}
+}
+
+func (s *playingScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (s *playingScreen) Title() string {
+	return "Now playing"
+}
+
+func (s *playingScreen) Capturing(msg tea.KeyMsg) bool {
+	return !key.Matches(msg, ui.Keys.App.Back)
+}
+
+func (s *playingScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	if s.session == nil {
+		return s, nil
+	}
+	if _, ok := msg.(player.FinishedMsg); ok {
+		if s.session.Quitting() {
+			return s, back
+		}
+		showID, episode := s.selection.showID, s.session.Episode()
+		return s, tea.Sequence(popTo(func(s screen) bool {
+			_, ok := s.(*episodesScreen)
+			return ok
+		}), func() tea.Msg { return playbackDoneMsg{showID: showID, episode: episode} })
+	}
+
+	_, cmd := s.session.Update(msg)
+	return s, cmd
+}
+
+func (s *playingScreen) View() string {
+	if s.err != nil {
+		return "\n  " + ui.ErrorStyle.Render("✗ "+s.err.Error()) + "\n"
+	}
+	return "\n" + ui.AppStyle.Render(s.session.View())
+}
+
+func (s *playingScreen) Close() {
+	if s.session != nil {
+		s.session.Close()
+	}
+}
+
+func playEpisode(opts AppOptions, selection playSelection, episode string) tea.Cmd {
+	scraper.PreloadAdjacentEpisodes(selection.showID, selection.episodes, episode)
+
+	if opts.AutoQuality {
+		cfg, _ := config.Load()
+		return push(newPlayingScreen(selection, episode, "", cfg.Quality))
+	}
+	return push(newQualityScreen(selection, episode))
+}
+
+func resumeEpisode(selection playSelection) (string, bool) {
+	history, err := config.LoadHistory()
+	if err != nil {
+		return "", false
+	}
+	return history.GetResumeEpisode(selection.showID, selection.episodes)
+}
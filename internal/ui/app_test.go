@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/keircn/karu/internal/player"
+	"github.com/keircn/karu/internal/scraper"
+)
+
+var (
+	backKey = tea.KeyMsg{Type: tea.KeyLeft, Alt: true}
+	downKey = tea.KeyMsg{Type: tea.KeyDown}
+)
+
+type navigation struct {
+	t   *testing.T
+	app *appModel
+}
+
+// newNavigation starts an app on the search screen. History and config are
+// kept in a temporary directory so the user's own are never touched.
+func newNavigation(t *testing.T) *navigation {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	opts := AppOptions{Query: "frieren"}
+	app := &appModel{opts: opts, stack: []screen{newSearchScreen(opts)}}
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	return &navigation{t: t, app: app}
+}
+
+// push adds s the way a screen's command would. Its Init command is not run,
+// so nothing is fetched; results are delivered with load instead.
+func (n *navigation) push(s screen) {
+	n.app.Update(pushMsg{screen: s})
+}
+
+func (n *navigation) load(l *loader, value interface{}) {
+	n.app.Update(loadedMsg{id: l.id, value: value})
+}
+
+func (n *navigation) press(msgs ...tea.KeyMsg) {
+	for _, msg := range msgs {
+		n.app.Update(msg)
+	}
+}
+
+func (n *navigation) back(want screen) {
+	n.t.Helper()
+	n.press(backKey)
+	if got := n.app.top(); got != want {
+		n.t.Fatalf("back showed %s, want %s", got.Title(), want.Title())
+	}
+}
+
+// run delivers the messages of cmd to the app in order, which is enough for
+// the commands screens return without doing any I/O.
+func (n *navigation) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	msg := cmd()
+	// Sequences have no exported type, but hold commands just as a batch does.
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice && v.Type().ConvertibleTo(reflect.TypeOf(tea.BatchMsg{})) {
+		for _, cmd := range v.Convert(reflect.TypeOf(tea.BatchMsg{})).Interface().(tea.BatchMsg) {
+			n.run(cmd)
+		}
+		return
+	}
+	_, cmd = n.app.Update(msg)
+	n.run(cmd)
+}
+
+func selected(l list.Model) string {
+	if item := l.SelectedItem(); item != nil {
+		return item.FilterValue()
+	}
+	return ""
+}
+
+func checkList(t *testing.T, name string, l list.Model, filter, want string) {
+	t.Helper()
+	if l.FilterState() != list.FilterApplied || l.FilterValue() != filter {
+		t.Errorf("%s filter = %q (%s), want %q applied", name, l.FilterValue(), l.FilterState(), filter)
+	}
+	if got := selected(l); got != want {
+		t.Errorf("%s selected %q, want %q", name, got, want)
+	}
+}
+
+func testSelection() playSelection {
+	return playSelection{
+		anime:    scraper.Anime{Title: "Frieren", URL: "https://example.com/frieren"},
+		showID:   "frieren",
+		episodes: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"},
+	}
+}
+
+// openEpisodes goes from search through results and details to a filtered
+// episode list, with the cursor on episode 11.
+func (n *navigation) openEpisodes() (*resultsScreen, *detailsScreen, *episodesScreen) {
+	selection := testSelection()
+
+	results := newResultsScreen(n.app.opts, "frieren")
+	n.push(results)
+	n.load(&results.loader, &scraper.SearchResults{Query: "frieren", Animes: []scraper.Anime{
+		{Title: "Sousou no Frieren", URL: "https://example.com/sousou"},
+		{Title: "Frieren", URL: selection.anime.URL},
+		{Title: "Frieren Specials", URL: "https://example.com/specials"},
+	}})
+	results.list.SetFilterText("frieren")
+	n.press(downKey)
+
+	details := newDetailsScreen(n.app.opts, "frieren", selection.anime)
+	n.push(details)
+	n.load(&details.loader, selection)
+
+	episodes := newEpisodesScreen(n.app.opts, selection)
+	n.push(episodes)
+	episodes.list.SetFilterText("1")
+	n.press(downKey, downKey)
+	return results, details, episodes
+}
+
+func TestBackRestoresEachScreen(t *testing.T) {
+	n := newNavigation(t)
+	search := n.app.top().(*searchScreen)
+	results, details, episodes := n.openEpisodes()
+	anime := selected(results.list)
+
+	quality := newQualityScreen(episodes.selection, "11")
+	n.push(quality)
+	n.load(&quality.loader, &scraper.QualityChoice{Options: []scraper.QualityOption{
+		{Quality: "1080p", URL: "https://example.com/1080.m3u8"},
+		{Quality: "720p", URL: "https://example.com/720.m3u8"},
+		{Quality: "480p", URL: "https://example.com/480.m3u8"},
+	}})
+	n.press(downKey)
+
+	// Pushing would start the player, so the playing screen is added as it
+	// is once the player has started.
+	n.app.stack = append(n.app.stack, newPlayingScreen(episodes.selection, "11", "", "720p"))
+
+	n.back(quality)
+	if got := selected(quality.list); got != "720p" {
+		t.Errorf("quality selected %q, want 720p", got)
+	}
+
+	n.back(episodes)
+	checkList(t, "episodes", episodes.list, "1", "11")
+
+	n.back(details)
+	if len(details.selection.episodes) != 12 {
+		t.Errorf("details lists %d episodes, want 12", len(details.selection.episodes))
+	}
+
+	n.back(results)
+	checkList(t, "results", results.list, "frieren", anime)
+
+	n.back(search)
+	if query := search.search.query(); query != "frieren" {
+		t.Errorf("search query = %q, want frieren", query)
+	}
+}
+
+func TestPlaybackEndReturnsToNextEpisode(t *testing.T) {
+	n := newNavigation(t)
+	_, _, episodes := n.openEpisodes()
+
+	quality := newQualityScreen(episodes.selection, "11")
+	n.push(quality)
+
+	playing := newPlayingScreen(episodes.selection, "11", "", "720p")
+	session, err := player.NewSession(&player.PlaybackInfo{
+		ShowID:   episodes.selection.showID,
+		Episodes: episodes.selection.episodes,
+		Current:  "11",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playing.session = session
+	n.app.stack = append(n.app.stack, playing)
+
+	_, cmd := n.app.Update(player.FinishedMsg{})
+	n.run(cmd)
+
+	if top := n.app.top(); top != episodes {
+		t.Fatalf("playback end showed %s, want the episode list", top.Title())
+	}
+	if len(n.app.forward) != 0 {
+		t.Errorf("%d screens kept for forward after playback ended", len(n.app.forward))
+	}
+	checkList(t, "episodes", episodes.list, "1", "12")
+}
@@ -8,9 +8,10 @@ import (
 type BrowseMode string
 
 const (
-	BrowseModeSearch   BrowseMode = "search"
-	BrowseModePopular  BrowseMode = "catalog"
-	BrowseModeTrending BrowseMode = "recent"
+	BrowseModeSearch    BrowseMode = "search"
+	BrowseModePopular   BrowseMode = "catalog"
+	BrowseModeTrending  BrowseMode = "recent"
+	BrowseModeWatchlist BrowseMode = "watchlist"
 )
 
 func SelectBrowseMode() (*BrowseMode, error) {
@@ -18,6 +19,7 @@ func SelectBrowseMode() (*BrowseMode, error) {
 		ui.NewGenericItem("Search for anime", string(BrowseModeSearch), BrowseModeSearch),
 		ui.NewGenericItem("Browse recent anime", string(BrowseModeTrending), BrowseModeTrending),
 		ui.NewGenericItem("Browse anime catalog", string(BrowseModePopular), BrowseModePopular),
+		ui.NewGenericItem("Browse your watchlist", string(BrowseModeWatchlist), BrowseModeWatchlist),
 	}
 
 	model := ui.NewListModel(items, "Browse Anime")
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/keircn/karu/internal/cast"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+func SelectRenderer(renderers []*cast.Renderer) (*cast.Renderer, error) {
+	if len(renderers) == 1 {
+		return renderers[0], nil
+	}
+
+	items := make([]list.Item, len(renderers))
+	for i, renderer := range renderers {
+		items[i] = ui.NewGenericItem(renderer.Name, renderer.Model, renderer)
+	}
+
+	model := ui.NewListModel(items, "Select a device")
+	result, err := ui.RunSelection(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	return result.(*cast.Renderer), nil
+}
@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+var (
+	editorKeyStyle   = lipgloss.NewStyle().Width(26)
+	editorValueStyle = lipgloss.NewStyle().Width(41)
+)
+
+type configEditorModel struct {
+	cfg      *config.Config
+	fields   []config.Field
+	inputs   []textinput.Model
+	original []string
+	errs     []error
+
+	cursor  int
+	offset  int
+	height  int
+	message string
+
+	saved    bool
+	quitting bool
+}
+
+func newConfigEditorModel(cfg *config.Config) configEditorModel {
+	fields := config.Fields()
+	m := configEditorModel{
+		cfg:      cfg,
+		fields:   fields,
+		inputs:   make([]textinput.Model, len(fields)),
+		original: make([]string, len(fields)),
+		errs:     make([]error, len(fields)),
+		height:   20,
+	}
+
+	for i, field := range fields {
+		input := textinput.New()
+		input.Prompt = ""
+		input.CharLimit = 512
+		input.Width = 40
+		input.SetValue(cfg.Get(field.Key))
+		m.inputs[i] = input
+		m.original[i] = input.Value()
+	}
+
+	m.focus(0)
+	return m
+}
+
+func (m configEditorModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *configEditorModel) focus(index int) {
+	m.inputs[m.cursor].Blur()
+	m.cursor = (index + len(m.inputs)) % len(m.inputs)
+	if m.fields[m.cursor].Allowed == nil {
+		m.inputs[m.cursor].Focus()
+		m.inputs[m.cursor].CursorEnd()
+	}
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.visibleRows() {
+		m.offset = m.cursor - m.visibleRows() + 1
+	}
+}
+
+func (m configEditorModel) visibleRows() int {
+	return max(m.height-10, 5)
+}
+
+func (m *configEditorModel) validate(index int) {
+	field := m.fields[index]
+	value := m.inputs[index].Value()
+	if value == m.original[index] && field.Key == "player" {
+		m.errs[index] = nil
+		return
+	}
+	m.errs[index] = config.CheckValue(field.Key, value)
+}
+
+func (m *configEditorModel) cycle(step int) {
+	allowed := m.fields[m.cursor].Allowed
+	if len(allowed) == 0 {
+		return
+	}
+
+	i := slices.Index(allowed, strings.ToLower(m.inputs[m.cursor].Value()))
+	i = (i + step + len(allowed)) % len(allowed)
+	m.inputs[m.cursor].SetValue(allowed[i])
+	m.validate(m.cursor)
+}
+
+func (m configEditorModel) hasErrors() bool {
+	for _, err := range m.errs {
+		if err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (m configEditorModel) changes() map[string]string {
+	changes := make(map[string]string)
+	for i, field := range m.fields {
+		if value := m.inputs[i].Value(); value != m.original[i] {
+			changes[field.Key] = value
+		}
+	}
+	return changes
+}
+
+func (m configEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.focus(m.cursor)
+		return m, nil
+
+	case tea.KeyMsg:
+		m.message = ""
+		keys := ui.Keys.Editor
+		switch {
+		case msg.String() == ui.ForceQuitKey || key.Matches(msg, keys.Cancel):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, keys.Save):
+			if m.hasErrors() {
+				m.message = "Fix the highlighted values before saving"
+				return m, nil
+			}
+			m.saved = true
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, keys.Up):
+			m.focus(m.cursor - 1)
+			return m, nil
+		case key.Matches(msg, keys.Down):
+			m.focus(m.cursor + 1)
+			return m, nil
+		case key.Matches(msg, keys.Reset):
+			m.inputs[m.cursor].SetValue(m.fields[m.cursor].Default())
+			m.validate(m.cursor)
+			return m, nil
+		}
+
+		if m.fields[m.cursor].Allowed != nil {
+			switch {
+			case key.Matches(msg, keys.PrevChoice):
+				m.cycle(-1)
+			case key.Matches(msg, keys.NextChoice):
+				m.cycle(1)
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	before := m.inputs[m.cursor].Value()
+	m.inputs[m.cursor], cmd = m.inputs[m.cursor].Update(msg)
+	if m.inputs[m.cursor].Value() != before {
+		m.validate(m.cursor)
+	}
+	return m, cmd
+}
+
+func (m configEditorModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n" + ui.AccentStyle.Render("Karu configuration") + "\n\n")
+
+	end := min(m.offset+m.visibleRows(), len(m.fields))
+	for i := m.offset; i < end; i++ {
+		field := m.fields[i]
+
+		cursor := "  "
+		key := editorKeyStyle.Render(field.Key)
+		if i == m.cursor {
+			cursor = ui.AccentStyle.Render("> ")
+			key = ui.AccentStyle.Render(editorKeyStyle.Render(field.Key))
+		}
+
+		value := m.inputs[i].View()
+		if field.Allowed != nil {
+			value = m.inputs[i].Value()
+			if i == m.cursor {
+				value = "◀ " + value + " ▶"
+			}
+			value = editorValueStyle.Render(value)
+		}
+
+		marker := " "
+		switch {
+		case m.errs[i] != nil:
+			marker = ui.ErrorStyle.Render("✗")
+		case m.inputs[i].Value() != m.original[i]:
+			marker = ui.WarningStyle.Render("•")
+		}
+
+		b.WriteString(fmt.Sprintf("%s%s %s %s %s\n", cursor, marker, key, value, ui.SubtleStyle.Render(field.Type())))
+	}
+
+	field := m.fields[m.cursor]
+	b.WriteString("\n" + field.Description + "\n")
+
+	details := []string{"Type: " + field.Type(), "Default: " + displayValue(field.Default())}
+	if field.Type() == "choice" {
+		details = append(details, "Allowed: "+strings.Join(field.Allowed, ", "))
+	}
+	origin := m.cfg.Origin(field.Key)
+	if origin.IsOverride() {
+		details = append(details, "Overridden by "+origin.String())
+	}
+	b.WriteString(ui.SubtleStyle.Render(strings.Join(details, " · ")) + "\n")
+
+	if err := m.errs[m.cursor]; err != nil {
+		b.WriteString(ui.ErrorStyle.Render(err.Error()) + "\n")
+	} else if m.message != "" {
+		b.WriteString(ui.ErrorStyle.Render(m.message) + "\n")
+	} else {
+		b.WriteString("\n")
+	}
+
+	keys := ui.Keys.Editor
+	b.WriteString("\n" + ui.SubtleStyle.Render(ui.HelpLine(keys.Up, keys.Down, keys.PrevChoice, keys.NextChoice, keys.Reset, keys.Save, keys.Cancel)) + "\n")
+	return b.String()
+}
+
+func displayValue(value string) string {
+	if value == "" {
+		return "(empty)"
+	}
+	return value
+}
+
+func EditConfig(cfg *config.Config) (map[string]string, error) {
+	p := tea.NewProgram(newConfigEditorModel(cfg), tea.WithOutput(os.Stderr))
+	m, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	if model, ok := m.(configEditorModel); ok && model.saved {
+		return model.changes(), nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+type EpisodeAction string
+
+const (
+	ActionPlay          EpisodeAction = "play"
+	ActionPlaylist      EpisodeAction = "playlist"
+	ActionDownload      EpisodeAction = "download"
+	ActionQueue         EpisodeAction = "queue"
+	ActionMarkWatched   EpisodeAction = "mark-watched"
+	ActionMarkUnwatched EpisodeAction = "mark-unwatched"
+)
+
+var batchActions = []EpisodeAction{
+	ActionPlaylist,
+	ActionDownload,
+	ActionQueue,
+	ActionMarkWatched,
+	ActionMarkUnwatched,
+}
+
+type EpisodeBatch struct {
+	Episodes []string
+	Action   EpisodeAction
+}
+
+func (a EpisodeAction) label(count int) string {
+	switch a {
+	case ActionPlaylist:
+		return fmt.Sprintf("Play %d episodes as a playlist", count)
+	case ActionDownload:
+		return fmt.Sprintf("Download %d episodes", count)
+	case ActionQueue:
+		return fmt.Sprintf("Queue %d episodes for download", count)
+	case ActionMarkWatched:
+		return fmt.Sprintf("Mark %d episodes as watched", count)
+	case ActionMarkUnwatched:
+		return fmt.Sprintf("Mark %d episodes as unwatched", count)
+	}
+	return string(a)
+}
+
+func batchActionItems(actions []EpisodeAction, count int) []list.Item {
+	items := make([]list.Item, len(actions))
+	for i, action := range actions {
+		items[i] = ui.NewGenericItem(action.label(count), "", action)
+	}
+	return items
+}
+
+func SelectEpisodeAction(count int) (EpisodeAction, error) {
+	model := ui.NewListModel(batchActionItems(batchActions, count), "What should happen to the selected episodes?")
+	choice, err := ui.RunSelection(model)
+	if err != nil || choice == nil {
+		return "", err
+	}
+	return choice.(EpisodeAction), nil
+}
+
+type episodeMarks map[string]bool
+
+func episodeSelectionKeys() []key.Binding {
+	keys := ui.Keys.Episodes
+	return []key.Binding{keys.Toggle, keys.ExtendDown, keys.SelectUnwatched, keys.SelectToEnd, keys.ClearSelection}
+}
+
+func (marks episodeMarks) handle(l *list.Model, msg tea.KeyMsg) (bool, tea.Cmd) {
+	keys := ui.Keys.Episodes
+	markCurrent := func() {
+		if item, ok := l.SelectedItem().(episodeItem); ok {
+			marks[item.title] = true
+		}
+	}
+
+	switch {
+	case key.Matches(msg, keys.Toggle):
+		if item, ok := l.SelectedItem().(episodeItem); ok {
+			if marks[item.title] {
+				delete(marks, item.title)
+			} else {
+				marks[item.title] = true
+			}
+		}
+	case key.Matches(msg, keys.ExtendUp):
+		markCurrent()
+		l.CursorUp()
+		markCurrent()
+	case key.Matches(msg, keys.ExtendDown):
+		markCurrent()
+		l.CursorDown()
+		markCurrent()
+	case key.Matches(msg, keys.SelectUnwatched):
+		for _, item := range l.Items() {
+			if item, ok := item.(episodeItem); ok && !item.record.Completed {
+				marks[item.title] = true
+			}
+		}
+	case key.Matches(msg, keys.SelectToEnd):
+		visible := l.VisibleItems()
+		for _, item := range visible[min(l.Index(), len(visible)):] {
+			if item, ok := item.(episodeItem); ok {
+				marks[item.title] = true
+			}
+		}
+	case key.Matches(msg, keys.ClearSelection):
+		clear(marks)
+	default:
+		return false, nil
+	}
+	return true, marks.apply(l)
+}
+
+func (marks episodeMarks) apply(l *list.Model) tea.Cmd {
+	items := l.Items()
+	for i, item := range items {
+		if item, ok := item.(episodeItem); ok {
+			item.marked = marks[item.title]
+			items[i] = item
+		}
+	}
+	return l.SetItems(items)
+}
+
+func (marks episodeMarks) episodes(l *list.Model) []string {
+	var episodes []string
+	for _, item := range l.Items() {
+		if item, ok := item.(episodeItem); ok && marks[item.title] {
+			episodes = append(episodes, item.title)
+		}
+	}
+	return episodes
+}
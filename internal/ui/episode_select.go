@@ -4,28 +4,47 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/scraper"
 	"github.com/keircn/karu/pkg/ui"
 )
 
 type episodeItem struct {
-	title      string
-	watched    bool
-	episodeNum int
+	title  string
+	record config.EpisodeRecord
+	marked bool
 }
 
 func (i episodeItem) Title() string {
-	if i.watched {
-		return fmt.Sprintf("✓ %s", i.title)
+	title := i.title
+	switch {
+	case i.record.Completed:
+		title = "✓ " + title
+	case i.record.InProgress():
+		title = "◐ " + title
 	}
-	return i.title
+	if i.marked {
+		return "● " + title
+	}
+	return title
 }
 
 func (i episodeItem) Description() string {
-	if i.watched {
+	switch {
+	case i.record.Completed && i.record.WatchCount > 1:
+		return fmt.Sprintf("Watched %d times", i.record.WatchCount)
+	case i.record.Completed:
 		return "Watched"
+	case i.record.InProgress() && i.record.Duration > 0:
+		return fmt.Sprintf("In progress %s / %s (%.0f%%)",
+			formatTimestamp(i.record.Position),
+			formatTimestamp(i.record.Duration),
+			i.record.Progress()*100)
+	case i.record.InProgress():
+		return fmt.Sprintf("In progress %s", formatTimestamp(i.record.Position))
 	}
 	return ""
 }
@@ -36,42 +55,35 @@ func (i episodeItem) GetValue() interface{} { return i.title }
 
 type episodeModel struct {
 	ui.ListModel
+	showID    string
 	showTitle string
 	hasResume bool
-	resumeEp  int
+	resumeEp  string
+	// marks is nil unless several episodes can be selected.
+	marks episodeMarks
+	batch []string
 }
 
-func NewEpisodeModel(episodes []string, showTitle string) episodeModel {
+func NewEpisodeModel(episodes []string, showID, showTitle string) episodeModel {
 	history, _ := config.LoadHistory()
 
 	items := make([]list.Item, len(episodes))
-	var hasResume bool
-	var resumeEp int
+	resumeEp, hasResume := history.GetResumeEpisode(showID, episodes)
 
 	for i, ep := range episodes {
-		episodeNum := len(episodes) - i
-		watched := history.IsWatched(showTitle, episodeNum)
-
-		if !hasResume {
-			if lastWatched, exists := history.GetProgress(showTitle); exists {
-				if episodeNum == lastWatched+1 {
-					hasResume = true
-					resumeEp = episodeNum
-				}
-			}
-		}
-
+		record, _ := history.GetEpisode(showID, ep)
 		items[i] = episodeItem{
-			title:      ep,
-			watched:    watched,
-			episodeNum: episodeNum,
+			title:  ep,
+			record: record,
 		}
 	}
 
 	baseModel := ui.NewListModel(items, "Select an episode")
+	baseModel.AddHelpKeys(ui.Keys.Episodes.Watchlist, ui.Keys.Episodes.Resume)
 
 	return episodeModel{
 		ListModel: baseModel,
+		showID:    showID,
 		showTitle: showTitle,
 		hasResume: hasResume,
 		resumeEp:  resumeEp,
@@ -81,9 +93,25 @@ func NewEpisodeModel(episodes []string, showTitle string) episodeModel {
 func (m episodeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch keypress := msg.String(); keypress {
-		case "r":
-			if m.hasResume {
+		if m.marks != nil && !m.Filtering() {
+			if handled, cmd := m.marks.handle(m.List(), msg); handled {
+				return m, cmd
+			}
+			if key.Matches(msg, ui.Keys.List.Select) && len(m.marks) > 0 {
+				m.batch = m.marks.episodes(m.List())
+				return m, tea.Quit
+			}
+		}
+
+		switch {
+		case key.Matches(msg, ui.Keys.Episodes.Watchlist):
+			if !m.Filtering() {
+				status := AddToWatchlist(m.showID, m.showTitle, scraper.ShowURL(m.showID), config.StatusWatching)
+				return m, m.StatusMessage(status)
+			}
+		case key.Matches(msg, ui.Keys.Episodes.Resume):
+			if m.hasResume && !m.Filtering() {
+				m.SetChoice(m.resumeEp)
 				return m, tea.Quit
 			}
 		}
@@ -96,37 +124,78 @@ func (m episodeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m episodeModel) View() string {
 	baseView := m.ListModel.View()
-	if baseView == "" {
+	if baseView == "" || len(m.batch) > 0 {
 		return ""
 	}
 
 	if m.hasResume {
-		baseView += fmt.Sprintf("\n\nPress 'r' to resume from episode %d", m.resumeEp)
+		baseView += fmt.Sprintf("\n\nPress '%s' to resume from episode %s", ui.Keys.Episodes.Resume.Help().Key, m.resumeEp)
 	}
 
 	return baseView
 }
 
-func SelectEpisode(episodes []string, showTitle string) (*string, error) {
+func SelectEpisodes(episodes []string, showID, showTitle string) (*EpisodeBatch, error) {
+	episodeModel, err := runEpisodeModel(episodes, showID, showTitle, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(episodeModel.batch) > 0 {
+		action, err := SelectEpisodeAction(len(episodeModel.batch))
+		if err != nil || action == "" {
+			return nil, err
+		}
+		return &EpisodeBatch{Episodes: episodeModel.batch, Action: action}, nil
+	}
+
+	episode, ok := episodeModel.GetChoice().(string)
+	if !ok || episode == "" {
+		return nil, nil
+	}
+	return &EpisodeBatch{Episodes: []string{episode}, Action: ActionPlay}, nil
+}
+
+func runEpisodeModel(episodes []string, showID, showTitle string, multi bool) (episodeModel, error) {
 	if len(episodes) == 0 {
-		return nil, fmt.Errorf("no episodes available")
+		return episodeModel{}, fmt.Errorf("no episodes available")
 	}
 
 	for i, j := 0, len(episodes)-1; i < j; i, j = i+1, j-1 {
 		episodes[i], episodes[j] = episodes[j], episodes[i]
 	}
 
-	m := NewEpisodeModel(episodes, showTitle)
+	m := NewEpisodeModel(episodes, showID, showTitle)
+	if multi {
+		m.marks = make(episodeMarks)
+		m.AddHelpKeys(append([]key.Binding{ui.Keys.Episodes.Watchlist, ui.Keys.Episodes.Resume}, episodeSelectionKeys()...)...)
+	}
 	p := tea.NewProgram(m, tea.WithOutput(os.Stderr))
 
 	finalModel, err := p.Run()
 	if err != nil {
-		return nil, fmt.Errorf("episode selection failed: %v", err)
+		return episodeModel{}, fmt.Errorf("episode selection failed: %v", err)
 	}
 
-	episodeModel, ok := finalModel.(episodeModel)
+	result, ok := finalModel.(episodeModel)
 	if !ok {
-		return nil, fmt.Errorf("unexpected model type in episode selection")
+		return episodeModel{}, fmt.Errorf("unexpected model type in episode selection")
+	}
+	return result, nil
+}
+
+func formatTimestamp(seconds float64) string {
+	total := int(seconds)
+	if total >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", total/3600, total%3600/60, total%60)
+	}
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+func SelectEpisode(episodes []string, showID, showTitle string) (*string, error) {
+	episodeModel, err := runEpisodeModel(episodes, showID, showTitle, false)
+	if err != nil {
+		return nil, err
 	}
 
 	result := episodeModel.GetChoice()
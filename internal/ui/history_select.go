@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -23,7 +24,7 @@ func (i historyItem) FilterValue() string {
 func (i historyItem) Title() string {
 	progress := ""
 	if i.entry.TotalEps > 0 {
-		progress = fmt.Sprintf(" (%d/%d)", i.entry.LastWatched, i.entry.TotalEps)
+		progress = fmt.Sprintf(" (%d/%d)", i.entry.WatchedCount(), i.entry.TotalEps)
 	}
 	return i.entry.Title + progress
 }
@@ -46,12 +47,16 @@ func NewHistoryModel(entries []config.HistoryEntry) HistoryModel {
 		items[i] = historyItem{entry: entry}
 	}
 
-	l := list.New(items, list.NewDefaultDelegate(), 80, 20)
+	l := list.New(items, ui.NewDelegate(), 80, 20)
 	l.Title = "Search History"
 	l.SetShowStatusBar(true)
 	l.Styles.Title = ui.TitleStyle
 	l.Styles.PaginationStyle = ui.PaginationStyle
 	l.Styles.HelpStyle = ui.HelpStyle
+	l.KeyMap.CursorUp = ui.Keys.List.Up
+	l.KeyMap.CursorDown = ui.Keys.List.Down
+	l.KeyMap.Filter = ui.Keys.List.Filter
+	l.KeyMap.Quit = ui.Keys.List.Quit
 
 	return HistoryModel{list: l}
 }
@@ -67,12 +72,16 @@ func (m HistoryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
-		switch keypress := msg.String(); keypress {
-		case "q", "ctrl+c", "esc":
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch {
+		case msg.String() == ui.ForceQuitKey || (key.Matches(msg, ui.Keys.List.Quit) && m.list.FilterState() != list.FilterApplied):
 			m.quitting = true
 			return m, tea.Quit
 
-		case "enter":
+		case key.Matches(msg, ui.Keys.List.Select):
 			if i, ok := m.list.SelectedItem().(historyItem); ok {
 				m.selected = i.entry
 				m.quitting = true
@@ -123,22 +132,22 @@ func (m HistoryOptionsModel) Init() tea.Cmd {
 func (m HistoryOptionsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q", "esc":
+		switch {
+		case msg.String() == ui.ForceQuitKey || key.Matches(msg, ui.Keys.Menu.Quit):
 			m.quitting = true
 			return m, tea.Quit
 
-		case "up", "k":
+		case key.Matches(msg, ui.Keys.Menu.Up):
 			if m.cursor > 0 {
 				m.cursor--
 			}
 
-		case "down", "j":
+		case key.Matches(msg, ui.Keys.Menu.Down):
 			if m.cursor < len(m.options)-1 {
 				m.cursor++
 			}
 
-		case "enter":
+		case key.Matches(msg, ui.Keys.Menu.Select):
 			m.selected = m.options[m.cursor]
 			m.quitting = true
 			return m, tea.Quit
@@ -159,14 +168,14 @@ func (m HistoryOptionsModel) View() string {
 		cursor := " "
 		if m.cursor == i {
 			cursor = ">"
-			option = selectedStyle.Render(option)
+			option = ui.AccentStyle.Bold(true).Render(option)
 		} else {
-			option = unselectedStyle.Render(option)
+			option = ui.SubtleStyle.Render(option)
 		}
 		s += fmt.Sprintf("%s %s\n", cursor, option)
 	}
 
-	s += "\n" + ui.HelpStyle.Render("j/k: move • enter: select • q: quit")
+	s += "\n" + ui.HelpStyle.Render(ui.HelpLine(ui.Keys.Menu.Up, ui.Keys.Menu.Down, ui.Keys.Menu.Select, ui.Keys.Menu.Quit))
 
 	return docStyle.Render(s)
 }
@@ -258,13 +267,4 @@ func formatTimeAgo(t time.Time) string {
 	}
 }
 
-var (
-	selectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
-			Bold(true)
-
-	unselectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-
-	docStyle = lipgloss.NewStyle().Margin(1, 2)
-)
+var docStyle = lipgloss.NewStyle().Margin(1, 2)
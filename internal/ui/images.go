@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/keircn/karu/internal/images"
+	"github.com/keircn/karu/pkg/termimg"
+)
+
+const (
+	coverCols     = 24
+	coverRows     = 16
+	coverGap      = 2
+	minCoverWidth = 80
+)
+
+type imageLoadedMsg struct {
+	url string
+}
+
+func fetchImages(urls ...string) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, url := range urls {
+		if images.Want(url) {
+			cmds = append(cmds, func() tea.Msg {
+				images.Fetch(context.Background(), url)
+				return imageLoadedMsg{url: url}
+			})
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func coverView(url string, cols, rows int) string {
+	cover := images.Render(url, cols, rows)
+	if cover == "" {
+		return ""
+	}
+	lines := strings.Split(cover, "\n")
+	for i, line := range lines {
+		lines[i] = line + strings.Repeat(" ", max(cols-lipgloss.Width(line), 0))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// iTerm2 and sixel images can be left behind when the screen changes.
+func clearImages() tea.Cmd {
+	if !images.Enabled() {
+		return nil
+	}
+	switch images.Protocol() {
+	case termimg.ITerm2, termimg.Sixel:
+		return tea.ClearScreen
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+const (
+	// liveSearchDebounce is how long typing has to pause before the
+	// provider is searched.
+	liveSearchDebounce  = 300 * time.Millisecond
+	liveSearchMinLength = 2
+	maxLocalSuggestions = 4
+	maxSuggestions      = 10
+)
+
+type SearchChoice struct {
+	Query string
+	Anime *scraper.Anime
+}
+
+type suggestion struct {
+	anime scraper.Anime
+	// source is "history" or "cached" for local suggestions and "" for
+	// provider results.
+	source string
+}
+
+type localSuggestionsMsg struct {
+	id          int64
+	suggestions []suggestion
+}
+
+type debounceMsg struct {
+	id int64
+}
+
+type remoteSuggestionsMsg struct {
+	id          int64
+	suggestions []suggestion
+	err         error
+}
+
+var lastQueryID atomic.Int64
+
+type liveSearch struct {
+	input     textinput.Model
+	id        int64
+	local     []suggestion
+	remote    []suggestion
+	cursor    int
+	searching bool
+	err       error
+	cancel    context.CancelFunc
+}
+
+func newLiveSearch(query string) liveSearch {
+	ti := textinput.New()
+	ti.Placeholder = "Enter anime name to search..."
+	ti.Focus()
+	ti.CharLimit = 156
+	ti.Width = 50
+	ti.SetValue(query)
+
+	return liveSearch{input: ti, cursor: -1}
+}
+
+func (s *liveSearch) query() string {
+	return strings.TrimSpace(s.input.Value())
+}
+
+func (s *liveSearch) suggestions() []suggestion {
+	seen := make(map[string]bool)
+	var all []suggestion
+	for _, group := range [][]suggestion{s.local, s.remote} {
+		for _, sug := range group {
+			if len(all) == maxSuggestions {
+				return all
+			}
+			if !seen[sug.anime.URL] {
+				seen[sug.anime.URL] = true
+				all = append(all, sug)
+			}
+		}
+	}
+	return all
+}
+
+func (s *liveSearch) update(msg tea.Msg) (*SearchChoice, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, ui.Keys.Search.Up):
+			s.cursor = max(s.cursor-1, -1)
+			return nil, nil
+		case key.Matches(msg, ui.Keys.Search.Down):
+			s.cursor = min(s.cursor+1, len(s.suggestions())-1)
+			return nil, nil
+		case key.Matches(msg, ui.Keys.Search.Select):
+			if suggestions := s.suggestions(); s.cursor >= 0 && s.cursor < len(suggestions) {
+				anime := suggestions[s.cursor].anime
+				return &SearchChoice{Query: s.query(), Anime: &anime}, nil
+			}
+			if s.query() != "" {
+				return &SearchChoice{Query: s.query()}, nil
+			}
+			return nil, nil
+		}
+
+		before := s.input.Value()
+		var cmd tea.Cmd
+		s.input, cmd = s.input.Update(msg)
+		if s.input.Value() != before {
+			return nil, tea.Batch(cmd, s.changed())
+		}
+		return nil, cmd
+
+	case localSuggestionsMsg:
+		if msg.id == s.id {
+			s.local = msg.suggestions
+		}
+		return nil, nil
+
+	case debounceMsg:
+		if msg.id == s.id {
+			return nil, s.searchRemote()
+		}
+		return nil, nil
+
+	case remoteSuggestionsMsg:
+		if msg.id == s.id {
+			s.searching = false
+			s.remote, s.err = msg.suggestions, msg.err
+		}
+		return nil, nil
+	}
+
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return nil, cmd
+}
+
+// changed starts over for the new query, cancelling any search still
+// running for the old one.
+func (s *liveSearch) changed() tea.Cmd {
+	s.stop()
+	s.id = lastQueryID.Add(1)
+	s.cursor = -1
+	s.remote, s.err = nil, nil
+
+	query := s.query()
+	if query == "" {
+		s.local = nil
+		return nil
+	}
+
+	id := s.id
+	cmds := []tea.Cmd{func() tea.Msg {
+		return localSuggestionsMsg{id: id, suggestions: localSuggestions(query)}
+	}}
+	if len([]rune(query)) >= liveSearchMinLength {
+		cmds = append(cmds, tea.Tick(liveSearchDebounce, func(time.Time) tea.Msg {
+			return debounceMsg{id: id}
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (s *liveSearch) searchRemote() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.searching = true
+
+	id, query := s.id, s.query()
+	return func() tea.Msg {
+		animes, err := scraper.SearchContext(ctx, query)
+		suggestions := make([]suggestion, len(animes))
+		for i, anime := range animes {
+			suggestions[i] = suggestion{anime: anime}
+		}
+		return remoteSuggestionsMsg{id: id, suggestions: suggestions, err: err}
+	}
+}
+
+func (s *liveSearch) stop() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.searching = false
+}
+
+func (s *liveSearch) view(indent string) string {
+	var b strings.Builder
+	b.WriteString(indent + s.input.View() + "\n")
+
+	suggestions := s.suggestions()
+	if len(suggestions) > 0 {
+		b.WriteString("\n")
+	}
+	for i, sug := range suggestions {
+		line := "  " + sug.anime.Title
+		if i == s.cursor {
+			line = ui.AccentStyle.Render("› " + sug.anime.Title)
+		}
+		if sug.source != "" {
+			line += ui.SubtleStyle.Render(" (" + sug.source + ")")
+		}
+		b.WriteString(indent + line + "\n")
+	}
+
+	switch {
+	case s.searching:
+		b.WriteString("\n" + indent + ui.SubtleStyle.Render("Searching...") + "\n")
+	case s.err != nil:
+		b.WriteString("\n" + indent + ui.ErrorStyle.Render("✗ "+s.err.Error()) + "\n")
+	}
+	return b.String()
+}
+
+func searchHelp() string {
+	keys := ui.Keys.Search
+	return ui.HelpLine(keys.Select, keys.Up, keys.Down)
+}
+
+func localSuggestions(query string) []suggestion {
+	var suggestions []suggestion
+	seen := make(map[string]bool)
+	add := func(anime scraper.Anime, source string) {
+		if !seen[anime.URL] && len(suggestions) < 2*maxLocalSuggestions {
+			seen[anime.URL] = true
+			suggestions = append(suggestions, suggestion{anime: anime, source: source})
+		}
+	}
+
+	history, _ := config.LoadHistory()
+	for i, entry := range history.Search(query) {
+		if i == maxLocalSuggestions {
+			break
+		}
+		url := entry.URL
+		if url == "" {
+			url = scraper.ShowURL(entry.ShowID)
+		}
+		add(scraper.Anime{Title: entry.Title, URL: url}, "history")
+	}
+
+	entries, _ := config.SearchCacheByPrefix(query, maxLocalSuggestions)
+	var cached []scraper.Anime
+	for _, entry := range entries {
+		for _, show := range entry.Results {
+			cached = append(cached, scraper.Anime{
+				Title:     show.Title,
+				URL:       scraper.ShowURL(show.ShowID),
+				Episodes:  show.Episodes,
+				AltTitles: show.AltTitles,
+				Thumbnail: show.Thumbnail,
+			})
+		}
+	}
+	for _, anime := range scraper.Rank(query, cached) {
+		add(anime, "cached")
+	}
+
+	return suggestions
+}
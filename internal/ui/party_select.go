@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"net"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+func SelectPartyAddress(addrs []string) (*string, error) {
+	if len(addrs) == 1 {
+		return &addrs[0], nil
+	}
+
+	items := make([]list.Item, len(addrs))
+	for i, addr := range addrs {
+		description := "Reachable from your network"
+		if ip := net.ParseIP(addr); ip != nil && ip.IsLoopback() {
+			description = "Only this machine"
+		}
+		items[i] = ui.NewGenericItem(addr, description, addr)
+	}
+
+	model := ui.NewListModel(items, "Where should the party listen?")
+	result, err := ui.RunSelection(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	addr := result.(string)
+	return &addr, nil
+}
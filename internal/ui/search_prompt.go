@@ -3,36 +3,20 @@ package ui
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
-)
-
-var (
-	focusedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
-	blurredStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	"github.com/keircn/karu/pkg/ui"
 )
 
 type searchModel struct {
-	textInput textinput.Model
-	err       error
-	query     string
-	quitting  bool
+	search   liveSearch
+	choice   SearchChoice
+	quitting bool
 }
 
 func initialSearchModel() searchModel {
-	ti := textinput.New()
-	ti.Placeholder = "Enter anime name to search..."
-	ti.Focus()
-	ti.CharLimit = 156
-	ti.Width = 50
-
-	return searchModel{
-		textInput: ti,
-		err:       nil,
-	}
+	return searchModel{search: newLiveSearch("")}
 }
 
 func (m searchModel) Init() tea.Cmd {
@@ -40,26 +24,19 @@ func (m searchModel) Init() tea.Cmd {
 }
 
 func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyEnter:
-			m.query = strings.TrimSpace(m.textInput.Value())
-			m.quitting = true
-			return m, tea.Quit
-		case tea.KeyCtrlC, tea.KeyEsc:
-			m.quitting = true
-			return m, tea.Quit
-		}
-
-	case error:
-		m.err = msg
-		return m, nil
+	if msg, ok := msg.(tea.KeyMsg); ok && (msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc) {
+		m.search.stop()
+		m.quitting = true
+		return m, tea.Quit
 	}
 
-	m.textInput, cmd = m.textInput.Update(msg)
+	choice, cmd := m.search.update(msg)
+	if choice != nil {
+		m.search.stop()
+		m.choice = *choice
+		m.quitting = true
+		return m, tea.Quit
+	}
 	return m, cmd
 }
 
@@ -69,23 +46,23 @@ func (m searchModel) View() string {
 	}
 
 	return fmt.Sprintf(
-		"\n%s\n\n%s\n\n%s\n",
-		focusedStyle.Render("What anime would you like to search for?"),
-		m.textInput.View(),
-		blurredStyle.Render("Press Enter to search or Esc to quit"),
+		"\n%s\n\n%s\n%s\n",
+		ui.AccentStyle.Render("What anime would you like to search for?"),
+		m.search.view(""),
+		ui.SubtleStyle.Render(searchHelp()+" • esc: quit"),
 	) + "\n"
 }
 
-func PromptForSearch() (string, error) {
+func PromptForSearch() (SearchChoice, error) {
 	p := tea.NewProgram(initialSearchModel(), tea.WithOutput(os.Stderr))
 	m, err := p.Run()
 	if err != nil {
-		return "", err
+		return SearchChoice{}, err
 	}
 
 	if model, ok := m.(searchModel); ok {
-		return model.query, nil
+		return model.choice, nil
 	}
 
-	return "", nil
+	return SearchChoice{}, nil
 }
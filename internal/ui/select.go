@@ -1,23 +1,49 @@
 package ui
 
 import (
+	"os"
+
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/keircn/karu/internal/config"
 	"github.com/keircn/karu/internal/scraper"
 	"github.com/keircn/karu/pkg/ui"
 )
 
+type animeModel struct {
+	ui.ListModel
+}
+
+func (m animeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && !m.Filtering() && key.Matches(msg, ui.Keys.Anime.Watchlist) {
+		if anime, ok := m.SelectedValue().(scraper.Anime); ok {
+			status := AddToWatchlist(config.ShowIDFromURL(anime.URL), anime.Title, anime.URL, config.StatusPlanned)
+			return m, m.StatusMessage(status)
+		}
+	}
+
+	baseModel, cmd := m.ListModel.Update(msg)
+	m.ListModel = baseModel.(ui.ListModel)
+	return m, cmd
+}
+
 func SelectAnime(animes []scraper.Anime) (*scraper.Anime, error) {
 	items := make([]list.Item, len(animes))
 	for i, anime := range animes {
 		items[i] = ui.NewGenericItem(anime.Title, anime.URL, anime)
 	}
 
-	model := ui.NewListModel(items, "Select an anime")
-	result, err := ui.RunSelection(model)
+	model := animeModel{ListModel: ui.NewListModel(items, "Select an anime")}
+	model.AddHelpKeys(ui.Keys.Anime.Watchlist)
+
+	p := tea.NewProgram(model, tea.WithOutput(os.Stderr))
+	finalModel, err := p.Run()
 	if err != nil {
 		return nil, err
 	}
 
+	result := finalModel.(animeModel).GetChoice()
 	if result == nil {
 		return nil, nil
 	}
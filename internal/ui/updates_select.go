@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/keircn/karu/internal/releases"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+func SelectShowUpdate(updates []releases.ShowUpdate) (*releases.ShowUpdate, error) {
+	var items []list.Item
+	for _, update := range updates {
+		if update.NextEpisode == "" {
+			continue
+		}
+
+		title := update.Title
+		if update.HasNew() {
+			title = fmt.Sprintf("%s (+%d new)", update.Title, len(update.NewEpisodes))
+		}
+		description := fmt.Sprintf("Next: episode %s • %d unwatched", update.NextEpisode, update.Unwatched)
+		items = append(items, ui.NewGenericItem(title, description, update))
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	model := ui.NewListModel(items, "Continue watching")
+	result, err := ui.RunSelection(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	update := result.(releases.ShowUpdate)
+	return &update, nil
+}
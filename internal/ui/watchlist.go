@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/pkg/ui"
+)
+
+func AddToWatchlist(showID, title, url string, status config.WatchStatus) string {
+	watchlist, err := config.LoadWatchlist()
+	if err != nil {
+		return fmt.Sprintf("Failed to load watchlist: %v", err)
+	}
+
+	if entry, exists := watchlist.Find(showID); exists {
+		return fmt.Sprintf("%s is already in your watchlist (%s)", entry.Title, entry.Status)
+	}
+
+	if _, err := watchlist.Add(showID, title, url, status); err != nil {
+		return fmt.Sprintf("Failed to save watchlist: %v", err)
+	}
+	return fmt.Sprintf("Added %s to your watchlist as %s", title, status)
+}
+
+func DescribeWatchlistEntry(entry config.WatchlistEntry) string {
+	parts := []string{string(entry.Status)}
+	if entry.Score > 0 {
+		parts = append(parts, fmt.Sprintf("score %d/10", entry.Score))
+	}
+	if len(entry.Tags) > 0 {
+		parts = append(parts, strings.Join(entry.Tags, ", "))
+	}
+	return strings.Join(parts, " • ")
+}
+
+func SelectWatchlistEntry(entries []config.WatchlistEntry) (*config.WatchlistEntry, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("your watchlist is empty")
+	}
+
+	items := make([]list.Item, len(entries))
+	for i, entry := range entries {
+		items[i] = ui.NewGenericItem(entry.Title, DescribeWatchlistEntry(entry), entry)
+	}
+
+	model := ui.NewListModel(items, "Watchlist")
+	result, err := ui.RunSelection(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, nil
+	}
+
+	entry := result.(config.WatchlistEntry)
+	return &entry, nil
+}
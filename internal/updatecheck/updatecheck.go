@@ -0,0 +1,116 @@
+package updatecheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/version"
+	"github.com/keircn/karu/pkg/errors"
+	karuhttp "github.com/keircn/karu/pkg/http"
+)
+
+const (
+	IntervalDaily  = "daily"
+	IntervalWeekly = "weekly"
+	IntervalNever  = "never"
+)
+
+type release struct {
+	TagName    string `json:"tag_name"`
+	HTMLURL    string `json:"html_url"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+func Interval(setting string) time.Duration {
+	switch setting {
+	case IntervalDaily:
+		return 24 * time.Hour
+	case IntervalWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Latest fetches the newest published release from a GitHub releases endpoint
+// and caches the result.
+func Latest(ctx context.Context, endpoint string) (config.UpdateCheck, error) {
+	if endpoint == "" {
+		endpoint = config.DefaultConfig.UpdateEndpoint
+	}
+
+	client := karuhttp.NewClient(
+		karuhttp.WithTimeout(5*time.Second),
+		karuhttp.WithUserAgent("karu/"+version.Get().Version),
+		karuhttp.WithHeader("Accept", "application/vnd.github+json"),
+	)
+
+	resp, err := client.Get(ctx, endpoint)
+	if err != nil {
+		return config.UpdateCheck{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return config.UpdateCheck{}, errors.New(errors.NetworkError, fmt.Sprintf("release check failed with status %d", resp.StatusCode))
+	}
+
+	var latest release
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return config.UpdateCheck{}, errors.Wrap(err, errors.NetworkError, "failed to decode release")
+	}
+	if latest.TagName == "" || latest.Draft || latest.Prerelease {
+		return config.UpdateCheck{}, errors.New(errors.NetworkError, "no published release found")
+	}
+
+	check := config.UpdateCheck{
+		Latest:    latest.TagName,
+		URL:       latest.HTMLURL,
+		CheckedAt: time.Now(),
+	}
+	config.PutUpdateCheck(check)
+	return check, nil
+}
+
+// Due reports whether a background check should run for the given interval
+// setting, returning the cached result either way.
+func Due(setting string) (config.UpdateCheck, bool) {
+	interval := Interval(setting)
+	cached, found := config.GetUpdateCheck()
+	if interval == 0 {
+		return cached, false
+	}
+	return cached, !found || time.Since(cached.CheckedAt) >= interval
+}
+
+// Enabled reports whether automatic checks make sense for this run: a release
+// build writing to a terminal outside CI.
+func Enabled(setting string) bool {
+	if Interval(setting) == 0 || os.Getenv("CI") != "" {
+		return false
+	}
+	if !version.Get().IsRelease() {
+		return false
+	}
+
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func Notice(check config.UpdateCheck, current string) string {
+	if !version.Newer(check.Latest, current) {
+		return ""
+	}
+
+	notice := fmt.Sprintf("A new version of karu is available: %s (current %s)", check.Latest, current)
+	if check.URL != "" {
+		notice += "\n" + check.URL
+	}
+	return notice
+}
@@ -0,0 +1,118 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Set at build time with -ldflags "-X github.com/keircn/karu/internal/version.Version=...".
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+
+	modified := false
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+
+	if len(info.Commit) > 12 {
+		info.Commit = info.Commit[:12]
+	}
+	if modified && info.Commit != "" && !strings.HasSuffix(info.Commit, "-dirty") {
+		info.Commit += "-dirty"
+	}
+	return info
+}
+
+// IsRelease reports whether the running binary was built from a tagged version.
+func (i Info) IsRelease() bool {
+	_, ok := Parse(i.Version)
+	return ok && !strings.Contains(i.Version, "-")
+}
+
+// Parse extracts the numeric parts of a version like v1.2.3 or 1.2.3-rc1.
+func Parse(v string) ([3]int, bool) {
+	var parts [3]int
+
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "-")
+	v, _, _ = strings.Cut(v, "+")
+
+	fields := strings.Split(v, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n := 0
+		if field == "" {
+			return parts, false
+		}
+		for _, r := range field {
+			if r < '0' || r > '9' {
+				return parts, false
+			}
+			n = n*10 + int(r-'0')
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// Newer reports whether latest is a higher version than current.
+func Newer(latest, current string) bool {
+	l, ok := Parse(latest)
+	if !ok {
+		return false
+	}
+	c, ok := Parse(current)
+	if !ok {
+		return false
+	}
+
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
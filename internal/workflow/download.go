@@ -8,14 +8,17 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/hooks"
 	"github.com/keircn/karu/internal/scraper"
 )
 
 type DownloadOptions struct {
 	All       bool
 	Range     string
+	Episodes  []string
 	OutputDir string
 }
 
@@ -24,6 +27,7 @@ type DownloadResult struct {
 	Successful int
 	Failed     int
 	Episodes   []string
+	Downloaded []string
 }
 
 func ParseEpisodeRange(rangeStr string, availableEpisodes []string) ([]string, error) {
@@ -129,6 +133,8 @@ func DownloadEpisodes(selection *AnimeSelection, opts DownloadOptions) (*Downloa
 		if err != nil {
 			return nil, fmt.Errorf("parsing episode range: %w", err)
 		}
+	} else if len(opts.Episodes) > 0 {
+		episodesToDownload = opts.Episodes
 	} else {
 		return nil, fmt.Errorf("no download options specified")
 	}
@@ -158,17 +164,49 @@ func DownloadEpisodes(selection *AnimeSelection, opts DownloadOptions) (*Downloa
 			strings.ReplaceAll(selection.Anime.Title, " ", "_"), episode)
 		outputPath := filepath.Join(seriesPath, filename)
 
+		payload := hooks.Payload{
+			ShowID:   selection.ShowID,
+			Title:    selection.Anime.Title,
+			Episode:  episode,
+			FilePath: outputPath,
+		}
+
 		if err := scraper.DownloadEpisodeWithProgress(selection.ShowID, episode, outputPath); err != nil {
 			fmt.Printf("Error downloading episode %s: %v\n", episode, err)
 			result.Failed++
+			payload.Error = err.Error()
+			runHook(hooks.Error, payload)
 			continue
 		}
 		result.Successful++
+		result.Downloaded = append(result.Downloaded, episode)
+
+		record := config.DownloadRecord{
+			ShowID:       selection.ShowID,
+			Title:        selection.Anime.Title,
+			Episode:      episode,
+			Path:         outputPath,
+			DownloadedAt: time.Now(),
+		}
+		if info, err := os.Stat(outputPath); err == nil {
+			record.Size = info.Size()
+		}
+		if err := config.RecordDownload(record); err != nil {
+			fmt.Printf("Warning: failed to record download: %v\n", err)
+		}
+
+		runHook(hooks.DownloadComplete, payload)
 	}
 
 	return result, nil
 }
 
+func runHook(event hooks.Event, payload hooks.Payload) {
+	if err := hooks.Run(event, payload); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
 func PrintDownloadSummary(result *DownloadResult) {
 	if result.Total > 1 {
 		fmt.Printf("\nDownload summary: %d/%d episodes downloaded successfully",
@@ -10,10 +10,10 @@ import (
 )
 
 type DownloadInfo struct {
-	Path     string
-	Name     string
-	Size     int64
-	Modified time.Time
+	Path     string    `json:"path"`
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
 }
 
 type FileManager struct {
@@ -34,8 +34,20 @@ func (fm *FileManager) ListDownloads() ([]DownloadInfo, error) {
 		return nil, fmt.Errorf("listing downloads: %w", err)
 	}
 
+	if records, err := config.GetDownloads(); err == nil {
+		for _, record := range records {
+			files = append(files, record.Path)
+		}
+	}
+
 	var downloads []DownloadInfo
+	seen := make(map[string]bool)
 	for _, file := range files {
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+
 		info, err := os.Stat(file)
 		if err != nil {
 			continue
@@ -52,14 +64,15 @@ func (fm *FileManager) ListDownloads() ([]DownloadInfo, error) {
 }
 
 func (fm *FileManager) CleanDownloads() (int, error) {
-	files, err := filepath.Glob(filepath.Join(fm.Config.DownloadDir, "*.mp4"))
+	downloads, err := fm.ListDownloads()
 	if err != nil {
-		return 0, fmt.Errorf("listing downloads: %w", err)
+		return 0, err
 	}
 
 	removed := 0
-	for _, file := range files {
-		if err := os.Remove(file); err == nil {
+	for _, download := range downloads {
+		if err := os.Remove(download.Path); err == nil {
+			config.RemoveDownload(download.Path)
 			removed++
 		}
 	}
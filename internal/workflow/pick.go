@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/releases"
+	"github.com/keircn/karu/internal/scraper"
+	"github.com/keircn/karu/pkg/errors"
+)
+
+// PickAnime selects a result without prompting. The selector is a 1-based
+// index into the results, an exact (case-insensitive) title or a show ID.
+func PickAnime(animes []scraper.Anime, selector string) (*scraper.Anime, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return nil, errors.New(errors.ValidationError, "empty selector")
+	}
+
+	for i := range animes {
+		if strings.EqualFold(animes[i].Title, selector) || config.ShowIDFromURL(animes[i].URL) == selector {
+			return &animes[i], nil
+		}
+	}
+
+	if index, err := strconv.Atoi(selector); err == nil {
+		if index < 1 || index > len(animes) {
+			return nil, errors.New(errors.ValidationError, "result "+selector+" is out of range (1-"+strconv.Itoa(len(animes))+")")
+		}
+		return &animes[index-1], nil
+	}
+
+	return nil, errors.New(errors.ValidationError, "no result matches '"+selector+"'")
+}
+
+// PickEpisode selects an episode without prompting. The selector is an
+// episode number as listed by the provider, or one of first, latest and
+// next (the episode after the last one watched).
+func PickEpisode(showID string, episodes []string, selector string) (string, error) {
+	if len(episodes) == 0 {
+		return "", errors.New(errors.ScrapingError, "no episodes available")
+	}
+	episodes = releases.SortEpisodes(episodes)
+
+	selector = strings.TrimSpace(selector)
+	switch strings.ToLower(selector) {
+	case "first":
+		return episodes[0], nil
+	case "latest", "last":
+		return episodes[len(episodes)-1], nil
+	case "next":
+		if history, err := config.LoadHistory(); err == nil {
+			if episode, ok := history.GetResumeEpisode(showID, episodes); ok {
+				return episode, nil
+			}
+			if entry, exists := history.Find(showID); exists && entry.LastEpisode != "" {
+				return "", errors.New(errors.ValidationError, "no unwatched episode left")
+			}
+		}
+		return episodes[0], nil
+	}
+
+	for _, episode := range episodes {
+		if episode == selector {
+			return episode, nil
+		}
+	}
+	return "", errors.New(errors.ValidationError, "episode '"+selector+"' not found")
+}
+
+// PickAnimeSelection is the non-interactive counterpart of GetAnimeSelection.
+func PickAnimeSelection(query, selector string) (*AnimeSelection, error) {
+	animes, err := scraper.Search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	choice, err := PickAnime(animes, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	showID := config.ShowIDFromURL(choice.URL)
+	episodes, err := scraper.GetEpisodes(showID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, _ := config.LoadHistory()
+	if history != nil {
+		history.AddEntry(showID, query, choice.Title, choice.URL, len(episodes))
+	}
+
+	return &AnimeSelection{
+		Anime:    choice,
+		ShowID:   showID,
+		Episodes: releases.SortEpisodes(episodes),
+	}, nil
+}
@@ -0,0 +1,59 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/releases"
+	"github.com/keircn/karu/internal/scraper"
+)
+
+func QueueDownloads(selection *AnimeSelection, episodes []string) error {
+	return config.QueueDownloads(selection.ShowID, selection.Anime.Title, episodes)
+}
+
+// Failed downloads stay queued for the next run.
+func RunDownloadQueue() (*DownloadResult, error) {
+	queue, err := config.GetDownloadQueue()
+	if err != nil {
+		return nil, fmt.Errorf("loading download queue: %w", err)
+	}
+
+	var shows []*AnimeSelection
+	byShow := make(map[string]*AnimeSelection)
+	for _, queued := range queue {
+		selection, ok := byShow[queued.ShowID]
+		if !ok {
+			selection = &AnimeSelection{
+				Anime:  &scraper.Anime{Title: queued.Title, URL: scraper.ShowURL(queued.ShowID)},
+				ShowID: queued.ShowID,
+			}
+			byShow[queued.ShowID] = selection
+			shows = append(shows, selection)
+		}
+		selection.Episodes = append(selection.Episodes, queued.Episode)
+	}
+
+	total := &DownloadResult{}
+	for _, selection := range shows {
+		episodes := releases.SortEpisodes(selection.Episodes)
+		fmt.Printf("\nDownloading %d queued episodes of %s\n", len(episodes), selection.Anime.Title)
+
+		result, err := DownloadEpisodes(selection, DownloadOptions{Episodes: episodes})
+		if err != nil {
+			return total, err
+		}
+		for _, episode := range result.Downloaded {
+			if err := config.RemoveQueuedDownload(selection.ShowID, episode); err != nil {
+				fmt.Printf("Warning: failed to remove episode %s from the queue: %v\n", episode, err)
+			}
+		}
+
+		total.Total += result.Total
+		total.Successful += result.Successful
+		total.Failed += result.Failed
+		total.Episodes = append(total.Episodes, result.Episodes...)
+		total.Downloaded = append(total.Downloaded, result.Downloaded...)
+	}
+	return total, nil
+}
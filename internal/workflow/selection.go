@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/keircn/karu/internal/config"
+	"github.com/keircn/karu/internal/releases"
 	"github.com/keircn/karu/internal/scraper"
 	"github.com/keircn/karu/internal/ui"
 )
@@ -17,25 +18,38 @@ type AnimeSelection struct {
 
 func GetAnimeSelection(query string) (*AnimeSelection, error) {
 	if query == "" {
-		var err error
-		query, err = ui.PromptForSearch()
+		choice, err := ui.PromptForSearch()
 		if err != nil {
 			return nil, fmt.Errorf("getting search query: %w", err)
 		}
-		if query == "" {
+		if choice.Query == "" && choice.Anime == nil {
 			return nil, fmt.Errorf("no search query provided")
 		}
+		if choice.Anime != nil {
+			return loadSelection(choice.Query, choice.Anime)
+		}
+		query = choice.Query
 	}
 
 	fmt.Printf("Searching for: %s...\n", query)
-	animes, err := scraper.Search(query)
+	results, err := scraper.SearchWithSuggestions(query)
 	if err != nil {
 		return nil, fmt.Errorf("searching for anime: %w", err)
 	}
 
+	animes := results.Animes
 	if len(animes) == 0 {
+		if len(results.Suggestions) > 0 {
+			return nil, fmt.Errorf("no anime found, did you mean %s?", strings.Join(results.Suggestions, ", "))
+		}
 		return nil, fmt.Errorf("no anime found")
 	}
+	if results.Query != query {
+		fmt.Printf("No results for %s, showing results for %s instead.\n", query, results.Query)
+		if len(results.Suggestions) > 0 {
+			fmt.Printf("Did you mean %s?\n", strings.Join(results.Suggestions, ", "))
+		}
+	}
 
 	choice, err := ui.SelectAnime(animes)
 	if err != nil {
@@ -46,6 +60,10 @@ func GetAnimeSelection(query string) (*AnimeSelection, error) {
 		return nil, fmt.Errorf("no anime selected")
 	}
 
+	return loadSelection(query, choice)
+}
+
+func loadSelection(query string, choice *scraper.Anime) (*AnimeSelection, error) {
 	showID := choice.URL[strings.LastIndex(choice.URL, "/")+1:]
 	fmt.Printf("Loading episodes for %s...\n", choice.Title)
 	episodes, err := scraper.GetEpisodes(showID)
@@ -59,13 +77,13 @@ func GetAnimeSelection(query string) (*AnimeSelection, error) {
 
 	history, _ := config.LoadHistory()
 	if history != nil {
-		history.AddEntry(query, choice.Title, choice.URL, len(episodes))
+		history.AddEntry(showID, query, choice.Title, choice.URL, len(episodes))
 	}
 
 	return &AnimeSelection{
 		Anime:    choice,
 		ShowID:   showID,
-		Episodes: episodes,
+		Episodes: releases.SortEpisodes(episodes),
 	}, nil
 }
 
@@ -88,7 +106,10 @@ func GetAnimeSelectionFromHistory() (*AnimeSelection, error) {
 		return GetAnimeSelection("")
 	}
 
-	showID := entry.URL[strings.LastIndex(entry.URL, "/")+1:]
+	showID := entry.ShowID
+	if showID == "" {
+		showID = config.ShowIDFromURL(entry.URL)
+	}
 	fmt.Printf("Loading episodes for %s...\n", entry.Title)
 	episodes, err := scraper.GetEpisodes(showID)
 	if err != nil {
@@ -101,12 +122,12 @@ func GetAnimeSelectionFromHistory() (*AnimeSelection, error) {
 
 	history, _ := config.LoadHistory()
 	if history != nil {
-		history.UpdateProgress(entry.Title, entry.LastWatched)
+		history.Touch(showID)
 	}
 
 	return &AnimeSelection{
 		Anime:    &scraper.Anime{Title: entry.Title, URL: entry.URL},
 		ShowID:   showID,
-		Episodes: episodes,
+		Episodes: releases.SortEpisodes(episodes),
 	}, nil
 }
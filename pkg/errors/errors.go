@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 )
 
@@ -53,3 +54,35 @@ func Wrapf(err error, errorType ErrorType, format string, args ...interface{}) *
 		Cause:   err,
 	}
 }
+
+// TypeOf returns the ErrorType of the first KaruError in err's chain, or an
+// empty type when there is none.
+func TypeOf(err error) ErrorType {
+	var karuErr *KaruError
+	if stderrors.As(err, &karuErr) {
+		return karuErr.Type
+	}
+	return ""
+}
+
+// ExitCode maps an error to the process exit status used for its ErrorType.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	switch TypeOf(err) {
+	case ValidationError:
+		return 2
+	case ConfigError:
+		return 3
+	case NetworkError:
+		return 4
+	case ScrapingError:
+		return 5
+	case PlayerError:
+		return 6
+	default:
+		return 1
+	}
+}
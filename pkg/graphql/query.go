@@ -93,6 +93,10 @@ const ShowsQuery = `query($search: SearchInput, $limit: Int, $page: Int, $transl
 		edges {
 			_id
 			name
+			englishName
+			nativeName
+			altNames
+			thumbnail
 			availableEpisodes
 			__typename
 		}
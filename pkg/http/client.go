@@ -20,6 +20,7 @@ type Client struct {
 	httpClient  *http.Client
 	userAgents  []string
 	referer     string
+	headers     map[string]string
 	mu          sync.Mutex
 	rand        *rand.Rand
 	rateLimiter *rateLimiter
@@ -78,6 +79,15 @@ func WithReferer(referer string) ClientOption {
 	}
 }
 
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(map[string]string)
+		}
+		c.headers[key] = value
+	}
+}
+
 func NewClient(opts ...ClientOption) *Client {
 	client := &Client{
 		httpClient: &http.Client{
@@ -132,6 +142,9 @@ func (c *Client) PostJSON(ctx context.Context, url string, payload interface{})
 	if c.referer != "" {
 		req.Header.Set("Referer", c.referer)
 	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -160,6 +173,9 @@ func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
 	if c.referer != "" {
 		req.Header.Set("Referer", c.referer)
 	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
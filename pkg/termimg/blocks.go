@@ -0,0 +1,37 @@
+package termimg
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Half blocks need colors, so nothing is drawn when they are off.
+func renderBlocks(img image.Image, cols, rows int) []string {
+	profile := lipgloss.ColorProfile()
+	if profile == termenv.Ascii {
+		return nil
+	}
+
+	scaled := Scale(img, cols, rows*2)
+	hex := func(x, y int) string {
+		i := scaled.PixOffset(x, y)
+		return fmt.Sprintf("#%02x%02x%02x", scaled.Pix[i], scaled.Pix[i+1], scaled.Pix[i+2])
+	}
+
+	lines := make([]string, rows)
+	for row := range lines {
+		var line strings.Builder
+		for x := range cols {
+			top := profile.Color(hex(x, row*2)).Sequence(false)
+			bottom := profile.Color(hex(x, row*2+1)).Sequence(true)
+			line.WriteString("\x1b[" + top + ";" + bottom + "m▀")
+		}
+		line.WriteString("\x1b[0m")
+		lines[row] = line.String()
+	}
+	return lines
+}
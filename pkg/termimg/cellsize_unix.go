@@ -0,0 +1,19 @@
+//go:build !windows
+
+package termimg
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func CellSize() (int, int) {
+	for _, f := range []*os.File{os.Stderr, os.Stdout, os.Stdin} {
+		ws, err := unix.IoctlGetWinsize(int(f.Fd()), unix.TIOCGWINSZ)
+		if err == nil && ws.Col > 0 && ws.Row > 0 && ws.Xpixel > 0 && ws.Ypixel > 0 {
+			return int(ws.Xpixel / ws.Col), int(ws.Ypixel / ws.Row)
+		}
+	}
+	return defaultCellWidth, defaultCellHeight
+}
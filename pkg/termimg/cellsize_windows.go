@@ -0,0 +1,7 @@
+//go:build windows
+
+package termimg
+
+func CellSize() (int, int) {
+	return defaultCellWidth, defaultCellHeight
+}
@@ -0,0 +1,24 @@
+package termimg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+func renderITerm2(img image.Image, cols, rows, cellW, cellH int) []string {
+	strips := rowStrips(img, cols, rows, cellW, cellH)
+	lines := make([]string, rows)
+	for row, strip := range strips {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, strip); err != nil {
+			return nil
+		}
+		seq := fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=1;preserveAspectRatio=0;doNotMoveCursor=1:%s\a",
+			buf.Len(), cols, base64.StdEncoding.EncodeToString(buf.Bytes()))
+		lines[row] = overlay(cols, seq)
+	}
+	return lines
+}
@@ -0,0 +1,68 @@
+package termimg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/png"
+	"strings"
+)
+
+const (
+	kittyChunkSize   = 4096
+	kittyPlaceholder = "\U0010EEEE"
+)
+
+// kittyDiacritics encode row and column numbers in placeholder cells. These
+// are the first entries of the table in the kitty protocol specification,
+// which limits images to as many rows.
+var kittyDiacritics = []rune{
+	0x0305, 0x030D, 0x030E, 0x0310, 0x0312, 0x033D, 0x033E, 0x033F,
+	0x0346, 0x034A, 0x034B, 0x034C, 0x0350, 0x0351, 0x0352, 0x0357,
+	0x035B, 0x0363, 0x0364, 0x0365, 0x0366, 0x0367, 0x0368, 0x0369,
+	0x036A, 0x036B, 0x036C, 0x036D, 0x036E, 0x036F,
+}
+
+// Unicode placeholders are ordinary text, so repainting a line repaints its
+// part of the image.
+func renderKitty(img image.Image, cols, rows, cellW, cellH int) []string {
+	scaled := Scale(img, cols*cellW, rows*cellH)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaled); err != nil {
+		return nil
+	}
+
+	// The image ID is carried in the placeholders' foreground color, so it
+	// has to fit in 24 bits.
+	h := fnv.New32a()
+	h.Write(scaled.Pix)
+	id := h.Sum32()&0xffffff | 1
+
+	var transmit strings.Builder
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	for i := 0; i < len(data); i += kittyChunkSize {
+		chunk := data[i:min(i+kittyChunkSize, len(data))]
+		more := 0
+		if i+kittyChunkSize < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&transmit, "\x1b_Ga=T,U=1,f=100,q=2,i=%d,c=%d,r=%d,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&transmit, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+
+	color := fmt.Sprintf("\x1b[38;2;%d;%d;%dm", id>>16&0xff, id>>8&0xff, id&0xff)
+	// Cells after the first in a row inherit its row and the next column.
+	rest := strings.Repeat(kittyPlaceholder, cols-1)
+	lines := make([]string, rows)
+	for row := range lines {
+		lines[row] = color + kittyPlaceholder + string(kittyDiacritics[row]) + string(kittyDiacritics[0]) + rest + "\x1b[39m"
+	}
+	lines[0] = transmit.String() + lines[0]
+	return lines
+}
@@ -0,0 +1,94 @@
+package termimg
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"strings"
+)
+
+func renderSixel(img image.Image, cols, rows, cellW, cellH int) []string {
+	strips := rowStrips(img, cols, rows, cellW, cellH)
+	lines := make([]string, rows)
+	for row, strip := range strips {
+		lines[row] = overlay(cols, encodeSixel(strip))
+	}
+	return lines
+}
+
+func encodeSixel(img image.Image) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	paletted := image.NewPaletted(image.Rect(0, 0, w, h), palette.WebSafe)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, b.Min)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+
+	used := make([]bool, len(palette.WebSafe))
+	for _, i := range paletted.Pix {
+		used[i] = true
+	}
+	for i, c := range palette.WebSafe {
+		if used[i] {
+			r, g, bl, _ := c.RGBA()
+			fmt.Fprintf(&out, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+		}
+	}
+
+	// Sixel data comes in bands six pixels high. Each color used in a band
+	// is drawn in turn, returning to the start of the band in between.
+	bits := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		inBand := make([]bool, len(palette.WebSafe))
+		for y := top; y < min(top+6, h); y++ {
+			for _, i := range paletted.Pix[y*paletted.Stride : y*paletted.Stride+w] {
+				inBand[i] = true
+			}
+		}
+
+		first := true
+		for i, present := range inBand {
+			if !present {
+				continue
+			}
+			for x := range bits {
+				bits[x] = 0
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if int(paletted.Pix[(top+dy)*paletted.Stride+x]) == i {
+						bits[x] |= 1 << dy
+					}
+				}
+			}
+			if !first {
+				out.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&out, "#%d", i)
+			writeSixelRuns(&out, bits)
+		}
+		out.WriteByte('-')
+	}
+
+	out.WriteString("\x1b\\")
+	return out.String()
+}
+
+func writeSixelRuns(out *strings.Builder, bits []byte) {
+	for x := 0; x < len(bits); {
+		n := 1
+		for x+n < len(bits) && bits[x+n] == bits[x] {
+			n++
+		}
+		ch := byte('?' + bits[x])
+		if n > 3 {
+			fmt.Fprintf(out, "!%d%c", n, ch)
+		} else {
+			for range n {
+				out.WriteByte(ch)
+			}
+		}
+		x += n
+	}
+}
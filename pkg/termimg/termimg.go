@@ -0,0 +1,130 @@
+package termimg
+
+import (
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/keircn/karu/pkg/errors"
+)
+
+const (
+	defaultCellWidth  = 10
+	defaultCellHeight = 20
+)
+
+type Protocol int
+
+const (
+	HalfBlocks Protocol = iota
+	Kitty
+	ITerm2
+	Sixel
+)
+
+var protocolNames = map[Protocol]string{
+	HalfBlocks: "blocks",
+	Kitty:      "kitty",
+	ITerm2:     "iterm2",
+	Sixel:      "sixel",
+}
+
+func (p Protocol) String() string {
+	return protocolNames[p]
+}
+
+func ParseProtocol(name string) (Protocol, bool) {
+	for p, n := range protocolNames {
+		if n == name {
+			return p, true
+		}
+	}
+	return HalfBlocks, false
+}
+
+func Detect() Protocol {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	// Multiplexers swallow graphics escapes unless configured to pass them
+	// through, so only plain text is safe.
+	if os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen") {
+		return HalfBlocks
+	}
+
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", term == "xterm-ghostty", program == "ghostty":
+		return Kitty
+	case program == "iTerm.app", program == "WezTerm", os.Getenv("LC_TERMINAL") == "iTerm2":
+		return ITerm2
+	case strings.Contains(term, "sixel"), strings.HasPrefix(term, "foot"), term == "mlterm", term == "contour", program == "mintty":
+		return Sixel
+	}
+	return HalfBlocks
+}
+
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ValidationError, "failed to decode image")
+	}
+	return img, nil
+}
+
+func Render(img image.Image, p Protocol, cols, rows int) string {
+	cellW, cellH := CellSize()
+	if p == Kitty {
+		rows = min(rows, len(kittyDiacritics))
+	}
+	cols, rows = fit(img.Bounds(), cellW, cellH, cols, rows)
+	if cols == 0 || rows == 0 {
+		return ""
+	}
+
+	var lines []string
+	switch p {
+	case Kitty:
+		lines = renderKitty(img, cols, rows, cellW, cellH)
+	case ITerm2:
+		lines = renderITerm2(img, cols, rows, cellW, cellH)
+	case Sixel:
+		lines = renderSixel(img, cols, rows, cellW, cellH)
+	default:
+		lines = renderBlocks(img, cols, rows)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func fit(b image.Rectangle, cellW, cellH, cols, rows int) (int, int) {
+	if b.Dx() == 0 || b.Dy() == 0 || cols <= 0 || rows <= 0 {
+		return 0, 0
+	}
+	imgCols := float64(b.Dx()) / float64(cellW)
+	imgRows := float64(b.Dy()) / float64(cellH)
+	scale := min(float64(cols)/imgCols, float64(rows)/imgRows)
+	return clamp(int(imgCols*scale+0.5), cols), clamp(int(imgRows*scale+0.5), rows)
+}
+
+func clamp(n, limit int) int {
+	return max(1, min(n, limit))
+}
+
+func rowStrips(img image.Image, cols, rows, cellW, cellH int) []*image.RGBA {
+	scaled := Scale(img, cols*cellW, rows*cellH)
+	strips := make([]*image.RGBA, rows)
+	for row := range strips {
+		strips[row] = scaled.SubImage(image.Rect(0, row*cellH, cols*cellW, (row+1)*cellH)).(*image.RGBA)
+	}
+	return strips
+}
+
+// overlay draws seq over the cols cells just written, leaving the cursor
+// where it was, so each row of an image is redrawn with its line.
+func overlay(cols int, seq string) string {
+	return strings.Repeat(" ", cols) + "\x1b7\x1b[" + strconv.Itoa(cols) + "D" + seq + "\x1b8"
+}
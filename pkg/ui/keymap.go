@@ -0,0 +1,417 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type AppKeys struct {
+	Back    key.Binding
+	Forward key.Binding
+	Quit    key.Binding
+}
+
+type ListKeys struct {
+	Up     key.Binding
+	Down   key.Binding
+	Filter key.Binding
+	Select key.Binding
+	Quit   key.Binding
+}
+
+type SearchKeys struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+}
+
+type AnimeKeys struct {
+	Watchlist key.Binding
+}
+
+type EpisodeKeys struct {
+	Watchlist       key.Binding
+	Resume          key.Binding
+	Toggle          key.Binding
+	ExtendUp        key.Binding
+	ExtendDown      key.Binding
+	SelectUnwatched key.Binding
+	SelectToEnd     key.Binding
+	ClearSelection  key.Binding
+}
+
+type MenuKeys struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Quit   key.Binding
+}
+
+type EditorKeys struct {
+	Up         key.Binding
+	Down       key.Binding
+	PrevChoice key.Binding
+	NextChoice key.Binding
+	Reset      key.Binding
+	Save       key.Binding
+	Cancel     key.Binding
+}
+
+type PlayerKeys struct {
+	Next key.Binding
+	Prev key.Binding
+	Skip key.Binding
+	Help key.Binding
+	Quit key.Binding
+}
+
+type PartyKeys struct {
+	Chat        key.Binding
+	Pause       key.Binding
+	SeekBack    key.Binding
+	SeekForward key.Binding
+	Next        key.Binding
+	Prev        key.Binding
+	Skip        key.Binding
+	Quit        key.Binding
+}
+
+type CastKeys struct {
+	Pause       key.Binding
+	SeekBack    key.Binding
+	SeekForward key.Binding
+	Stop        key.Binding
+	Next        key.Binding
+	Prev        key.Binding
+	Quit        key.Binding
+}
+
+type KeyMap struct {
+	App      AppKeys
+	Search   SearchKeys
+	List     ListKeys
+	Anime    AnimeKeys
+	Episodes EpisodeKeys
+	Menu     MenuKeys
+	Editor   EditorKeys
+	Player   PlayerKeys
+	Party    PartyKeys
+	Cast     CastKeys
+}
+
+type Action struct {
+	Name    string
+	Binding *key.Binding
+}
+
+type Screen struct {
+	Name        string
+	Description string
+	Extends     string
+	Actions     []Action
+}
+
+// ForceQuitKey always exits and cannot be rebound.
+const ForceQuitKey = "ctrl+c"
+
+var Keys = DefaultKeyMap()
+
+var keyNames = map[string]string{
+	" ":     "space",
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+}
+
+func binding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKeys(keys), desc))
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		App: AppKeys{
+			Back:    binding("back", "esc", "alt+left"),
+			Forward: binding("forward", "alt+right"),
+			Quit:    binding("quit", "q"),
+		},
+		Search: SearchKeys{
+			Up:     binding("previous suggestion", "up", "ctrl+p"),
+			Down:   binding("next suggestion", "down", "ctrl+n"),
+			Select: binding("search or open suggestion", "enter"),
+		},
+		List: ListKeys{
+			Up:     binding("up", "up", "k"),
+			Down:   binding("down", "down", "j"),
+			Filter: binding("filter", "/"),
+			Select: binding("select", "enter"),
+			Quit:   binding("quit", "q", "esc"),
+		},
+		Anime: AnimeKeys{
+			Watchlist: binding("add to watchlist", "a"),
+		},
+		Episodes: EpisodeKeys{
+			Watchlist:       binding("add to watchlist", "a"),
+			Resume:          binding("resume", "r"),
+			Toggle:          binding("toggle selection", " "),
+			ExtendUp:        binding("extend selection up", "shift+up", "K"),
+			ExtendDown:      binding("extend selection down", "shift+down", "J"),
+			SelectUnwatched: binding("select unwatched", "U"),
+			SelectToEnd:     binding("select to end", "E"),
+			ClearSelection:  binding("clear selection", "X"),
+		},
+		Menu: MenuKeys{
+			Up:     binding("move up", "up", "k"),
+			Down:   binding("move down", "down", "j"),
+			Select: binding("select", "enter"),
+			Quit:   binding("quit", "q", "esc"),
+		},
+		Editor: EditorKeys{
+			Up:         binding("move up", "up", "shift+tab"),
+			Down:       binding("move down", "down", "tab", "enter"),
+			PrevChoice: binding("previous choice", "left", "h"),
+			NextChoice: binding("next choice", "right", "l", " "),
+			Reset:      binding("reset to default", "ctrl+r"),
+			Save:       binding("save", "ctrl+s"),
+			Cancel:     binding("cancel", "esc"),
+		},
+		Player: PlayerKeys{
+			Next: binding("next episode", "n"),
+			Prev: binding("previous episode", "p"),
+			Skip: binding("skip intro/outro", "s"),
+			Help: binding("toggle help", "h", "?"),
+			Quit: binding("quit", "q"),
+		},
+		Party: PartyKeys{
+			Chat:        binding("chat", "enter", "c"),
+			Pause:       binding("pause/resume", " "),
+			SeekBack:    binding("seek back", "left"),
+			SeekForward: binding("seek forward", "right"),
+			Next:        binding("next episode", "n"),
+			Prev:        binding("previous episode", "p"),
+			Skip:        binding("skip", "s"),
+			Quit:        binding("leave", "q"),
+		},
+		Cast: CastKeys{
+			Pause:       binding("pause/resume", " "),
+			SeekBack:    binding("seek back 30s", "left"),
+			SeekForward: binding("seek forward 30s", "right"),
+			Stop:        binding("stop", "s"),
+			Next:        binding("next episode", "n"),
+			Prev:        binding("previous episode", "p"),
+			Quit:        binding("quit", "q"),
+		},
+	}
+}
+
+func (k *KeyMap) Screens() []Screen {
+	return []Screen{
+		{Name: "app", Description: "Screen navigation in search", Actions: []Action{
+			{"back", &k.App.Back}, {"forward", &k.App.Forward}, {"quit", &k.App.Quit},
+		}},
+		{Name: "search", Description: "Search box and its suggestions", Actions: []Action{
+			{"up", &k.Search.Up}, {"down", &k.Search.Down}, {"select", &k.Search.Select},
+		}},
+		{Name: "list", Description: "Every selection list", Actions: []Action{
+			{"up", &k.List.Up}, {"down", &k.List.Down}, {"filter", &k.List.Filter},
+			{"select", &k.List.Select}, {"quit", &k.List.Quit},
+		}},
+		{Name: "anime", Description: "Search results", Extends: "list", Actions: []Action{
+			{"watchlist", &k.Anime.Watchlist},
+		}},
+		{Name: "episodes", Description: "Episode list", Extends: "list", Actions: []Action{
+			{"watchlist", &k.Episodes.Watchlist}, {"resume", &k.Episodes.Resume},
+			{"toggle", &k.Episodes.Toggle}, {"extend_up", &k.Episodes.ExtendUp},
+			{"extend_down", &k.Episodes.ExtendDown}, {"select_unwatched", &k.Episodes.SelectUnwatched},
+			{"select_to_end", &k.Episodes.SelectToEnd}, {"clear_selection", &k.Episodes.ClearSelection},
+		}},
+		{Name: "menu", Description: "History menu", Actions: []Action{
+			{"up", &k.Menu.Up}, {"down", &k.Menu.Down}, {"select", &k.Menu.Select}, {"quit", &k.Menu.Quit},
+		}},
+		{Name: "editor", Description: "Config editor", Actions: []Action{
+			{"up", &k.Editor.Up}, {"down", &k.Editor.Down},
+			{"prev_choice", &k.Editor.PrevChoice}, {"next_choice", &k.Editor.NextChoice},
+			{"reset", &k.Editor.Reset}, {"save", &k.Editor.Save}, {"cancel", &k.Editor.Cancel},
+		}},
+		{Name: "player", Description: "Video player", Actions: []Action{
+			{"next", &k.Player.Next}, {"prev", &k.Player.Prev}, {"skip", &k.Player.Skip},
+			{"help", &k.Player.Help}, {"quit", &k.Player.Quit},
+		}},
+		{Name: "party", Description: "Watch party", Actions: []Action{
+			{"chat", &k.Party.Chat}, {"pause", &k.Party.Pause},
+			{"seek_back", &k.Party.SeekBack}, {"seek_forward", &k.Party.SeekForward},
+			{"next", &k.Party.Next}, {"prev", &k.Party.Prev}, {"skip", &k.Party.Skip}, {"quit", &k.Party.Quit},
+		}},
+		{Name: "cast", Description: "Cast session", Actions: []Action{
+			{"pause", &k.Cast.Pause}, {"seek_back", &k.Cast.SeekBack}, {"seek_forward", &k.Cast.SeekForward},
+			{"stop", &k.Cast.Stop}, {"next", &k.Cast.Next}, {"prev", &k.Cast.Prev}, {"quit", &k.Cast.Quit},
+		}},
+	}
+}
+
+func (k *KeyMap) Override(overrides map[string]map[string][]string) []error {
+	screens := k.Screens()
+
+	var problems []error
+	for _, screenName := range sortedKeys(overrides) {
+		index := slices.IndexFunc(screens, func(s Screen) bool { return s.Name == screenName })
+		if index < 0 {
+			problems = append(problems, fmt.Errorf("unknown key screen '%s'", screenName))
+			continue
+		}
+
+		actions := overrides[screenName]
+		for _, actionName := range sortedKeys(actions) {
+			action := slices.IndexFunc(screens[index].Actions, func(a Action) bool { return a.Name == actionName })
+			if action < 0 {
+				problems = append(problems, fmt.Errorf("%s: unknown action '%s'", screenName, actionName))
+				continue
+			}
+
+			keys := normalizeKeys(actions[actionName])
+			if len(keys) == 0 {
+				problems = append(problems, fmt.Errorf("%s.%s: no keys given", screenName, actionName))
+				continue
+			}
+			if slices.Contains(keys, ForceQuitKey) {
+				problems = append(problems, fmt.Errorf("%s.%s: %s is reserved for force quit", screenName, actionName, ForceQuitKey))
+				continue
+			}
+
+			target := screens[index].Actions[action].Binding
+			target.SetKeys(keys...)
+			target.SetHelp(helpKeys(keys), target.Help().Desc)
+		}
+	}
+	return problems
+}
+
+// Conflicts reports keys bound to more than one action on the same screen,
+// including actions inherited from the screen it extends.
+func (k *KeyMap) Conflicts() []error {
+	screens := k.Screens()
+
+	var problems []error
+	for _, screen := range screens {
+		owners := make(map[string]string)
+		if screen.Extends != "" {
+			parent := screens[slices.IndexFunc(screens, func(s Screen) bool { return s.Name == screen.Extends })]
+			for _, action := range parent.Actions {
+				for _, pressed := range action.Binding.Keys() {
+					owners[pressed] = screen.Extends + "." + action.Name
+				}
+			}
+		}
+
+		for _, action := range screen.Actions {
+			name := screen.Name + "." + action.Name
+			for _, pressed := range action.Binding.Keys() {
+				if owner, exists := owners[pressed]; exists {
+					problems = append(problems, fmt.Errorf("key %s is bound to both %s and %s", displayKey(pressed), owner, name))
+					continue
+				}
+				owners[pressed] = name
+			}
+		}
+	}
+	return problems
+}
+
+// LoadKeyMap replaces Keys with the defaults plus overrides. The current
+// keymap is kept when any override is invalid or conflicts.
+func LoadKeyMap(overrides map[string]map[string][]string) []error {
+	keymap := DefaultKeyMap()
+	problems := keymap.Override(overrides)
+	problems = append(problems, keymap.Conflicts()...)
+	if len(problems) > 0 {
+		return problems
+	}
+
+	Keys = keymap
+	return nil
+}
+
+func HelpLine(bindings ...key.Binding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if b.Enabled() {
+			parts = append(parts, b.Help().Key+": "+b.Help().Desc)
+		}
+	}
+	return strings.Join(parts, " • ")
+}
+
+func HelpTable(bindings ...key.Binding) string {
+	width := 0
+	for _, b := range bindings {
+		width = max(width, len([]rune(b.Help().Key)))
+	}
+
+	lines := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		lines = append(lines, fmt.Sprintf("  %-*s - %s", width, b.Help().Key, b.Help().Desc))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func normalizeKeys(keys []string) []string {
+	var normalized []string
+	for _, k := range keys {
+		k = normalizeKey(k)
+		if k != "" && !slices.Contains(normalized, k) {
+			normalized = append(normalized, k)
+		}
+	}
+	return normalized
+}
+
+// Named keys and ctrl combinations are case-insensitive, but a single
+// character keeps its case so that "K" and "k" can be bound separately.
+func normalizeKey(k string) string {
+	k = strings.TrimSpace(k)
+	modifiers, name := "", k
+	if i := strings.LastIndex(k, "+"); i > 0 && i < len(k)-1 {
+		modifiers, name = strings.ToLower(k[:i+1]), k[i+1:]
+	}
+	if utf8.RuneCountInString(name) > 1 || strings.Contains(modifiers, "ctrl+") {
+		name = strings.ToLower(name)
+	}
+
+	switch name {
+	case "space":
+		name = " "
+	case "return":
+		name = "enter"
+	case "escape":
+		name = "esc"
+	}
+	return modifiers + name
+}
+
+func helpKeys(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = displayKey(k)
+	}
+	return strings.Join(names, "/")
+}
+
+func displayKey(k string) string {
+	if name, exists := keyNames[k]; exists {
+		return name
+	}
+	return k
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -2,19 +2,17 @@ package ui
 
 import (
 	"os"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 var (
-	AppStyle = lipgloss.NewStyle().Padding(1, 2)
-
-	TitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFDF5")).
-			Background(lipgloss.Color("#25A065")).
-			Padding(0, 1)
+	AppStyle   = lipgloss.NewStyle().Padding(1, 2)
+	TitleStyle lipgloss.Style
 
 	PaginationStyle = list.DefaultStyles().PaginationStyle.PaddingTop(1)
 	HelpStyle       = list.DefaultStyles().HelpStyle.PaddingLeft(4).PaddingBottom(1)
@@ -51,13 +49,17 @@ type ListModel struct {
 }
 
 func NewListModel(items []list.Item, title string) ListModel {
-	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	return ListModel{list: NewList(items, title)}
+}
+
+func NewList(items []list.Item, title string) list.Model {
+	l := list.New(items, NewDelegate(), 0, 0)
 	l.Title = title
 	l.Styles.Title = TitleStyle
 	l.Styles.PaginationStyle = PaginationStyle
 	l.Styles.HelpStyle = HelpStyle
-
-	return ListModel{list: l}
+	applyListKeys(&l)
+	return l
 }
 
 func (m ListModel) Init() tea.Cmd {
@@ -75,12 +77,12 @@ func (m ListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			break
 		}
 
-		switch keypress := msg.String(); keypress {
-		case "ctrl+c", "q":
+		switch {
+		case msg.String() == ForceQuitKey || quitsList(m.list, msg):
 			m.quitting = true
 			return m, tea.Quit
 
-		case "enter":
+		case key.Matches(msg, Keys.List.Select):
 			if item, ok := m.list.SelectedItem().(SelectableItem); ok {
 				m.choice = item.GetValue()
 			}
@@ -100,6 +102,52 @@ func (m ListModel) View() string {
 	return AppStyle.Render(m.list.View())
 }
 
+func applyListKeys(l *list.Model) {
+	l.KeyMap.CursorUp = Keys.List.Up
+	l.KeyMap.CursorDown = Keys.List.Down
+	l.KeyMap.Filter = Keys.List.Filter
+	l.KeyMap.Quit = Keys.List.Quit
+}
+
+// quitsList reports whether msg quits the list rather than clearing an
+// applied filter, which shares esc by default.
+func quitsList(l list.Model, msg tea.KeyMsg) bool {
+	if l.FilterState() == list.FilterApplied && key.Matches(msg, l.KeyMap.ClearFilter) {
+		return false
+	}
+	return key.Matches(msg, Keys.List.Quit)
+}
+
+func (m *ListModel) AddHelpKeys(bindings ...key.Binding) {
+	keys := func() []key.Binding { return bindings }
+	m.list.AdditionalShortHelpKeys = keys
+	m.list.AdditionalFullHelpKeys = keys
+}
+
+func (m ListModel) SelectedValue() interface{} {
+	if item, ok := m.list.SelectedItem().(SelectableItem); ok {
+		return item.GetValue()
+	}
+	return nil
+}
+
+func (m *ListModel) StatusMessage(message string) tea.Cmd {
+	m.list.StatusMessageLifetime = 3 * time.Second
+	return m.list.NewStatusMessage(message)
+}
+
+func (m *ListModel) List() *list.Model {
+	return &m.list
+}
+
+func (m ListModel) Filtering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+func (m *ListModel) SetChoice(value interface{}) {
+	m.choice = value
+}
+
 func (m ListModel) GetChoice() interface{} {
 	return m.choice
 }
@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/keircn/karu/pkg/errors"
+	"github.com/muesli/termenv"
+)
+
+const (
+	DefaultThemeName = "default"
+	NoColorThemeName = "no-color"
+)
+
+type Theme struct {
+	Name        string
+	Description string
+
+	Primary   lipgloss.TerminalColor
+	Secondary lipgloss.TerminalColor
+	Muted     lipgloss.TerminalColor
+	Subtle    lipgloss.TerminalColor
+	Error     lipgloss.TerminalColor
+	Warning   lipgloss.TerminalColor
+
+	TitleForeground lipgloss.TerminalColor
+	TitleBackground lipgloss.TerminalColor
+}
+
+var themes = []Theme{
+	{
+		Name:            DefaultThemeName,
+		Description:     "Pink and teal on a dark terminal",
+		Primary:         lipgloss.Color("205"),
+		Secondary:       lipgloss.Color("86"),
+		Muted:           lipgloss.Color("244"),
+		Subtle:          lipgloss.Color("240"),
+		Error:           lipgloss.Color("196"),
+		Warning:         lipgloss.Color("214"),
+		TitleForeground: lipgloss.Color("#FFFDF5"),
+		TitleBackground: lipgloss.Color("#25A065"),
+	},
+	{
+		Name:            "light",
+		Description:     "Darker colors for light terminal backgrounds",
+		Primary:         lipgloss.Color("161"),
+		Secondary:       lipgloss.Color("30"),
+		Muted:           lipgloss.Color("242"),
+		Subtle:          lipgloss.Color("246"),
+		Error:           lipgloss.Color("160"),
+		Warning:         lipgloss.Color("130"),
+		TitleForeground: lipgloss.Color("#FFFFFF"),
+		TitleBackground: lipgloss.Color("#1F7A4D"),
+	},
+	{
+		Name:            "high-contrast",
+		Description:     "Bright basic colors for maximum legibility",
+		Primary:         lipgloss.Color("11"),
+		Secondary:       lipgloss.Color("14"),
+		Muted:           lipgloss.Color("15"),
+		Subtle:          lipgloss.Color("7"),
+		Error:           lipgloss.Color("9"),
+		Warning:         lipgloss.Color("11"),
+		TitleForeground: lipgloss.Color("0"),
+		TitleBackground: lipgloss.Color("11"),
+	},
+	{
+		Name:            NoColorThemeName,
+		Description:     "No colors, only bold and italic text",
+		Primary:         lipgloss.NoColor{},
+		Secondary:       lipgloss.NoColor{},
+		Muted:           lipgloss.NoColor{},
+		Subtle:          lipgloss.NoColor{},
+		Error:           lipgloss.NoColor{},
+		Warning:         lipgloss.NoColor{},
+		TitleForeground: lipgloss.NoColor{},
+		TitleBackground: lipgloss.NoColor{},
+	},
+}
+
+var (
+	current = themes[0]
+
+	AccentStyle  lipgloss.Style
+	StatusStyle  lipgloss.Style
+	MutedStyle   lipgloss.Style
+	SubtleStyle  lipgloss.Style
+	ErrorStyle   lipgloss.Style
+	WarningStyle lipgloss.Style
+)
+
+func init() {
+	ApplyTheme(current)
+}
+
+func Themes() []Theme {
+	return themes
+}
+
+func ThemeNames() []string {
+	names := make([]string, len(themes))
+	for i, theme := range themes {
+		names[i] = theme.Name
+	}
+	return names
+}
+
+func LookupTheme(name string) (Theme, bool) {
+	for _, theme := range themes {
+		if theme.Name == name {
+			return theme, true
+		}
+	}
+	return Theme{}, false
+}
+
+func CurrentTheme() Theme {
+	return current
+}
+
+// UseTheme applies the named theme, or the no-color theme when NO_COLOR is set.
+func UseTheme(name string) error {
+	if os.Getenv("NO_COLOR") != "" {
+		name = NoColorThemeName
+	}
+	if name == "" {
+		name = DefaultThemeName
+	}
+
+	theme, exists := LookupTheme(name)
+	if !exists {
+		return errors.New(errors.ValidationError, "unknown theme '"+name+"'")
+	}
+	ApplyTheme(theme)
+	return nil
+}
+
+func ApplyTheme(theme Theme) {
+	current = theme
+
+	TitleStyle = lipgloss.NewStyle().
+		Foreground(theme.TitleForeground).
+		Background(theme.TitleBackground).
+		Padding(0, 1)
+
+	AccentStyle = lipgloss.NewStyle().Foreground(theme.Primary)
+	StatusStyle = lipgloss.NewStyle().Foreground(theme.Secondary)
+	MutedStyle = lipgloss.NewStyle().Foreground(theme.Muted)
+	SubtleStyle = lipgloss.NewStyle().Foreground(theme.Subtle)
+	ErrorStyle = lipgloss.NewStyle().Foreground(theme.Error)
+	WarningStyle = lipgloss.NewStyle().Foreground(theme.Warning)
+
+	if theme.Name == NoColorThemeName {
+		TitleStyle = TitleStyle.Bold(true)
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+func NewDelegate() list.DefaultDelegate {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(current.Primary).
+		BorderForeground(current.Primary)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(current.Primary).
+		BorderForeground(current.Primary)
+	return delegate
+}
+
+func (t Theme) Preview() string {
+	title := lipgloss.NewStyle().Foreground(t.TitleForeground).Background(t.TitleBackground).Padding(0, 1)
+	primary := lipgloss.NewStyle().Foreground(t.Primary)
+	selected := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(t.Primary).
+		Foreground(t.Primary).
+		Padding(0, 0, 0, 1)
+	if t.Name == NoColorThemeName {
+		title = title.Bold(true)
+		selected = selected.Bold(true)
+	}
+
+	lines := []string{
+		title.Render("Select an anime"),
+		"",
+		selected.Render("Frieren: Beyond Journey's End"),
+		"  " + lipgloss.NewStyle().Foreground(t.Subtle).Render("Sousou no Frieren"),
+		primary.Bold(true).Render("Karu Video Player"),
+		lipgloss.NewStyle().Foreground(t.Secondary).Render("Playing episode 3"),
+		lipgloss.NewStyle().Foreground(t.Warning).Render("• 2 unsaved changes") + "  " + lipgloss.NewStyle().Foreground(t.Error).Render("✗ player 'vlc' was not found"),
+		lipgloss.NewStyle().Foreground(t.Muted).Render("j/k: move • enter: select • q: quit"),
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString("  " + line + "\n")
+	}
+	return b.String()
+}